@@ -3,16 +3,25 @@
 
 package config
 
-import "github.com/zeromicro/go-zero/rest"
+import (
+	"aifriend/internal/pkg/account"
+	"aifriend/internal/pkg/client"
+	"aifriend/internal/pkg/knowledge"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/pkg/loginguard"
+	"aifriend/internal/pkg/mailer"
+	"aifriend/internal/pkg/memory"
+	"aifriend/internal/pkg/ranking"
+	"aifriend/internal/pkg/search"
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/pkg/vector"
+
+	"github.com/zeromicro/go-zero/rest"
+)
 
 type Config struct {
 	rest.RestConf
-	Auth struct {
-		AccessSecret       string
-		AccessExpire       int64  // 访问令牌过期时间(秒)
-		RefreshSecret      string
-		RefreshExpire      int64  // 刷新令牌过期时间(秒)
-	}
+	Auth  session.Config
 	MySQL struct {
 		DataSource string
 	}
@@ -21,9 +30,27 @@ type Config struct {
 		AllowCredentials bool
 	}
 	Upload struct {
-		AvatarDir       string
-		MaxAvatarSize   int64
-		CharacterDir    string
+		AvatarDir        string
+		MaxAvatarSize    int64
+		CharacterDir     string
 		MaxCharacterSize int64
+		KnowledgeDir     string `json:",optional"`
+		MaxDocumentSize  int64  `json:",optional"`
+	}
+	LLM  llm.Config
+	Chat struct {
+		ContextTokens    int    `json:",default=8192"`                                                 // 模型上下文窗口(token)
+		ReplyTokens      int    `json:",default=1024"`                                                 // 为回复预留的token
+		TruncateStrategy string `json:",default=drop_oldest,options=latest_turns|drop_oldest|summary"` // 超出上下文时的截断策略
+		LatestTurns      int    `json:",default=20"`                                                   // latest_turns 策略保留的轮数
 	}
+	Memory     memory.Config
+	Vector     vector.Config
+	Knowledge  knowledge.Config
+	Search     search.Config
+	Ranking    ranking.Config
+	Mail       mailer.Config
+	Account    account.Config
+	LoginGuard loginguard.Config
+	Client     client.Config
 }
@@ -0,0 +1,25 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package auth
+
+import (
+	"net/http"
+
+	"aifriend/internal/logic/auth"
+	"aifriend/internal/svc"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 退出所有设备
+func LogoutAllHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := auth.NewLogoutAllLogic(r.Context(), svcCtx)
+		resp, err := l.LogoutAll()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
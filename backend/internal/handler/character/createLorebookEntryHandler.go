@@ -0,0 +1,32 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"net/http"
+
+	"aifriend/internal/logic/character"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 创建世界设定条目
+func CreateLorebookEntryHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.LorebookEntryReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := character.NewCreateLorebookEntryLogic(r.Context(), svcCtx)
+		resp, err := l.CreateLorebookEntry(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
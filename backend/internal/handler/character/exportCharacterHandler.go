@@ -0,0 +1,44 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"mime"
+	"net/http"
+	"strconv"
+
+	"aifriend/internal/logic/character"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 导出角色卡
+func ExportCharacterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.ExportCharacterReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		l := character.NewExportCharacterLogic(r.Context(), svcCtx)
+		file, resp, err := l.ExportCharacter(&req)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+		if resp != nil {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+			return
+		}
+
+		w.Header().Set("Content-Type", file.ContentType)
+		w.Header().Set("Content-Length", strconv.Itoa(len(file.Data)))
+		w.Header().Set("Content-Disposition", mime.FormatMediaType("attachment", map[string]string{"filename": file.Filename}))
+		w.WriteHeader(http.StatusOK)
+		w.Write(file.Data)
+	}
+}
@@ -8,14 +8,21 @@ import (
 
 	"aifriend/internal/logic/character"
 	"aifriend/internal/svc"
+	"aifriend/internal/types"
 	"github.com/zeromicro/go-zero/rest/httpx"
 )
 
 // 获取角色列表
 func GetCharacterListHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.CursorReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
 		l := character.NewGetCharacterListLogic(r.Context(), svcCtx)
-		resp, err := l.GetCharacterList()
+		resp, err := l.GetCharacterList(&req)
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
@@ -0,0 +1,51 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"net/http"
+
+	"aifriend/internal/logic/character"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 导入角色卡 (multipart form, 支持 PNG 和 JSON)
+func ImportCharacterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		maxSize := svcCtx.Config.Upload.MaxCharacterSize
+		if maxSize <= 0 {
+			maxSize = 5 * 1024 * 1024
+		}
+
+		const maxOverhead = int64(512 * 1024)
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize+maxOverhead)
+		if err := r.ParseMultipartForm(maxSize + maxOverhead); err != nil {
+			httpx.OkJsonCtx(r.Context(), w, &types.DataResp{
+				Code:    400,
+				Message: "请求数据过大",
+			})
+			return
+		}
+
+		_, fileHeader, err := r.FormFile("file")
+		if err != nil {
+			httpx.OkJsonCtx(r.Context(), w, &types.DataResp{
+				Code:    400,
+				Message: "请选择要导入的角色卡",
+			})
+			return
+		}
+
+		l := character.NewImportCharacterLogic(r.Context(), svcCtx)
+		resp, err := l.ImportCharacter(fileHeader)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -45,14 +45,34 @@ func UpdateCharacterHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
 			return
 		}
 
-		name := r.FormValue("name")
-		profile := r.FormValue("profile")
+		form := types.CharacterForm{
+			Name:             r.FormValue("name"),
+			Profile:          r.FormValue("profile"),
+			Scenario:         r.FormValue("scenario"),
+			Greeting:         r.FormValue("greeting"),
+			Personality:      r.FormValue("personality"),
+			ExampleDialogues: r.FormValue("example_dialogues"),
+			SpeakingStyle:    r.FormValue("speaking_style"),
+			CreatorNotes:     r.FormValue("creator_notes"),
+			PromptTemplate:   r.FormValue("prompt_template"),
+			Generation:       r.FormValue("generation"),
+			Visibility:       r.FormValue("visibility"),
+			Category:         r.FormValue("category"),
+		}
+		// 备选开场白为可重复字段, 未传时为 nil
+		if greetings, ok := r.MultipartForm.Value["alternate_greetings"]; ok {
+			form.AlternateGreetings = greetings
+		}
+		// 标签为可重复字段, 未传时为 nil
+		if tags, ok := r.MultipartForm.Value["tags"]; ok {
+			form.Tags = tags
+		}
 
 		_, photoFileHeader, _ := r.FormFile("photo")
 		_, bgFileHeader, _ := r.FormFile("background_image")
 
 		l := character.NewUpdateCharacterLogic(r.Context(), svcCtx)
-		resp, err := l.UpdateCharacter(characterId, name, profile, photoFileHeader, bgFileHeader)
+		resp, err := l.UpdateCharacter(characterId, &form, photoFileHeader, bgFileHeader)
 		if err != nil {
 			httpx.ErrorCtx(r.Context(), w, err)
 		} else {
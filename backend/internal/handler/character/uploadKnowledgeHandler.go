@@ -0,0 +1,65 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"net/http"
+	"strconv"
+
+	"aifriend/internal/logic/character"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/rest/httpx"
+	"github.com/zeromicro/go-zero/rest/pathvar"
+)
+
+// 上传知识库文档 (multipart form)
+func UploadKnowledgeHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		// 获取路径参数
+		vars := pathvar.Vars(r)
+		idStr := vars["id"]
+		characterId, err := strconv.ParseInt(idStr, 10, 64)
+		if err != nil {
+			httpx.OkJsonCtx(r.Context(), w, &types.DataResp{
+				Code:    400,
+				Message: "无效的角色ID",
+			})
+			return
+		}
+
+		maxSize := svcCtx.Config.Upload.MaxDocumentSize
+		if maxSize <= 0 {
+			maxSize = 10 * 1024 * 1024
+		}
+
+		const maxOverhead = int64(512 * 1024)
+		r.Body = http.MaxBytesReader(w, r.Body, maxSize+maxOverhead)
+		if err := r.ParseMultipartForm(maxSize + maxOverhead); err != nil {
+			httpx.OkJsonCtx(r.Context(), w, &types.DataResp{
+				Code:    400,
+				Message: "请求数据过大",
+			})
+			return
+		}
+
+		_, fileHeader, err := r.FormFile("file")
+		if err != nil {
+			httpx.OkJsonCtx(r.Context(), w, &types.DataResp{
+				Code:    400,
+				Message: "请选择要上传的文档",
+			})
+			return
+		}
+
+		l := character.NewUploadKnowledgeLogic(r.Context(), svcCtx)
+		resp, err := l.UploadKnowledge(characterId, fileHeader)
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"net/http"
+
+	"aifriend/internal/logic/conversation"
+	"aifriend/internal/middleware"
+	"aifriend/internal/pkg/jwt"
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/pkg/ws"
+	"aifriend/internal/svc"
+
+	"github.com/gorilla/websocket"
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// WebSocket 实时对话
+func ChatSocketHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	cors := middleware.NewCorsMiddleware(svcCtx.Config.Cors.AllowOrigins, svcCtx.Config.Cors.AllowCredentials)
+	upgrader := websocket.Upgrader{
+		Subprotocols: []string{ws.Subprotocol},
+		CheckOrigin: func(r *http.Request) bool {
+			// 移动端等非浏览器客户端不会携带 Origin
+			origin := r.Header.Get("Origin")
+			return origin == "" || cors.IsOriginAllowed(origin)
+		},
+	}
+
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := jwt.ParseToken(ws.TokenFromRequest(r), svcCtx.Config.Auth.AccessSecret)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		if err := session.Check(svcCtx.DB, claims.UserId, claims.TokenVersion, claims.ID); err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			logc.Errorw(r.Context(), "ChatSocketHandler", logc.Field("error", err))
+			return
+		}
+
+		l := conversation.NewChatSocketLogic(r.Context(), svcCtx, claims, ws.NewConn(conn))
+		l.ChatSocket()
+	}
+}
@@ -0,0 +1,65 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"aifriend/internal/logic/conversation"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"github.com/zeromicro/go-zero/core/threading"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 重新生成回复并流式返回
+func RegenerateMessageStreamHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		var req types.RegenerateMessageReq
+		if err := httpx.Parse(r, &req); err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+			return
+		}
+
+		// 缓冲少量事件, 避免模型输出被客户端的慢速读取阻塞
+		client := make(chan *types.ChatStreamEvent, 16)
+
+		l := conversation.NewRegenerateMessageStreamLogic(r.Context(), svcCtx)
+		threading.GoSafeCtx(r.Context(), func() {
+			defer close(client)
+			if err := l.RegenerateMessageStream(&req, client); err != nil {
+				logc.Errorw(r.Context(), "RegenerateMessageStreamHandler", logc.Field("error", err))
+			}
+		})
+
+		for {
+			select {
+			case event, ok := <-client:
+				if !ok {
+					return
+				}
+
+				output, err := json.Marshal(event)
+				if err != nil {
+					logc.Errorw(r.Context(), "RegenerateMessageStreamHandler", logc.Field("error", err))
+					continue
+				}
+
+				if _, err := fmt.Fprintf(w, "event: %s\ndata: %s\n\n", event.Event, output); err != nil {
+					logc.Errorw(r.Context(), "RegenerateMessageStreamHandler", logc.Field("error", err))
+					return
+				}
+				if flusher, ok := w.(http.Flusher); ok {
+					flusher.Flush()
+				}
+			case <-r.Context().Done():
+				return
+			}
+		}
+	}
+}
@@ -5,9 +5,12 @@ package handler
 
 import (
 	"net/http"
+	"time"
 
 	auth "aifriend/internal/handler/auth"
 	character "aifriend/internal/handler/character"
+	conversation "aifriend/internal/handler/conversation"
+	memory "aifriend/internal/handler/memory"
 	user "aifriend/internal/handler/user"
 	"aifriend/internal/svc"
 
@@ -15,25 +18,98 @@ import (
 )
 
 func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo},
+			[]rest.Route{
+				{
+					// 用户登录
+					Method:  http.MethodPost,
+					Path:    "/auth/login",
+					Handler: auth.LoginHandler(serverCtx),
+				},
+				{
+					// 退出登录
+					Method:  http.MethodPost,
+					Path:    "/auth/logout",
+					Handler: auth.LogoutHandler(serverCtx),
+				},
+				{
+					// 刷新Token
+					Method:  http.MethodPost,
+					Path:    "/auth/refresh",
+					Handler: auth.RefreshTokenHandler(serverCtx),
+				},
+				{
+					// 用户注册
+					Method:  http.MethodPost,
+					Path:    "/auth/register",
+					Handler: auth.RegisterHandler(serverCtx),
+				},
+				{
+					// 验证邮箱
+					Method:  http.MethodPost,
+					Path:    "/auth/email/verify",
+					Handler: auth.VerifyEmailHandler(serverCtx),
+				},
+				{
+					// 忘记密码
+					Method:  http.MethodPost,
+					Path:    "/auth/password/forgot",
+					Handler: auth.ForgotPasswordHandler(serverCtx),
+				},
+				{
+					// 重置密码
+					Method:  http.MethodPost,
+					Path:    "/auth/password/reset",
+					Handler: auth.ResetPasswordHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithPrefix("/api/v1"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo, serverCtx.SessionCheck},
+			[]rest.Route{
+				{
+					// 退出所有设备
+					Method:  http.MethodPost,
+					Path:    "/auth/logout/all",
+					Handler: auth.LogoutAllHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/api/v1"),
+	)
+
 	server.AddRoutes(
 		[]rest.Route{
 			{
-				// 用户登录
-				Method:  http.MethodPost,
-				Path:    "/auth/login",
-				Handler: auth.LoginHandler(serverCtx),
+				// 浏览公开角色
+				Method:  http.MethodGet,
+				Path:    "/characters",
+				Handler: character.GetPublicCharacterListHandler(serverCtx),
 			},
 			{
-				// 刷新Token
-				Method:  http.MethodPost,
-				Path:    "/auth/refresh",
-				Handler: auth.RefreshTokenHandler(serverCtx),
+				// 搜索公开角色
+				Method:  http.MethodGet,
+				Path:    "/characters/search",
+				Handler: character.SearchCharactersHandler(serverCtx),
 			},
 			{
-				// 用户注册
-				Method:  http.MethodPost,
-				Path:    "/auth/register",
-				Handler: auth.RegisterHandler(serverCtx),
+				// 热门角色
+				Method:  http.MethodGet,
+				Path:    "/characters/trending",
+				Handler: character.GetTrendingCharactersHandler(serverCtx),
+			},
+			{
+				// 查看公开角色
+				Method:  http.MethodGet,
+				Path:    "/characters/:id",
+				Handler: character.GetPublicCharacterHandler(serverCtx),
 			},
 			{
 				// 获取头像文件
@@ -52,38 +128,254 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 	)
 
 	server.AddRoutes(
-		[]rest.Route{
-			{
-				// 创建角色
-				Method:  http.MethodPost,
-				Path:    "/character",
-				Handler: character.CreateCharacterHandler(serverCtx),
-			},
-			{
-				// 获取单个角色
-				Method:  http.MethodGet,
-				Path:    "/character/:id",
-				Handler: character.GetCharacterHandler(serverCtx),
-			},
-			{
-				// 更新角色
-				Method:  http.MethodPut,
-				Path:    "/character/:id",
-				Handler: character.UpdateCharacterHandler(serverCtx),
-			},
-			{
-				// 删除角色
-				Method:  http.MethodDelete,
-				Path:    "/character/:id",
-				Handler: character.RemoveCharacterHandler(serverCtx),
-			},
-			{
-				// 获取角色列表
-				Method:  http.MethodGet,
-				Path:    "/character/list",
-				Handler: character.GetCharacterListHandler(serverCtx),
-			},
-		},
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo, serverCtx.SessionCheck},
+			[]rest.Route{
+				{
+					// 创建角色
+					Method:  http.MethodPost,
+					Path:    "/character",
+					Handler: character.CreateCharacterHandler(serverCtx),
+				},
+				{
+					// 登录用户浏览公开角色
+					Method:  http.MethodGet,
+					Path:    "/character/discover",
+					Handler: character.DiscoverCharactersHandler(serverCtx),
+				},
+				{
+					// 获取我收藏的角色
+					Method:  http.MethodGet,
+					Path:    "/character/favorites",
+					Handler: character.GetFavoriteListHandler(serverCtx),
+				},
+				{
+					// 获取为我推荐的角色
+					Method:  http.MethodGet,
+					Path:    "/character/recommendations",
+					Handler: character.GetRecommendedCharactersHandler(serverCtx),
+				},
+				{
+					// 导入角色卡
+					Method:  http.MethodPost,
+					Path:    "/character/import",
+					Handler: character.ImportCharacterHandler(serverCtx),
+				},
+				{
+					// 导出角色卡
+					Method:  http.MethodGet,
+					Path:    "/character/:id/export",
+					Handler: character.ExportCharacterHandler(serverCtx),
+				},
+				{
+					// 复制角色到我的角色库
+					Method:  http.MethodPost,
+					Path:    "/character/:id/fork",
+					Handler: character.ForkCharacterHandler(serverCtx),
+				},
+				{
+					// 获取单个角色
+					Method:  http.MethodGet,
+					Path:    "/character/:id",
+					Handler: character.GetCharacterHandler(serverCtx),
+				},
+				{
+					// 更新角色
+					Method:  http.MethodPut,
+					Path:    "/character/:id",
+					Handler: character.UpdateCharacterHandler(serverCtx),
+				},
+				{
+					// 删除角色
+					Method:  http.MethodDelete,
+					Path:    "/character/:id",
+					Handler: character.RemoveCharacterHandler(serverCtx),
+				},
+				{
+					// 预览角色系统提示词
+					Method:  http.MethodPost,
+					Path:    "/character/:id/prompt/preview",
+					Handler: character.PreviewPromptHandler(serverCtx),
+				},
+				{
+					// 点赞角色
+					Method:  http.MethodPut,
+					Path:    "/character/:id/like",
+					Handler: character.LikeCharacterHandler(serverCtx),
+				},
+				{
+					// 取消点赞
+					Method:  http.MethodDelete,
+					Path:    "/character/:id/like",
+					Handler: character.UnlikeCharacterHandler(serverCtx),
+				},
+				{
+					// 收藏角色
+					Method:  http.MethodPut,
+					Path:    "/character/:id/favorite",
+					Handler: character.FavoriteCharacterHandler(serverCtx),
+				},
+				{
+					// 取消收藏
+					Method:  http.MethodDelete,
+					Path:    "/character/:id/favorite",
+					Handler: character.UnfavoriteCharacterHandler(serverCtx),
+				},
+				{
+					// 评分角色
+					Method:  http.MethodPut,
+					Path:    "/character/:id/rating",
+					Handler: character.RateCharacterHandler(serverCtx),
+				},
+				{
+					// 删除评分
+					Method:  http.MethodDelete,
+					Path:    "/character/:id/rating",
+					Handler: character.RemoveRatingHandler(serverCtx),
+				},
+				{
+					// 获取角色评价列表
+					Method:  http.MethodGet,
+					Path:    "/character/:id/ratings",
+					Handler: character.GetCharacterRatingsHandler(serverCtx),
+				},
+				{
+					// 获取我与角色的互动状态
+					Method:  http.MethodGet,
+					Path:    "/character/:id/interaction",
+					Handler: character.GetCharacterInteractionHandler(serverCtx),
+				},
+				{
+					// 获取角色历史版本
+					Method:  http.MethodGet,
+					Path:    "/character/:id/versions",
+					Handler: character.GetCharacterVersionsHandler(serverCtx),
+				},
+				{
+					// 比较角色的两个版本
+					Method:  http.MethodGet,
+					Path:    "/character/:id/versions/diff",
+					Handler: character.DiffCharacterVersionsHandler(serverCtx),
+				},
+				{
+					// 回滚角色到历史版本
+					Method:  http.MethodPost,
+					Path:    "/character/:id/versions/:version/rollback",
+					Handler: character.RollbackCharacterHandler(serverCtx),
+				},
+				{
+					// 获取知识库文档列表
+					Method:  http.MethodGet,
+					Path:    "/character/:id/knowledge",
+					Handler: character.GetKnowledgeListHandler(serverCtx),
+				},
+				{
+					// 删除知识库文档
+					Method:  http.MethodDelete,
+					Path:    "/character/:id/knowledge/:documentId",
+					Handler: character.RemoveKnowledgeHandler(serverCtx),
+				},
+				{
+					// 获取世界设定条目列表
+					Method:  http.MethodGet,
+					Path:    "/character/:id/lorebook",
+					Handler: character.GetLorebookHandler(serverCtx),
+				},
+				{
+					// 创建世界设定条目
+					Method:  http.MethodPost,
+					Path:    "/character/:id/lorebook",
+					Handler: character.CreateLorebookEntryHandler(serverCtx),
+				},
+				{
+					// 更新世界设定条目
+					Method:  http.MethodPut,
+					Path:    "/character/:id/lorebook/:entryId",
+					Handler: character.UpdateLorebookEntryHandler(serverCtx),
+				},
+				{
+					// 删除世界设定条目
+					Method:  http.MethodDelete,
+					Path:    "/character/:id/lorebook/:entryId",
+					Handler: character.RemoveLorebookEntryHandler(serverCtx),
+				},
+				{
+					// 获取角色列表
+					Method:  http.MethodGet,
+					Path:    "/character/list",
+					Handler: character.GetCharacterListHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/api/v1"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo, serverCtx.SessionCheck},
+			[]rest.Route{
+				{
+					// 上传知识库文档 (multipart form)
+					Method:  http.MethodPost,
+					Path:    "/character/:id/knowledge",
+					Handler: character.UploadKnowledgeHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/api/v1"),
+		rest.WithTimeout(300000*time.Millisecond),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo, serverCtx.SessionCheck},
+			[]rest.Route{
+				{
+					// 创建会话
+					Method:  http.MethodPost,
+					Path:    "/character/:id/conversations",
+					Handler: conversation.CreateConversationHandler(serverCtx),
+				},
+				{
+					// 获取会话列表
+					Method:  http.MethodGet,
+					Path:    "/character/:id/conversations",
+					Handler: conversation.GetConversationListHandler(serverCtx),
+				},
+				{
+					// 获取单个会话
+					Method:  http.MethodGet,
+					Path:    "/character/:id/conversations/:conversationId",
+					Handler: conversation.GetConversationHandler(serverCtx),
+				},
+				{
+					// 删除会话
+					Method:  http.MethodDelete,
+					Path:    "/character/:id/conversations/:conversationId",
+					Handler: conversation.RemoveConversationHandler(serverCtx),
+				},
+				{
+					// 获取会话消息
+					Method:  http.MethodGet,
+					Path:    "/character/:id/conversations/:conversationId/messages",
+					Handler: conversation.GetMessageListHandler(serverCtx),
+				},
+				{
+					// 获取消息的备选分支
+					Method:  http.MethodGet,
+					Path:    "/character/:id/conversations/:conversationId/messages/:messageId/alternatives",
+					Handler: conversation.GetMessageAlternativesHandler(serverCtx),
+				},
+				{
+					// 切换会话分支
+					Method:  http.MethodPut,
+					Path:    "/character/:id/conversations/:conversationId/branch",
+					Handler: conversation.SelectBranchHandler(serverCtx),
+				},
+			}...,
+		),
 		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
 		rest.WithPrefix("/api/v1"),
 	)
@@ -91,30 +383,127 @@ func RegisterHandlers(server *rest.Server, serverCtx *svc.ServiceContext) {
 	server.AddRoutes(
 		[]rest.Route{
 			{
-				// 上传头像
-				Method:  http.MethodPost,
-				Path:    "/user/avatar",
-				Handler: user.UploadAvatarHandler(serverCtx),
-			},
-			{
-				// 获取当前用户信息
+				// WebSocket 实时对话
 				Method:  http.MethodGet,
-				Path:    "/user/info",
-				Handler: user.GetUserInfoHandler(serverCtx),
-			},
-			{
-				// 更新用户信息
-				Method:  http.MethodPut,
-				Path:    "/user/info",
-				Handler: user.UpdateUserInfoHandler(serverCtx),
-			},
-			{
-				// 修改密码
-				Method:  http.MethodPost,
-				Path:    "/user/password",
-				Handler: user.ChangePasswordHandler(serverCtx),
+				Path:    "/ws/chat",
+				Handler: conversation.ChatSocketHandler(serverCtx),
 			},
 		},
+		rest.WithPrefix("/api/v1"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo, serverCtx.SessionCheck},
+			[]rest.Route{
+				{
+					// 发送消息并流式返回回复
+					Method:  http.MethodPost,
+					Path:    "/conversation/:id/messages/stream",
+					Handler: conversation.SendMessageStreamHandler(serverCtx),
+				},
+				{
+					// 重新生成回复并流式返回
+					Method:  http.MethodPost,
+					Path:    "/conversation/:id/messages/:messageId/regenerate",
+					Handler: conversation.RegenerateMessageStreamHandler(serverCtx),
+				},
+				{
+					// 编辑消息并流式返回新的回复
+					Method:  http.MethodPost,
+					Path:    "/conversation/:id/messages/:messageId/edit",
+					Handler: conversation.EditMessageStreamHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/api/v1"),
+		rest.WithSSE(),
+		rest.WithTimeout(300000*time.Millisecond),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo, serverCtx.SessionCheck},
+			[]rest.Route{
+				{
+					// 获取角色记住的记忆
+					Method:  http.MethodGet,
+					Path:    "/character/:id/memories",
+					Handler: memory.GetMemoryListHandler(serverCtx),
+				},
+				{
+					// 编辑记忆
+					Method:  http.MethodPut,
+					Path:    "/character/:id/memories/:memoryId",
+					Handler: memory.UpdateMemoryHandler(serverCtx),
+				},
+				{
+					// 删除记忆
+					Method:  http.MethodDelete,
+					Path:    "/character/:id/memories/:memoryId",
+					Handler: memory.RemoveMemoryHandler(serverCtx),
+				},
+				{
+					// 置顶或取消置顶记忆
+					Method:  http.MethodPut,
+					Path:    "/character/:id/memories/:memoryId/pin",
+					Handler: memory.PinMemoryHandler(serverCtx),
+				},
+			}...,
+		),
+		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
+		rest.WithPrefix("/api/v1"),
+	)
+
+	server.AddRoutes(
+		rest.WithMiddlewares(
+			[]rest.Middleware{serverCtx.ClientInfo, serverCtx.SessionCheck},
+			[]rest.Route{
+				{
+					// 上传头像
+					Method:  http.MethodPost,
+					Path:    "/user/avatar",
+					Handler: user.UploadAvatarHandler(serverCtx),
+				},
+				{
+					// 获取登录设备
+					Method:  http.MethodGet,
+					Path:    "/user/sessions",
+					Handler: user.GetSessionListHandler(serverCtx),
+				},
+				{
+					// 下线登录设备
+					Method:  http.MethodDelete,
+					Path:    "/user/sessions/:id",
+					Handler: user.RemoveSessionHandler(serverCtx),
+				},
+				{
+					// 获取当前用户信息
+					Method:  http.MethodGet,
+					Path:    "/user/info",
+					Handler: user.GetUserInfoHandler(serverCtx),
+				},
+				{
+					// 更新用户信息
+					Method:  http.MethodPut,
+					Path:    "/user/info",
+					Handler: user.UpdateUserInfoHandler(serverCtx),
+				},
+				{
+					// 修改密码
+					Method:  http.MethodPost,
+					Path:    "/user/password",
+					Handler: user.ChangePasswordHandler(serverCtx),
+				},
+				{
+					// 发送邮箱验证邮件
+					Method:  http.MethodPost,
+					Path:    "/user/email/verification",
+					Handler: user.SendVerificationEmailHandler(serverCtx),
+				},
+			}...,
+		),
 		rest.WithJwt(serverCtx.Config.Auth.AccessSecret),
 		rest.WithPrefix("/api/v1"),
 	)
@@ -0,0 +1,25 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package user
+
+import (
+	"net/http"
+
+	"aifriend/internal/logic/user"
+	"aifriend/internal/svc"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 获取登录设备
+func GetSessionListHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := user.NewGetSessionListLogic(r.Context(), svcCtx)
+		resp, err := l.GetSessionList()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package user
+
+import (
+	"net/http"
+
+	"aifriend/internal/logic/user"
+	"aifriend/internal/svc"
+	"github.com/zeromicro/go-zero/rest/httpx"
+)
+
+// 发送邮箱验证邮件
+func SendVerificationEmailHandler(svcCtx *svc.ServiceContext) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		l := user.NewSendVerificationEmailLogic(r.Context(), svcCtx)
+		resp, err := l.SendVerificationEmail()
+		if err != nil {
+			httpx.ErrorCtx(r.Context(), w, err)
+		} else {
+			httpx.OkJsonCtx(r.Context(), w, resp)
+		}
+	}
+}
@@ -0,0 +1,27 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"aifriend/internal/pkg/account"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+)
+
+// sendEmailTimeout 后台发送一封邮件的超时时间
+const sendEmailTimeout = 30 * time.Second
+
+// sendInBackground 在后台发送邮件, 不阻塞请求, 响应时间也不会暴露邮箱是否已注册
+func sendInBackground(kind string, userId int64, send func(ctx context.Context) error) {
+	threading.GoSafe(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), sendEmailTimeout)
+		defer cancel()
+
+		if err := send(ctx); err != nil && !errors.Is(err, account.ErrTooFrequent) {
+			logx.Errorf("send %s email to user %d failed: %v", kind, userId, err)
+		}
+	})
+}
@@ -0,0 +1,64 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package auth
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// maxResetAccounts 同一邮箱最多向多少个账号发送重置邮件
+const maxResetAccounts = 5
+
+type ForgotPasswordLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 忘记密码
+func NewForgotPasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ForgotPasswordLogic {
+	return &ForgotPasswordLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ForgotPasswordLogic) ForgotPassword(req *types.ForgotPasswordReq) (resp *types.BaseResp, err error) {
+	email := strings.TrimSpace(req.Email)
+	if email == "" {
+		return &types.BaseResp{
+			Code:    400,
+			Message: "邮箱不能为空",
+		}, nil
+	}
+
+	// 只向已验证的邮箱发送, 邮箱可能被多个账号使用, 每个账号单独发送一封
+	var users []model.User
+	if err := l.svcCtx.DB.Where("email = ? AND email_verified_at IS NOT NULL", email).
+		Limit(maxResetAccounts).
+		Find(&users).Error; err != nil {
+		return nil, errors.New("查询用户失败")
+	}
+	for i := range users {
+		user := &users[i]
+		sendInBackground("password reset", user.Id, func(ctx context.Context) error {
+			return l.svcCtx.Emails.SendPasswordReset(ctx, user)
+		})
+	}
+
+	// 无论邮箱是否存在都返回相同的结果, 避免被用来探测已注册的邮箱
+	return &types.BaseResp{
+		Code:    0,
+		Message: "如果该邮箱已绑定并通过验证, 你将收到重置密码的邮件",
+	}, nil
+}
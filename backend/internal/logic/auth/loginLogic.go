@@ -6,14 +6,19 @@ package auth
 import (
 	"context"
 	"errors"
+	"fmt"
+	"math"
+	"time"
 
 	"aifriend/internal/model"
-	"aifriend/internal/pkg/jwt"
+	"aifriend/internal/pkg/client"
+	"aifriend/internal/pkg/session"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type LoginLogic struct {
@@ -31,43 +36,115 @@ func NewLoginLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LoginLogic
 	}
 }
 
-func (l *LoginLogic) Login(req *types.LoginReq) (resp *types.TokenResp, err error) {
+func (l *LoginLogic) Login(req *types.LoginReq) (resp *types.LoginResp, err error) {
+	info := client.FromContext(l.ctx)
+
+	// 连续失败次数过多时不再验证密码. 允许登录时本次尝试先计为一次失败, 成功后再清除,
+	// 并发的请求不会同时通过检查. 失败记录无法读取时不阻止登录
+	status, err := l.svcCtx.LoginGuard.Attempt(l.ctx, req.Username, info.Ip)
+	if err != nil {
+		l.Errorf("检查登录失败次数失败: %v", err)
+	}
+	if status.RetryAfter > 0 {
+		l.recordFailure(0, req.Username, model.LoginFailureThrottled)
+		return &types.LoginResp{
+			Code:            429,
+			Message:         fmt.Sprintf("登录失败次数过多, 请 %s后再试", waitText(status.RetryAfter)),
+			CaptchaRequired: status.CaptchaRequired,
+			RetryAfter:      retryAfterSeconds(status.RetryAfter),
+		}, nil
+	}
+
 	// 查找用户
 	var user model.User
 	if err := l.svcCtx.DB.Where("username = ?", req.Username).First(&user).Error; err != nil {
-		return nil, errors.New("用户名或密码错误")
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, errors.New("查询用户失败")
+		}
+		return l.fail(0, req.Username, model.LoginFailureUserNotFound, status.ReservedAt), nil
 	}
 
 	// 验证密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
-		return nil, errors.New("用户名或密码错误")
+		return l.fail(user.Id, req.Username, model.LoginFailureWrongPassword, status.ReservedAt), nil
+	}
+
+	if err := l.svcCtx.LoginGuard.Succeed(l.ctx, req.Username, info.Ip, status.ReservedAt); err != nil {
+		l.Errorf("清除登录失败次数失败: %v", err)
+	}
+
+	// 清理已过期的刷新令牌
+	if err := l.svcCtx.DB.Where("user_id = ? AND expires_at < ?", user.Id, time.Now()).
+		Delete(&model.RefreshToken{}).Error; err != nil {
+		l.Errorf("清理过期的刷新令牌失败: %v", err)
 	}
 
-	// 生成访问令牌
-	accessToken, err := jwt.GenerateToken(
-		user.Id,
-		user.Username,
-		l.svcCtx.Config.Auth.AccessSecret,
-		l.svcCtx.Config.Auth.AccessExpire,
-	)
+	var tokens *session.Tokens
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		tokens, err = session.Start(l.ctx, tx, l.svcCtx.Config.Auth, &user, req.DeviceName)
+		return err
+	})
 	if err != nil {
-		return nil, errors.New("生成令牌失败")
+		l.Errorf("创建会话失败: %v", err)
+		return nil, errors.New("创建会话失败")
 	}
+	return &types.LoginResp{
+		Code:         0,
+		Message:      "登录成功",
+		AccessToken:  tokens.AccessToken,
+		RefreshToken: tokens.RefreshToken,
+		ExpiresIn:    tokens.ExpiresIn,
+	}, nil
+}
 
-	// 生成刷新令牌
-	refreshToken, err := jwt.GenerateToken(
-		user.Id,
-		user.Username,
-		l.svcCtx.Config.Auth.RefreshSecret,
-		l.svcCtx.Config.Auth.RefreshExpire,
-	)
+// fail 确认本次尝试在 reservedAt 时的预留为一次失败并返回登录失败的响应. 用户不存在和密码错误返回相同的提示
+func (l *LoginLogic) fail(userId int64, username, reason string, reservedAt time.Time) *types.LoginResp {
+	status, err := l.svcCtx.LoginGuard.Fail(l.ctx, username, client.FromContext(l.ctx).Ip, reservedAt)
 	if err != nil {
-		return nil, errors.New("生成刷新令牌失败")
+		l.Errorf("记录登录失败次数失败: %v", err)
 	}
+	l.recordFailure(userId, username, reason)
 
-	return &types.TokenResp{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    l.svcCtx.Config.Auth.AccessExpire,
-	}, nil
+	resp := &types.LoginResp{
+		Code:            400,
+		Message:         "用户名或密码错误",
+		CaptchaRequired: status.CaptchaRequired,
+		RetryAfter:      retryAfterSeconds(status.RetryAfter),
+	}
+	if status.Locked {
+		resp.Message = fmt.Sprintf("用户名或密码错误, 失败次数过多, 请 %s后再试", waitText(status.RetryAfter))
+	}
+	return resp
+}
+
+// recordFailure 保存登录失败的审计记录
+func (l *LoginLogic) recordFailure(userId int64, username, reason string) {
+	if name := []rune(username); len(name) > maxUsernameLength {
+		username = string(name[:maxUsernameLength])
+	}
+	info := client.FromContext(l.ctx)
+	if err := l.svcCtx.DB.Create(&model.LoginFailure{
+		UserId:    userId,
+		Username:  username,
+		Ip:        info.Ip,
+		UserAgent: info.UserAgent,
+		Reason:    reason,
+	}).Error; err != nil {
+		l.Errorf("保存登录失败记录失败: %v", err)
+	}
+}
+
+// maxUsernameLength 审计记录中保存的用户名最大字数, 与 users.username 的长度一致
+const maxUsernameLength = 50
+
+func retryAfterSeconds(d time.Duration) int64 {
+	return int64(math.Ceil(d.Seconds()))
+}
+
+// waitText 把等待时间显示为秒或分钟
+func waitText(d time.Duration) string {
+	if seconds := retryAfterSeconds(d); seconds < 60 {
+		return fmt.Sprintf("%d 秒", seconds)
+	}
+	return fmt.Sprintf("%d 分钟", int64(math.Ceil(d.Minutes())))
 }
@@ -0,0 +1,47 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type LogoutAllLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 退出所有设备
+func NewLogoutAllLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LogoutAllLogic {
+	return &LogoutAllLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *LogoutAllLogic) LogoutAll() (resp *types.BaseResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	// 吊销全部会话, 已签发的访问令牌随之失效
+	if err := session.Revoke(l.svcCtx.DB, "user_id = ?", userId); err != nil {
+		return nil, errors.New("退出登录失败")
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "已退出所有设备",
+	}, nil
+}
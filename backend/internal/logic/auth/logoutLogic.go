@@ -0,0 +1,46 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type LogoutLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 退出登录
+func NewLogoutLogic(ctx context.Context, svcCtx *svc.ServiceContext) *LogoutLogic {
+	return &LogoutLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *LogoutLogic) Logout(req *types.RefreshTokenReq) (resp *types.BaseResp, err error) {
+	// 吊销该令牌所在的会话, 即这台设备上的登录; 令牌无效时同样视为已退出
+	var token model.RefreshToken
+	if err := l.svcCtx.DB.Where("token_hash = ?", session.HashToken(req.RefreshToken)).First(&token).Error; err == nil {
+		if err := session.Revoke(l.svcCtx.DB, "id = ?", token.FamilyId); err != nil {
+			return nil, errors.New("退出登录失败")
+		}
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "已退出登录",
+	}, nil
+}
@@ -6,12 +6,17 @@ package auth
 import (
 	"context"
 	"errors"
+	"time"
 
+	"aifriend/internal/model"
 	"aifriend/internal/pkg/jwt"
+	"aifriend/internal/pkg/session"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type RefreshTokenLogic struct {
@@ -33,34 +38,54 @@ func (l *RefreshTokenLogic) RefreshToken(req *types.RefreshTokenReq) (resp *type
 	// 解析刷新令牌
 	claims, err := jwt.ParseToken(req.RefreshToken, l.svcCtx.Config.Auth.RefreshSecret)
 	if err != nil {
-		return nil, errors.New("无效的刷新令牌")
+		return nil, errInvalidRefreshToken
 	}
 
-	// 生成新的访问令牌
-	accessToken, err := jwt.GenerateToken(
-		claims.UserId,
-		claims.Username,
-		l.svcCtx.Config.Auth.AccessSecret,
-		l.svcCtx.Config.Auth.AccessExpire,
-	)
-	if err != nil {
-		return nil, errors.New("生成令牌失败")
-	}
+	reused := false
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		var token model.RefreshToken
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("token_hash = ?", session.HashToken(req.RefreshToken)).
+			First(&token).Error; err != nil {
+			return errInvalidRefreshToken
+		}
+		if token.RevokedAt != nil || token.UserId != claims.UserId || time.Now().After(token.ExpiresAt) {
+			return errInvalidRefreshToken
+		}
+
+		// 已轮换的令牌被再次使用, 吊销整个会话
+		if token.RotatedAt != nil {
+			reused = true
+			return session.Revoke(tx, "id = ?", token.FamilyId)
+		}
 
-	// 生成新的刷新令牌
-	refreshToken, err := jwt.GenerateToken(
-		claims.UserId,
-		claims.Username,
-		l.svcCtx.Config.Auth.RefreshSecret,
-		l.svcCtx.Config.Auth.RefreshExpire,
-	)
+		var user model.User
+		if err := tx.First(&user, token.UserId).Error; err != nil {
+			return errInvalidRefreshToken
+		}
+		// 修改密码后签发的令牌版本已变化
+		if claims.TokenVersion != user.TokenVersion {
+			return errInvalidRefreshToken
+		}
+
+		if err := tx.Model(&token).UpdateColumn("rotated_at", time.Now()).Error; err != nil {
+			return errors.New("刷新令牌失败")
+		}
+		tokens, err := session.Issue(l.ctx, tx, l.svcCtx.Config.Auth, &user, token.FamilyId)
+		if err != nil {
+			l.Errorf("签发令牌失败: %v", err)
+			return errors.New("刷新令牌失败")
+		}
+		resp = toTokenResp(tokens)
+		return nil
+	})
 	if err != nil {
-		return nil, errors.New("生成刷新令牌失败")
+		return nil, err
+	}
+	if reused {
+		l.Infof("检测到已轮换的刷新令牌被重复使用, 已吊销用户 %d 的会话", claims.UserId)
+		return nil, errInvalidRefreshToken
 	}
 
-	return &types.TokenResp{
-		AccessToken:  accessToken,
-		RefreshToken: refreshToken,
-		ExpiresIn:    l.svcCtx.Config.Auth.AccessExpire,
-	}, nil
+	return resp, nil
 }
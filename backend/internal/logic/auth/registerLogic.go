@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"aifriend/internal/model"
+	"aifriend/internal/pkg/account"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
@@ -47,6 +48,14 @@ func (l *RegisterLogic) Register(req *types.RegisterReq) (resp *types.BaseResp,
 		}, nil
 	}
 
+	email := strings.TrimSpace(req.Email)
+	if email != "" && !account.ValidEmail(email) {
+		return &types.BaseResp{
+			Code:    400,
+			Message: "邮箱格式不正确",
+		}, nil
+	}
+
 	// 检查用户名是否已存在
 	var existingUser model.User
 	if err := l.svcCtx.DB.Where("username = ?", username).First(&existingUser).Error; err == nil {
@@ -66,7 +75,7 @@ func (l *RegisterLogic) Register(req *types.RegisterReq) (resp *types.BaseResp,
 	user := model.User{
 		Username: username,
 		Password: string(hashedPassword),
-		Email:    req.Email,
+		Email:    email,
 		Profile:  "谢谢你的关注",
 	}
 
@@ -74,6 +83,13 @@ func (l *RegisterLogic) Register(req *types.RegisterReq) (resp *types.BaseResp,
 		return nil, errors.New("创建用户失败")
 	}
 
+	// 填写了邮箱时发送验证邮件
+	if user.Email != "" {
+		sendInBackground("verification", user.Id, func(ctx context.Context) error {
+			return l.svcCtx.Emails.SendVerification(ctx, &user)
+		})
+	}
+
 	return &types.BaseResp{
 		Code:    0,
 		Message: "注册成功",
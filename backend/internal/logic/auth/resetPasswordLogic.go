@@ -0,0 +1,62 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/pkg/account"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"golang.org/x/crypto/bcrypt"
+)
+
+type ResetPasswordLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 重置密码
+func NewResetPasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ResetPasswordLogic {
+	return &ResetPasswordLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ResetPasswordLogic) ResetPassword(req *types.ResetPasswordReq) (resp *types.BaseResp, err error) {
+	if len(req.NewPassword) < 6 {
+		return &types.BaseResp{
+			Code:    400,
+			Message: "密码长度至少为6位",
+		}, nil
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return nil, errors.New("密码加密失败")
+	}
+
+	// 重置成功后所有设备上的令牌都会失效
+	if err := l.svcCtx.Emails.ResetPassword(l.ctx, req.Token, string(hashedPassword)); err != nil {
+		if errors.Is(err, account.ErrInvalidToken) {
+			return &types.BaseResp{
+				Code:    400,
+				Message: "重置链接无效或已过期",
+			}, nil
+		}
+		l.Errorf("重置密码失败: %v", err)
+		return nil, errors.New("重置密码失败")
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "密码已重置, 请使用新密码登录",
+	}, nil
+}
@@ -0,0 +1,18 @@
+package auth
+
+import (
+	"errors"
+
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/types"
+)
+
+var errInvalidRefreshToken = errors.New("无效的刷新令牌")
+
+func toTokenResp(t *session.Tokens) *types.TokenResp {
+	return &types.TokenResp{
+		AccessToken:  t.AccessToken,
+		RefreshToken: t.RefreshToken,
+		ExpiresIn:    t.ExpiresIn,
+	}
+}
@@ -0,0 +1,48 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package auth
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/pkg/account"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type VerifyEmailLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 验证邮箱
+func NewVerifyEmailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *VerifyEmailLogic {
+	return &VerifyEmailLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *VerifyEmailLogic) VerifyEmail(req *types.EmailTokenReq) (resp *types.BaseResp, err error) {
+	if err := l.svcCtx.Emails.VerifyEmail(l.ctx, req.Token); err != nil {
+		if errors.Is(err, account.ErrInvalidToken) {
+			return &types.BaseResp{
+				Code:    400,
+				Message: "验证链接无效或已过期",
+			}, nil
+		}
+		l.Errorf("验证邮箱失败: %v", err)
+		return nil, errors.New("验证邮箱失败")
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "邮箱验证成功",
+	}, nil
+}
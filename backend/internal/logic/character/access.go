@@ -0,0 +1,45 @@
+package character
+
+import (
+	"aifriend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// findOwnedCharacter 查询角色并校验所有权, 校验失败时返回对应的状态码和提示
+func findOwnedCharacter(db *gorm.DB, characterId, userId int64) (*model.Character, int, string) {
+	var character model.Character
+	if err := db.First(&character, characterId).Error; err != nil {
+		return nil, 404, "角色不存在"
+	}
+
+	// 验证所有权
+	if character.UserId != userId {
+		return nil, 403, "无权访问此角色"
+	}
+
+	return &character, 0, ""
+}
+
+// findVisibleCharacter 查询角色及其标签并校验可见性: 私有角色仅所有者可访问, userId 为 0 表示未登录
+func findVisibleCharacter(db *gorm.DB, characterId, userId int64) (*model.Character, int, string) {
+	var character model.Character
+	if err := preloadTags(db).First(&character, characterId).Error; err != nil {
+		return nil, 404, "角色不存在"
+	}
+
+	if !character.VisibleTo(userId) {
+		return nil, 403, "无权访问此角色"
+	}
+
+	return &character, 0, ""
+}
+
+// findCharacter 查询角色是否存在, 用于取消互动: 角色变为私有后仍然可以取消之前的点赞、收藏和评分
+func findCharacter(db *gorm.DB, characterId int64) (*model.Character, int, string) {
+	var character model.Character
+	if err := db.First(&character, characterId).Error; err != nil {
+		return nil, 404, "角色不存在"
+	}
+	return &character, 0, ""
+}
@@ -0,0 +1,130 @@
+package character
+
+import (
+	"bytes"
+	"encoding/json"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/gif"
+	_ "image/jpeg"
+	"image/png"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/card"
+	"aifriend/internal/pkg/prompt"
+	"aifriend/internal/types"
+)
+
+// cardExtensionKey 本应用特有字段在角色卡 extensions 中的键
+const cardExtensionKey = "aifriend"
+
+// cardExtension 角色卡规范中没有的字段, 导出后再导入时可以还原
+type cardExtension struct {
+	SpeakingStyle  string                    `json:"speaking_style,omitempty"`
+	Category       string                    `json:"category,omitempty"`
+	PromptTemplate string                    `json:"prompt_template,omitempty"`
+	Generation     *types.GenerationSettings `json:"generation,omitempty"`
+}
+
+// toCard 把角色转换为 V2 角色卡
+func toCard(c *model.Character, creator string) (*card.Card, error) {
+	cc := card.New(card.Data{
+		Name:               c.Name,
+		Description:        c.Profile,
+		Personality:        c.Personality,
+		Scenario:           c.Scenario,
+		FirstMes:           c.Greeting,
+		MesExample:         c.ExampleDialogues,
+		CreatorNotes:       c.CreatorNotes,
+		AlternateGreetings: append([]string{}, c.AlternateGreetings...),
+		Tags:               tagNames(c.Tags),
+		Creator:            creator,
+	})
+
+	generation := toGenerationSettings(&c.Generation)
+	if err := cc.SetExtension(cardExtensionKey, cardExtension{
+		SpeakingStyle:  c.SpeakingStyle,
+		Category:       c.Category,
+		PromptTemplate: c.PromptTemplate,
+		Generation:     &generation,
+	}); err != nil {
+		return nil, err
+	}
+	return cc, nil
+}
+
+// cardForm 把角色卡字段映射为角色表单. 其他应用的 system_prompt 能通过模板校验时作为提示词模板,
+// 否则忽略; 其余不支持的字段(如 character_book)不导入
+func cardForm(cc *card.Card) types.CharacterForm {
+	d := cc.Data
+	form := types.CharacterForm{
+		Name:               d.Name,
+		Profile:            d.Description,
+		Scenario:           d.Scenario,
+		Greeting:           d.FirstMes,
+		AlternateGreetings: append([]string{}, d.AlternateGreetings...),
+		Personality:        d.Personality,
+		ExampleDialogues:   d.MesExample,
+		CreatorNotes:       d.CreatorNotes,
+		Tags:               normalizeTags(d.Tags),
+		Visibility:         model.VisibilityPrivate,
+	}
+
+	var ext cardExtension
+	if ok, err := cc.Extension(cardExtensionKey, &ext); ok && err == nil {
+		form.SpeakingStyle = ext.SpeakingStyle
+		form.Category = ext.Category
+		form.PromptTemplate = ext.PromptTemplate
+		if ext.Generation != nil {
+			if raw, err := json.Marshal(ext.Generation); err == nil {
+				form.Generation = string(raw)
+			}
+		}
+	} else if d.SystemPrompt != "" && prompt.Validate(d.SystemPrompt) == nil {
+		form.PromptTemplate = d.SystemPrompt
+	}
+
+	// 超出数量的标签截断, 避免因标签过多导致整张卡导入失败
+	if len(form.Tags) > maxCharacterTags {
+		form.Tags = form.Tags[:maxCharacterTags]
+	}
+	return form
+}
+
+// 角色没有可用图片时生成的占位图尺寸
+const (
+	cardImageWidth  = 400
+	cardImageHeight = 600
+)
+
+// cardImage 返回导出 PNG 角色卡使用的图片: 角色头像为 PNG 时直接使用, JPEG 和 GIF 转换为 PNG,
+// 没有头像或无法解码时生成纯色占位图
+func cardImage(spec uploadSpec, photo string) ([]byte, error) {
+	if photo != "" {
+		data, err := os.ReadFile(filepath.Join(spec.dir, filepath.Base(photo)))
+		if err == nil {
+			if http.DetectContentType(data) == "image/png" {
+				return data, nil
+			}
+			if img, _, err := image.Decode(bytes.NewReader(data)); err == nil {
+				return encodePNG(img)
+			}
+		}
+	}
+
+	img := image.NewRGBA(image.Rect(0, 0, cardImageWidth, cardImageHeight))
+	draw.Draw(img, img.Bounds(), &image.Uniform{C: color.RGBA{R: 0x5b, G: 0x6b, B: 0x8c, A: 0xff}}, image.Point{}, draw.Src)
+	return encodePNG(img)
+}
+
+func encodePNG(img image.Image) ([]byte, error) {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,73 @@
+package character
+
+import (
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/knowledge"
+	"aifriend/internal/types"
+)
+
+func toCharacterInfo(c *model.Character) types.CharacterInfo {
+	return types.CharacterInfo{
+		Id:                 c.Id,
+		CreatorId:          c.UserId,
+		Name:               c.Name,
+		Photo:              c.Photo,
+		Profile:            c.Profile,
+		BackgroundImage:    c.BackgroundImage,
+		Scenario:           c.Scenario,
+		Greeting:           c.Greeting,
+		AlternateGreetings: append([]string{}, c.AlternateGreetings...),
+		Personality:        c.Personality,
+		ExampleDialogues:   c.ExampleDialogues,
+		SpeakingStyle:      c.SpeakingStyle,
+		CreatorNotes:       c.CreatorNotes,
+		PromptTemplate:     c.PromptTemplate,
+		Generation:         toGenerationSettings(&c.Generation),
+		Visibility:         c.Visibility,
+		Category:           c.Category,
+		Tags:               tagNames(c.Tags),
+		ForkedFromId:       c.ForkedFromId,
+		ForkCount:          c.ForkCount,
+		ChatCount:          c.ChatCount,
+		LikeCount:          c.LikeCount,
+		FavoriteCount:      c.FavoriteCount,
+		RatingCount:        c.RatingCount,
+		RatingAverage:      c.RatingAverage(),
+		CreatedAt:          c.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:          c.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func tagNames(tags []model.Tag) []string {
+	names := make([]string, len(tags))
+	for i, tag := range tags {
+		names[i] = tag.Name
+	}
+	return names
+}
+
+func toKnowledgeDocumentInfo(d *model.KnowledgeDocument) types.KnowledgeDocumentInfo {
+	return types.KnowledgeDocumentInfo{
+		Id:          d.Id,
+		CharacterId: d.CharacterId,
+		Filename:    d.Filename,
+		ContentType: d.ContentType,
+		Size:        d.Size,
+		ChunkCount:  d.ChunkCount,
+		CreatedAt:   d.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toLorebookEntryInfo(e *model.LorebookEntry) types.LorebookEntryInfo {
+	return types.LorebookEntryInfo{
+		Id:          e.Id,
+		CharacterId: e.CharacterId,
+		Keywords:    knowledge.ParseKeywords(e.Keywords),
+		Content:     e.Content,
+		Constant:    e.Constant,
+		Enabled:     e.Enabled,
+		Priority:    e.Priority,
+		CreatedAt:   e.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:   e.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -9,9 +9,7 @@ import (
 	"encoding/hex"
 	"errors"
 	"fmt"
-	"io"
 	"mime/multipart"
-	"net/http"
 	"os"
 	"path/filepath"
 	"strings"
@@ -21,15 +19,9 @@ import (
 	"aifriend/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
-var allowedImageTypes = map[string]string{
-	"image/jpeg": "jpg",
-	"image/png":  "png",
-	"image/gif":  "gif",
-	"image/webp": "webp",
-}
-
 type CreateCharacterLogic struct {
 	logx.Logger
 	ctx    context.Context
@@ -45,14 +37,14 @@ func NewCreateCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *C
 	}
 }
 
-func (l *CreateCharacterLogic) CreateCharacter(name, profile string, photoHeader, bgHeader *multipart.FileHeader) (resp *types.DataResp, err error) {
+func (l *CreateCharacterLogic) CreateCharacter(form *types.CharacterForm, photoHeader, bgHeader *multipart.FileHeader) (resp *types.DataResp, err error) {
 	userId, err := userIdFromContext(l.ctx)
 	if err != nil {
 		return nil, errors.New("无效的用户身份")
 	}
 
 	// 验证必填字段
-	name = strings.TrimSpace(name)
+	name := strings.TrimSpace(form.Name)
 	if name == "" {
 		return &types.DataResp{
 			Code:    400,
@@ -60,6 +52,21 @@ func (l *CreateCharacterLogic) CreateCharacter(name, profile string, photoHeader
 		}, nil
 	}
 
+	if msg := checkCharacterForm(form); msg != "" {
+		return &types.DataResp{
+			Code:    400,
+			Message: msg,
+		}, nil
+	}
+
+	generation, msg := parseGeneration(l.svcCtx.Config.LLM, form.Generation)
+	if msg != "" {
+		return &types.DataResp{
+			Code:    400,
+			Message: msg,
+		}, nil
+	}
+
 	uploadDir := l.svcCtx.Config.Upload.CharacterDir
 	if uploadDir == "" {
 		uploadDir = "uploads/characters"
@@ -99,16 +106,39 @@ func (l *CreateCharacterLogic) CreateCharacter(name, profile string, photoHeader
 		bgPath = path
 	}
 
-	// 创建角色记录
-	character := &model.Character{
-		UserId:          userId,
-		Name:            name,
-		Profile:         profile,
-		Photo:           photoPath,
-		BackgroundImage: bgPath,
+	// 新角色默认私有
+	visibility := form.Visibility
+	if visibility == "" {
+		visibility = model.VisibilityPrivate
 	}
 
-	if err := l.svcCtx.DB.Create(character).Error; err != nil {
+	// 创建角色记录
+	character := &model.Character{
+		UserId:             userId,
+		Name:               name,
+		Profile:            form.Profile,
+		Photo:              photoPath,
+		BackgroundImage:    bgPath,
+		Scenario:           form.Scenario,
+		Greeting:           form.Greeting,
+		AlternateGreetings: form.AlternateGreetings,
+		Personality:        form.Personality,
+		ExampleDialogues:   form.ExampleDialogues,
+		SpeakingStyle:      form.SpeakingStyle,
+		CreatorNotes:       form.CreatorNotes,
+		PromptTemplate:     form.PromptTemplate,
+		Generation:         *generation,
+		Visibility:         visibility,
+		Category:           form.Category,
+	}
+
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(character).Error; err != nil {
+			return err
+		}
+		return replaceTags(tx, character, form.Tags)
+	})
+	if err != nil {
 		// 删除已上传的文件
 		if photoPath != "" {
 			os.Remove(filepath.Join(uploadDir, filepath.Base(photoPath)))
@@ -122,79 +152,20 @@ func (l *CreateCharacterLogic) CreateCharacter(name, profile string, photoHeader
 	return &types.DataResp{
 		Code:    0,
 		Message: "创建成功",
-		Data: types.CharacterInfo{
-			Id:              character.Id,
-			Name:            character.Name,
-			Photo:           character.Photo,
-			Profile:         character.Profile,
-			BackgroundImage: character.BackgroundImage,
-			CreatedAt:       character.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:       character.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
+		Data:    toCharacterInfo(character),
 	}, nil
 }
 
 func (l *CreateCharacterLogic) saveFile(fileHeader *multipart.FileHeader, uploadDir string, userId int64, prefix string) (string, error) {
-	maxSize := l.svcCtx.Config.Upload.MaxCharacterSize
-	if maxSize <= 0 {
-		maxSize = 5 * 1024 * 1024
-	}
-
-	if fileHeader.Size > maxSize {
-		return "", fmt.Errorf("图片大小不能超过%dMB", maxSize/1024/1024)
-	}
+	spec := imageUpload(l.svcCtx.Config)
+	spec.dir = uploadDir
 
-	contentType, err := detectFileType(fileHeader)
-	if err != nil {
-		return "", errors.New("读取文件失败")
-	}
-
-	extension, ok := allowedImageTypes[contentType]
-	if !ok {
-		return "", errors.New("仅支持 JPG、PNG、GIF、WEBP 格式")
-	}
-
-	randomSuffix, err := randomHex(8)
-	if err != nil {
-		return "", errors.New("生成文件名失败")
-	}
-
-	filename := fmt.Sprintf("char_%s_%d_%s.%s", prefix, userId, randomSuffix, extension)
-	filePath := filepath.Join(uploadDir, filename)
-
-	source, err := fileHeader.Open()
-	if err != nil {
-		return "", errors.New("读取文件失败")
-	}
-	defer source.Close()
-
-	target, err := os.Create(filePath)
-	if err != nil {
-		return "", errors.New("保存文件失败")
-	}
-	defer target.Close()
-
-	if _, err := io.Copy(target, source); err != nil {
-		return "", errors.New("保存文件失败")
-	}
-
-	return fmt.Sprintf("/api/v1/uploads/characters/%s", filename), nil
-}
-
-func detectFileType(fileHeader *multipart.FileHeader) (string, error) {
-	file, err := fileHeader.Open()
+	filename, _, err := spec.save(fileHeader, fmt.Sprintf("char_%s_%d", prefix, userId))
 	if err != nil {
 		return "", err
 	}
-	defer file.Close()
 
-	buffer := make([]byte, 512)
-	n, err := file.Read(buffer)
-	if err != nil && err != io.EOF {
-		return "", err
-	}
-
-	return http.DetectContentType(buffer[:n]), nil
+	return fmt.Sprintf("/api/v1/uploads/characters/%s", filename), nil
 }
 
 func randomHex(length int) (string, error) {
@@ -0,0 +1,83 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type CreateLorebookEntryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建世界设定条目
+func NewCreateLorebookEntryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateLorebookEntryLogic {
+	return &CreateLorebookEntryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateLorebookEntryLogic) CreateLorebookEntry(req *types.LorebookEntryReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if msg := checkLorebookEntry(req.Keywords, req.Content, req.Constant); msg != "" {
+		return &types.DataResp{
+			Code:    400,
+			Message: msg,
+		}, nil
+	}
+
+	if _, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId); code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var count int64
+	if err := l.svcCtx.DB.Model(&model.LorebookEntry{}).
+		Where("character_id = ?", req.Id).Count(&count).Error; err != nil {
+		return nil, errors.New("查询世界设定失败")
+	}
+	if count >= maxLorebookEntries {
+		return &types.DataResp{
+			Code:    400,
+			Message: fmt.Sprintf("每个角色最多添加%d条世界设定", maxLorebookEntries),
+		}, nil
+	}
+
+	entry := &model.LorebookEntry{
+		CharacterId: req.Id,
+		Keywords:    joinKeywords(req.Keywords),
+		Content:     strings.TrimSpace(req.Content),
+		Constant:    req.Constant,
+		Enabled:     req.Enabled,
+		Priority:    req.Priority,
+	}
+	if err := l.svcCtx.DB.Create(entry).Error; err != nil {
+		return nil, errors.New("创建世界设定失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "创建成功",
+		Data:    toLorebookEntryInfo(entry),
+	}, nil
+}
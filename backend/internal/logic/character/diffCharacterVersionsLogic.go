@@ -0,0 +1,90 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type DiffCharacterVersionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 比较角色的两个版本
+func NewDiffCharacterVersionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DiffCharacterVersionsLogic {
+	return &DiffCharacterVersionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DiffCharacterVersionsLogic) DiffCharacterVersions(req *types.CharacterVersionDiffReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	// 当前状态的快照需要标签
+	if err := l.svcCtx.DB.Model(character).Association("Tags").Find(&character.Tags); err != nil {
+		return nil, errors.New("查询角色标签失败")
+	}
+
+	from, code, msg := findSnapshot(l.svcCtx.DB, character, req.From)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+	to, code, msg := findSnapshot(l.svcCtx.DB, character, req.To)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.CharacterVersionDiff{
+			From:    req.From,
+			To:      req.To,
+			Changes: diffSnapshots(from, to),
+		},
+	}, nil
+}
+
+// findSnapshot 查询角色指定版本的快照, version 为 0 时返回当前状态, 此时角色的标签需要已经加载
+func findSnapshot(db *gorm.DB, character *model.Character, version int) (*model.CharacterSnapshot, int, string) {
+	if version == 0 {
+		snapshot := character.Snapshot()
+		return &snapshot, 0, ""
+	}
+
+	v, code, msg := findVersion(db, character.Id, version)
+	if code != 0 {
+		return nil, code, msg
+	}
+	return &v.Snapshot, 0, ""
+}
@@ -0,0 +1,172 @@
+package character
+
+import (
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/cursor"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"gorm.io/gorm"
+)
+
+// 公开角色的排序方式
+const (
+	sortNewest    = "newest"
+	sortPopular   = "popular"
+	sortLiked     = "liked"
+	sortRated     = "rated"
+	sortRelevance = "relevance"
+)
+
+const maxFacetCount = 20 // 每类筛选项最多返回的数量
+
+var discoverOrders = map[string]string{
+	sortNewest:  "characters.created_at DESC, characters.id DESC",
+	sortPopular: "characters.chat_count DESC, characters.id DESC",
+	sortLiked:   "characters.like_count DESC, characters.id DESC",
+	// 按贝叶斯平均分排序: 以 3 分为先验、5 人为权重, 避免评分人数很少的角色排在前面
+	sortRated: "(characters.rating_sum + 15.0) / (characters.rating_count + 5) DESC, characters.id DESC",
+}
+
+// discoverCharacters 分页查询公开角色, 不公开列出和私有的角色不会出现在结果中.
+// withFacets 为 true 时同时统计匹配结果中各标签和分类的角色数
+func discoverCharacters(svcCtx *svc.ServiceContext, req *types.DiscoverCharactersReq, withFacets bool) (*types.DataResp, error) {
+	keyword := strings.TrimSpace(req.Keyword)
+	sort := req.Sort
+	if sort == "" {
+		sort = sortNewest
+		if keyword != "" {
+			sort = sortRelevance
+		}
+	}
+	if _, ok := discoverOrders[sort]; !ok && sort != sortRelevance {
+		return &types.DataResp{
+			Code:    400,
+			Message: "排序方式只能是 newest、popular、liked、rated 或 relevance",
+		}, nil
+	}
+
+	page, pageSize := pagination(req.Page, req.PageSize)
+
+	db := svcCtx.DB
+	query := db.Model(&model.Character{}).Where("characters.visibility = ?", model.VisibilityPublic)
+	query = svcCtx.Search.Match(query, keyword)
+	if req.CreatorId != 0 {
+		query = query.Where("characters.user_id = ?", req.CreatorId)
+	}
+	if category := strings.TrimSpace(req.Category); category != "" {
+		query = query.Where("characters.category = ?", category)
+	}
+	// 筛选多个标签时角色需要同时带有全部标签
+	if tags := normalizeTags(strings.Split(req.Tags, ",")); len(tags) > 0 {
+		query = query.Where("characters.id IN (?)", db.Table("character_tags").
+			Select("character_tags.character_id").
+			Joins("JOIN tags ON tags.id = character_tags.tag_id").
+			Where("tags.name IN ?", tags).
+			Group("character_tags.character_id").
+			Having("COUNT(DISTINCT tags.id) = ?", len(tags)))
+	}
+	query = query.Session(&gorm.Session{})
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, err
+	}
+
+	ordered := query
+	if sort == sortRelevance {
+		ordered = ordered.Order(svcCtx.Search.Relevance(keyword))
+	} else {
+		ordered = ordered.Order(discoverOrders[sort])
+	}
+
+	var characters []model.Character
+	if err := preloadTags(ordered).Offset((page - 1) * pageSize).Limit(pageSize).Find(&characters).Error; err != nil {
+		return nil, err
+	}
+
+	list := make([]types.CharacterInfo, len(characters))
+	for i := range characters {
+		list[i] = toCharacterInfo(&characters[i])
+	}
+
+	if !withFacets {
+		return &types.DataResp{
+			Code:    0,
+			Message: "获取成功",
+			Data: types.CharacterPage{
+				List:     list,
+				Total:    total,
+				Page:     page,
+				PageSize: pageSize,
+			},
+		}, nil
+	}
+
+	facets, err := characterFacets(db, query)
+	if err != nil {
+		return nil, err
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.CharacterSearchResult{
+			List:     list,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+			Facets:   *facets,
+		},
+	}, nil
+}
+
+// characterFacets 统计匹配的角色中各标签和分类的数量, 按数量降序
+func characterFacets(db *gorm.DB, matched *gorm.DB) (*types.CharacterFacets, error) {
+	ids := matched.Select("characters.id")
+
+	tags := make([]types.FacetCount, 0)
+	if err := db.Table("character_tags").
+		Select("tags.name AS name, COUNT(*) AS count").
+		Joins("JOIN tags ON tags.id = character_tags.tag_id").
+		Where("character_tags.character_id IN (?)", ids).
+		Group("tags.name").
+		Order("count DESC, name ASC").
+		Limit(maxFacetCount).
+		Scan(&tags).Error; err != nil {
+		return nil, err
+	}
+
+	categories := make([]types.FacetCount, 0)
+	if err := matched.Select("characters.category AS name, COUNT(*) AS count").
+		Where("characters.category <> ''").
+		Group("characters.category").
+		Order("count DESC, name ASC").
+		Limit(maxFacetCount).
+		Scan(&categories).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.CharacterFacets{
+		Tags:       tags,
+		Categories: categories,
+	}, nil
+}
+
+// pagination 规范化页码分页参数, 每页数量与游标分页使用同一上限.
+// 按相关度、评分等计算值排序并需要总数和跳页的列表使用页码分页
+func pagination(page, pageSize int) (int, int) {
+	if page < 1 {
+		page = 1
+	}
+	return page, cursor.Size(pageSize)
+}
+
+// preloadTags 查询角色时一并加载标签, 按名称排序
+func preloadTags(db *gorm.DB) *gorm.DB {
+	return db.Preload("Tags", func(db *gorm.DB) *gorm.DB {
+		return db.Order("tags.name ASC")
+	})
+}
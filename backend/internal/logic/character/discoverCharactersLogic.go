@@ -0,0 +1,41 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type DiscoverCharactersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 登录用户浏览公开角色
+func NewDiscoverCharactersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *DiscoverCharactersLogic {
+	return &DiscoverCharactersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *DiscoverCharactersLogic) DiscoverCharacters(req *types.DiscoverCharactersReq) (resp *types.DataResp, err error) {
+	if _, err := userIdFromContext(l.ctx); err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	resp, err = discoverCharacters(l.svcCtx, req, false)
+	if err != nil {
+		return nil, errors.New("查询角色列表失败")
+	}
+	return resp, nil
+}
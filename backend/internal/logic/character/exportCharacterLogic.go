@@ -0,0 +1,107 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/card"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 角色卡导出格式
+const (
+	cardFormatJSON = "json"
+	cardFormatPNG  = "png"
+)
+
+// CardFile 导出的角色卡文件
+type CardFile struct {
+	Filename    string
+	ContentType string
+	Data        []byte
+}
+
+type ExportCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 导出角色卡
+func NewExportCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ExportCharacterLogic {
+	return &ExportCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// ExportCharacter 导出为 Character Card V2, 校验失败时返回 resp
+func (l *ExportCharacterLogic) ExportCharacter(req *types.ExportCharacterReq) (file *CardFile, resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, nil, errors.New("无效的用户身份")
+	}
+
+	format := req.Format
+	if format == "" {
+		format = cardFormatJSON
+	}
+	if format != cardFormatJSON && format != cardFormatPNG {
+		return nil, &types.DataResp{
+			Code:    400,
+			Message: "导出格式只能是 json 或 png",
+		}, nil
+	}
+
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return nil, &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var creator model.User
+	l.svcCtx.DB.Select("username").First(&creator, character.UserId)
+
+	cc, err := toCard(character, creator.Username)
+	if err != nil {
+		return nil, nil, errors.New("导出角色卡失败")
+	}
+	cardJSON, err := json.Marshal(cc)
+	if err != nil {
+		return nil, nil, errors.New("导出角色卡失败")
+	}
+
+	if format == cardFormatJSON {
+		return &CardFile{
+			Filename:    character.Name + ".json",
+			ContentType: "application/json",
+			Data:        cardJSON,
+		}, nil, nil
+	}
+
+	img, err := cardImage(imageUpload(l.svcCtx.Config), character.Photo)
+	if err != nil {
+		return nil, nil, errors.New("生成角色卡图片失败")
+	}
+	data, err := card.Embed(img, cardJSON)
+	if err != nil {
+		return nil, nil, errors.New("生成角色卡图片失败")
+	}
+
+	return &CardFile{
+		Filename:    character.Name + ".png",
+		ContentType: "image/png",
+		Data:        data,
+	}, nil, nil
+}
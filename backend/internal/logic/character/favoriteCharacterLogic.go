@@ -0,0 +1,61 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type FavoriteCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 收藏角色
+func NewFavoriteCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *FavoriteCharacterLogic {
+	return &FavoriteCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *FavoriteCharacterLogic) FavoriteCharacter(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	reaction := &model.CharacterFavorite{UserId: userId, CharacterId: character.Id}
+	if err := setReaction(l.svcCtx.DB, reaction, character.Id, userId, favoriteCountColumn, true); err != nil {
+		return nil, errors.New("收藏失败")
+	}
+
+	interaction, err := characterInteraction(l.svcCtx.DB, character.Id, userId)
+	if err != nil {
+		return nil, errors.New("收藏失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "收藏成功",
+		Data:    interaction,
+	}, nil
+}
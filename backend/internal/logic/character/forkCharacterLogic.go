@@ -0,0 +1,114 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type ForkCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 复制角色到我的角色库
+func NewForkCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ForkCharacterLogic {
+	return &ForkCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ForkCharacterLogic) ForkCharacter(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	// 只能复制自己可见的角色, 私有角色仅所有者可以复制
+	original, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	// 复制图片文件, 原角色删除或更换图片后副本不受影响
+	spec := imageUpload(l.svcCtx.Config)
+	var copied []string
+	removeCopied := func() {
+		for _, filename := range copied {
+			os.Remove(filepath.Join(spec.dir, filename))
+		}
+	}
+	copyImage := func(path, prefix string) string {
+		if path == "" {
+			return ""
+		}
+		filename, err := spec.copy(path, fmt.Sprintf("char_%s_%d", prefix, userId))
+		if err != nil {
+			// 原文件已丢失时副本不带该图片
+			l.Errorf("copy character image %s failed: %v", path, err)
+			return ""
+		}
+		copied = append(copied, filename)
+		return fmt.Sprintf("/api/v1/uploads/characters/%s", filename)
+	}
+
+	// 复制人设字段, 副本默认私有; 知识库、世界设定和统计数据不复制
+	fork := &model.Character{
+		UserId:             userId,
+		Name:               original.Name,
+		Profile:            original.Profile,
+		Photo:              copyImage(original.Photo, "photo"),
+		BackgroundImage:    copyImage(original.BackgroundImage, "bg"),
+		Scenario:           original.Scenario,
+		Greeting:           original.Greeting,
+		AlternateGreetings: original.AlternateGreetings,
+		Personality:        original.Personality,
+		ExampleDialogues:   original.ExampleDialogues,
+		SpeakingStyle:      original.SpeakingStyle,
+		CreatorNotes:       original.CreatorNotes,
+		PromptTemplate:     original.PromptTemplate,
+		Generation:         original.Generation,
+		Visibility:         model.VisibilityPrivate,
+		Category:           original.Category,
+		ForkedFromId:       original.Id,
+	}
+
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(fork).Error; err != nil {
+			return err
+		}
+		if err := replaceTags(tx, fork, tagNames(original.Tags)); err != nil {
+			return err
+		}
+		return tx.Model(&model.Character{}).Where("id = ?", original.Id).
+			UpdateColumn("fork_count", gorm.Expr("fork_count + 1")).Error
+	})
+	if err != nil {
+		removeCopied()
+		return nil, errors.New("复制角色失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "复制成功",
+		Data:    toCharacterInfo(fork),
+	}, nil
+}
@@ -0,0 +1,168 @@
+package character
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/knowledge"
+	"aifriend/internal/pkg/prompt"
+	"aifriend/internal/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 角色文本字段的长度限制(字符数)
+var characterFieldLimits = []struct {
+	label string
+	limit int
+	value func(form *types.CharacterForm) string
+}{
+	{"场景", 2000, func(f *types.CharacterForm) string { return f.Scenario }},
+	{"开场白", 2000, func(f *types.CharacterForm) string { return f.Greeting }},
+	{"性格概述", 1000, func(f *types.CharacterForm) string { return f.Personality }},
+	{"对话示例", 4000, func(f *types.CharacterForm) string { return f.ExampleDialogues }},
+	{"说话风格", 500, func(f *types.CharacterForm) string { return f.SpeakingStyle }},
+	{"作者说明", 2000, func(f *types.CharacterForm) string { return f.CreatorNotes }},
+	{"提示词模板", prompt.MaxTemplateLength, func(f *types.CharacterForm) string { return f.PromptTemplate }},
+}
+
+const maxAlternateGreetings = 10
+
+// 标签和分类的限制
+const (
+	maxCharacterTags = 10
+	maxTagLength     = 20
+	maxCategoryLen   = 30
+)
+
+// checkCharacterForm 校验角色表单的长度限制和提示词模板语法, 返回错误提示.
+// 备选开场白会被去掉首尾空白和空项
+func checkCharacterForm(form *types.CharacterForm) string {
+	for _, field := range characterFieldLimits {
+		if len([]rune(field.value(form))) > field.limit {
+			return fmt.Sprintf("%s最多%d字", field.label, field.limit)
+		}
+	}
+
+	if form.AlternateGreetings != nil {
+		greetings := make([]string, 0, len(form.AlternateGreetings))
+		for _, g := range form.AlternateGreetings {
+			if g = strings.TrimSpace(g); g != "" {
+				greetings = append(greetings, g)
+			}
+		}
+		if len(greetings) > maxAlternateGreetings {
+			return fmt.Sprintf("备选开场白最多%d条", maxAlternateGreetings)
+		}
+		for _, g := range greetings {
+			if len([]rune(g)) > 2000 {
+				return "每条备选开场白最多2000字"
+			}
+		}
+		form.AlternateGreetings = greetings
+	}
+
+	if form.Tags != nil {
+		tags := normalizeTags(form.Tags)
+		if len(tags) > maxCharacterTags {
+			return fmt.Sprintf("标签最多%d个", maxCharacterTags)
+		}
+		for _, tag := range tags {
+			if len([]rune(tag)) > maxTagLength {
+				return fmt.Sprintf("每个标签最多%d字", maxTagLength)
+			}
+		}
+		form.Tags = tags
+	}
+
+	form.Category = strings.TrimSpace(form.Category)
+	if len([]rune(form.Category)) > maxCategoryLen {
+		return fmt.Sprintf("分类最多%d字", maxCategoryLen)
+	}
+
+	switch form.Visibility {
+	case "", model.VisibilityPrivate, model.VisibilityUnlisted, model.VisibilityPublic:
+	default:
+		return "可见性只能是 private、unlisted 或 public"
+	}
+
+	if form.PromptTemplate != "" {
+		if err := prompt.Validate(form.PromptTemplate); err != nil {
+			return fmt.Sprintf("提示词模板格式错误: %v", err)
+		}
+	}
+
+	return ""
+}
+
+// normalizeTags 规范化标签: 每项可以用逗号分隔多个标签, 去掉 # 前缀和首尾空白,
+// 合并连续空白并转为小写, 去掉空项和重复项
+func normalizeTags(values []string) []string {
+	tags := make([]string, 0, len(values))
+	for _, value := range values {
+		for _, tag := range strings.FieldsFunc(value, func(r rune) bool { return r == ',' || r == '，' }) {
+			tag = strings.ToLower(strings.Join(strings.Fields(strings.TrimLeft(strings.TrimSpace(tag), "#")), " "))
+			if tag != "" && !slices.Contains(tags, tag) {
+				tags = append(tags, tag)
+			}
+		}
+	}
+	return tags
+}
+
+// replaceTags 把角色的标签整体替换为 names, 不存在的标签会被创建
+func replaceTags(tx *gorm.DB, character *model.Character, names []string) error {
+	if len(names) == 0 {
+		character.Tags = nil
+		return tx.Model(character).Association("Tags").Clear()
+	}
+
+	tags := make([]model.Tag, len(names))
+	for i, name := range names {
+		tags[i] = model.Tag{Name: name}
+	}
+	if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(&tags).Error; err != nil {
+		return err
+	}
+
+	tags = nil
+	if err := tx.Where("name IN ?", names).Order("name ASC").Find(&tags).Error; err != nil {
+		return err
+	}
+	if err := tx.Model(character).Association("Tags").Replace(tags); err != nil {
+		return err
+	}
+	character.Tags = tags
+	return nil
+}
+
+const (
+	maxLorebookEntries  = 100 // 每个角色最多的世界设定条目数, 条目列表不分页
+	maxLorebookContent  = 2000
+	maxLorebookKeywords = 500
+)
+
+// checkLorebookEntry 校验世界设定条目, 通过时返回空字符串
+func checkLorebookEntry(keywords, content string, constant bool) string {
+	if strings.TrimSpace(content) == "" {
+		return "条目内容不能为空"
+	}
+	if len([]rune(content)) > maxLorebookContent {
+		return fmt.Sprintf("条目内容最多%d字", maxLorebookContent)
+	}
+	if len([]rune(keywords)) > maxLorebookKeywords {
+		return fmt.Sprintf("关键词最多%d字", maxLorebookKeywords)
+	}
+	if !constant && len(knowledge.ParseKeywords(keywords)) == 0 {
+		return "非常驻条目至少需要一个关键词"
+	}
+	return ""
+}
+
+// joinKeywords 规范化关键词列表后以英文逗号保存
+func joinKeywords(keywords string) string {
+	return strings.Join(knowledge.ParseKeywords(keywords), ",")
+}
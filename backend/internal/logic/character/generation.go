@@ -0,0 +1,69 @@
+package character
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/types"
+)
+
+// parseGeneration 解析表单中 JSON 格式的生成参数并按提供方限制校验, 返回错误提示
+func parseGeneration(c llm.Config, raw string) (*model.GenerationSettings, string) {
+	var settings types.GenerationSettings
+	if strings.TrimSpace(raw) != "" {
+		if err := json.Unmarshal([]byte(raw), &settings); err != nil {
+			return nil, "生成参数格式错误"
+		}
+	}
+
+	g := model.GenerationSettings{
+		Model:            strings.TrimSpace(settings.Model),
+		Temperature:      settings.Temperature,
+		TopP:             settings.TopP,
+		MaxTokens:        settings.MaxTokens,
+		PresencePenalty:  settings.PresencePenalty,
+		FrequencyPenalty: settings.FrequencyPenalty,
+		Stop:             settings.Stop,
+	}
+	if err := c.CheckParams(llm.Params{
+		Model:            g.Model,
+		Temperature:      g.Temperature,
+		TopP:             g.TopP,
+		MaxTokens:        g.MaxTokens,
+		PresencePenalty:  g.PresencePenalty,
+		FrequencyPenalty: g.FrequencyPenalty,
+		Stop:             g.Stop,
+	}); err != nil {
+		return nil, fmt.Sprintf("生成参数错误: %v", err)
+	}
+
+	return &g, ""
+}
+
+// generationColumns 返回更新生成参数所需的列, 整体替换原有设置
+func generationColumns(g *model.GenerationSettings) map[string]interface{} {
+	return map[string]interface{}{
+		"gen_model":             g.Model,
+		"gen_temperature":       g.Temperature,
+		"gen_top_p":             g.TopP,
+		"gen_max_tokens":        g.MaxTokens,
+		"gen_presence_penalty":  g.PresencePenalty,
+		"gen_frequency_penalty": g.FrequencyPenalty,
+		"gen_stop":              g.Stop,
+	}
+}
+
+func toGenerationSettings(g *model.GenerationSettings) types.GenerationSettings {
+	return types.GenerationSettings{
+		Model:            g.Model,
+		Temperature:      g.Temperature,
+		TopP:             g.TopP,
+		MaxTokens:        g.MaxTokens,
+		PresencePenalty:  g.PresencePenalty,
+		FrequencyPenalty: g.FrequencyPenalty,
+		Stop:             append([]string{}, g.Stop...),
+	}
+}
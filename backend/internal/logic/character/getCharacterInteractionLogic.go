@@ -0,0 +1,55 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCharacterInteractionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取我与角色的互动状态
+func NewGetCharacterInteractionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCharacterInteractionLogic {
+	return &GetCharacterInteractionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCharacterInteractionLogic) GetCharacterInteraction(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	interaction, err := characterInteraction(l.svcCtx.DB, character.Id, userId)
+	if err != nil {
+		return nil, errors.New("查询互动状态失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    interaction,
+	}, nil
+}
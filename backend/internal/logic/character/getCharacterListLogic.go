@@ -8,6 +8,7 @@ import (
 	"errors"
 
 	"aifriend/internal/model"
+	"aifriend/internal/pkg/cursor"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
@@ -29,33 +30,41 @@ func NewGetCharacterListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *
 	}
 }
 
-func (l *GetCharacterListLogic) GetCharacterList() (resp *types.DataResp, err error) {
+func (l *GetCharacterListLogic) GetCharacterList(req *types.CursorReq) (resp *types.DataResp, err error) {
 	userId, err := userIdFromContext(l.ctx)
 	if err != nil {
 		return nil, errors.New("无效的用户身份")
 	}
 
+	page, err := cursor.Parse(req.Cursor, req.PageSize)
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: "无效的分页游标",
+		}, nil
+	}
+
 	var characters []model.Character
-	if err := l.svcCtx.DB.Where("user_id = ?", userId).Order("created_at DESC").Find(&characters).Error; err != nil {
+	if err := preloadTags(l.svcCtx.DB).Where("user_id = ?", userId).
+		Scopes(page.Scope("characters", "created_at")).Find(&characters).Error; err != nil {
 		return nil, errors.New("查询角色列表失败")
 	}
+	characters, next, hasMore := cursor.Trim(page, characters, func(c *model.Character) cursor.Cursor {
+		return cursor.Cursor{Time: c.CreatedAt, Id: c.Id}
+	})
 
 	list := make([]types.CharacterInfo, len(characters))
-	for i, c := range characters {
-		list[i] = types.CharacterInfo{
-			Id:              c.Id,
-			Name:            c.Name,
-			Photo:           c.Photo,
-			Profile:         c.Profile,
-			BackgroundImage: c.BackgroundImage,
-			CreatedAt:       c.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:       c.UpdatedAt.Format("2006-01-02 15:04:05"),
-		}
+	for i := range characters {
+		list[i] = toCharacterInfo(&characters[i])
 	}
 
 	return &types.DataResp{
 		Code:    0,
 		Message: "获取成功",
-		Data:    list,
+		Data: types.CharacterList{
+			List:       list,
+			NextCursor: next,
+			HasMore:    hasMore,
+		},
 	}, nil
 }
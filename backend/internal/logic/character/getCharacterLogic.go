@@ -7,7 +7,6 @@ import (
 	"context"
 	"errors"
 
-	"aifriend/internal/model"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
@@ -35,33 +34,18 @@ func (l *GetCharacterLogic) GetCharacter(req *types.CharacterIdReq) (resp *types
 		return nil, errors.New("无效的用户身份")
 	}
 
-	var character model.Character
-	if err := l.svcCtx.DB.First(&character, req.Id).Error; err != nil {
+	// 公开和不公开列出的角色所有人可见, 私有角色仅所有者可见
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
 		return &types.DataResp{
-			Code:    404,
-			Message: "角色不存在",
-		}, nil
-	}
-
-	// 验证所有权
-	if character.UserId != userId {
-		return &types.DataResp{
-			Code:    403,
-			Message: "无权访问此角色",
+			Code:    code,
+			Message: msg,
 		}, nil
 	}
 
 	return &types.DataResp{
 		Code:    0,
 		Message: "获取成功",
-		Data: types.CharacterInfo{
-			Id:              character.Id,
-			Name:            character.Name,
-			Photo:           character.Photo,
-			Profile:         character.Profile,
-			BackgroundImage: character.BackgroundImage,
-			CreatedAt:       character.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:       character.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
+		Data:    toCharacterInfo(character),
 	}, nil
 }
@@ -0,0 +1,94 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/cursor"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCharacterRatingsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取角色评价列表
+func NewGetCharacterRatingsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCharacterRatingsLogic {
+	return &GetCharacterRatingsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCharacterRatingsLogic) GetCharacterRatings(req *types.CharacterRatingsReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	page, err := cursor.Parse(req.Cursor, req.PageSize)
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: "无效的分页游标",
+		}, nil
+	}
+
+	type rating struct {
+		model.CharacterRating
+		Username string
+	}
+	var ratings []rating
+	if err := l.svcCtx.DB.Model(&model.CharacterRating{}).
+		Select("character_ratings.*, users.username").
+		Joins("LEFT JOIN users ON users.id = character_ratings.user_id").
+		Where("character_ratings.character_id = ?", character.Id).
+		Scopes(page.Scope("character_ratings", "updated_at")).
+		Scan(&ratings).Error; err != nil {
+		return nil, errors.New("查询评价失败")
+	}
+	ratings, next, hasMore := cursor.Trim(page, ratings, func(r *rating) cursor.Cursor {
+		return cursor.Cursor{Time: r.UpdatedAt, Id: r.Id}
+	})
+
+	list := make([]types.CharacterRatingInfo, len(ratings))
+	for i, r := range ratings {
+		list[i] = types.CharacterRatingInfo{
+			Id:        r.Id,
+			UserId:    r.UserId,
+			Username:  r.Username,
+			Score:     r.Score,
+			Review:    r.Review,
+			CreatedAt: r.CreatedAt.Format("2006-01-02 15:04:05"),
+			UpdatedAt: r.UpdatedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.CharacterRatingList{
+			List:       list,
+			NextCursor: next,
+			HasMore:    hasMore,
+		},
+	}, nil
+}
@@ -0,0 +1,63 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetCharacterVersionsLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取角色历史版本
+func NewGetCharacterVersionsLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetCharacterVersionsLogic {
+	return &GetCharacterVersionsLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetCharacterVersionsLogic) GetCharacterVersions(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var versions []model.CharacterVersion
+	if err := l.svcCtx.DB.Where("character_id = ?", character.Id).
+		Order("version DESC").
+		Find(&versions).Error; err != nil {
+		return nil, errors.New("查询历史版本失败")
+	}
+
+	list := make([]types.CharacterVersionInfo, len(versions))
+	for i := range versions {
+		list[i] = toCharacterVersionInfo(&versions[i])
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    list,
+	}, nil
+}
@@ -0,0 +1,91 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/cursor"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetFavoriteListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取我收藏的角色
+func NewGetFavoriteListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetFavoriteListLogic {
+	return &GetFavoriteListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetFavoriteListLogic) GetFavoriteList(req *types.CursorReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	page, err := cursor.Parse(req.Cursor, req.PageSize)
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: "无效的分页游标",
+		}, nil
+	}
+
+	// 按收藏时间倒序, 收藏后变为私有的角色不再显示
+	var favorites []model.CharacterFavorite
+	if err := l.svcCtx.DB.Model(&model.CharacterFavorite{}).
+		Select("character_favorites.*").
+		Joins("JOIN characters ON characters.id = character_favorites.character_id AND characters.deleted_at IS NULL").
+		Where("character_favorites.user_id = ?", userId).
+		Where("characters.visibility <> ? OR characters.user_id = ?", model.VisibilityPrivate, userId).
+		Scopes(page.ScopeColumns("character_favorites.created_at", "character_favorites.character_id")).
+		Find(&favorites).Error; err != nil {
+		return nil, errors.New("查询收藏失败")
+	}
+	favorites, next, hasMore := cursor.Trim(page, favorites, func(f *model.CharacterFavorite) cursor.Cursor {
+		return cursor.Cursor{Time: f.CreatedAt, Id: f.CharacterId}
+	})
+
+	ids := make([]int64, len(favorites))
+	for i, f := range favorites {
+		ids[i] = f.CharacterId
+	}
+	var characters []model.Character
+	if err := preloadTags(l.svcCtx.DB).Where("id IN ?", ids).Find(&characters).Error; err != nil {
+		return nil, errors.New("查询收藏失败")
+	}
+	byId := make(map[int64]*model.Character, len(characters))
+	for i := range characters {
+		byId[characters[i].Id] = &characters[i]
+	}
+
+	list := make([]types.CharacterInfo, 0, len(ids))
+	for _, id := range ids {
+		if c, ok := byId[id]; ok {
+			list = append(list, toCharacterInfo(c))
+		}
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.CharacterList{
+			List:       list,
+			NextCursor: next,
+			HasMore:    hasMore,
+		},
+	}, nil
+}
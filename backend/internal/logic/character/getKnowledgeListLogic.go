@@ -0,0 +1,61 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetKnowledgeListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取知识库文档列表
+func NewGetKnowledgeListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetKnowledgeListLogic {
+	return &GetKnowledgeListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetKnowledgeListLogic) GetKnowledgeList(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if _, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId); code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var documents []model.KnowledgeDocument
+	if err := l.svcCtx.DB.Where("character_id = ?", req.Id).
+		Order("id DESC").Find(&documents).Error; err != nil {
+		return nil, errors.New("查询知识库失败")
+	}
+
+	list := make([]types.KnowledgeDocumentInfo, len(documents))
+	for i := range documents {
+		list[i] = toKnowledgeDocumentInfo(&documents[i])
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    list,
+	}, nil
+}
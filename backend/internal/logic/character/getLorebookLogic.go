@@ -0,0 +1,61 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetLorebookLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取世界设定条目列表
+func NewGetLorebookLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetLorebookLogic {
+	return &GetLorebookLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetLorebookLogic) GetLorebook(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if _, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId); code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var entries []model.LorebookEntry
+	if err := l.svcCtx.DB.Where("character_id = ?", req.Id).
+		Order("priority DESC, id ASC").Find(&entries).Error; err != nil {
+		return nil, errors.New("查询世界设定失败")
+	}
+
+	list := make([]types.LorebookEntryInfo, len(entries))
+	for i := range entries {
+		list[i] = toLorebookEntryInfo(&entries[i])
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    list,
+	}, nil
+}
@@ -0,0 +1,37 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetPublicCharacterListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 浏览公开角色
+func NewGetPublicCharacterListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPublicCharacterListLogic {
+	return &GetPublicCharacterListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetPublicCharacterListLogic) GetPublicCharacterList(req *types.DiscoverCharactersReq) (resp *types.DataResp, err error) {
+	resp, err = discoverCharacters(l.svcCtx, req, false)
+	if err != nil {
+		return nil, errors.New("查询角色列表失败")
+	}
+	return resp, nil
+}
@@ -0,0 +1,45 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetPublicCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 查看公开角色
+func NewGetPublicCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetPublicCharacterLogic {
+	return &GetPublicCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetPublicCharacterLogic) GetPublicCharacter(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	// 未登录时只能访问公开和不公开列出的角色
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, 0)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    toCharacterInfo(character),
+	}, nil
+}
@@ -0,0 +1,87 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetRecommendedCharactersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取为我推荐的角色
+func NewGetRecommendedCharactersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetRecommendedCharactersLogic {
+	return &GetRecommendedCharactersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetRecommendedCharactersLogic) GetRecommendedCharacters() (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	// 推荐由后台任务定期计算, 计算后变为非公开的角色不再显示
+	var recommendations []model.CharacterRecommendation
+	if err := l.svcCtx.DB.Model(&model.CharacterRecommendation{}).
+		Joins("JOIN characters ON characters.id = character_recommendations.character_id AND characters.deleted_at IS NULL").
+		Where("character_recommendations.user_id = ? AND characters.visibility = ?", userId, model.VisibilityPublic).
+		Order("character_recommendations.score DESC, character_recommendations.character_id ASC").
+		Find(&recommendations).Error; err != nil {
+		return nil, errors.New("查询推荐失败")
+	}
+
+	ids := make([]int64, 0, len(recommendations)*2)
+	for _, r := range recommendations {
+		ids = append(ids, r.CharacterId, r.SourceCharacterId)
+	}
+	var characters []model.Character
+	if err := preloadTags(l.svcCtx.DB).Where("id IN ?", ids).Find(&characters).Error; err != nil {
+		return nil, errors.New("查询推荐失败")
+	}
+	byId := make(map[int64]*model.Character, len(characters))
+	for i := range characters {
+		byId[characters[i].Id] = &characters[i]
+	}
+
+	list := make([]types.CharacterRecommendationInfo, 0, len(recommendations))
+	for _, r := range recommendations {
+		character, ok := byId[r.CharacterId]
+		if !ok {
+			continue
+		}
+		info := types.CharacterRecommendationInfo{
+			Character: toCharacterInfo(character),
+			SourceId:  r.SourceCharacterId,
+			Reason:    "为你推荐",
+			Score:     r.Score,
+		}
+		// 依据角色已删除时只给出通用的推荐理由
+		if source, ok := byId[r.SourceCharacterId]; ok {
+			info.SourceName = source.Name
+			info.Reason = fmt.Sprintf("因为你和%s聊过", source.Name)
+		}
+		list = append(list, info)
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    list,
+	}, nil
+}
@@ -0,0 +1,68 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetTrendingCharactersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 热门角色
+func NewGetTrendingCharactersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetTrendingCharactersLogic {
+	return &GetTrendingCharactersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetTrendingCharactersLogic) GetTrendingCharacters(req *types.PageReq) (resp *types.DataResp, err error) {
+	page, pageSize := pagination(req.Page, req.PageSize)
+
+	// 热度由后台任务定期计算, 计算后变为非公开的角色不再显示
+	query := l.svcCtx.DB.Model(&model.Character{}).
+		Joins("JOIN character_rankings ON character_rankings.character_id = characters.id").
+		Where("characters.visibility = ?", model.VisibilityPublic)
+
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, errors.New("查询热门角色失败")
+	}
+
+	var characters []model.Character
+	if err := preloadTags(query).
+		Order("character_rankings.score DESC, characters.id DESC").
+		Offset((page - 1) * pageSize).Limit(pageSize).
+		Find(&characters).Error; err != nil {
+		return nil, errors.New("查询热门角色失败")
+	}
+
+	list := make([]types.CharacterInfo, len(characters))
+	for i := range characters {
+		list[i] = toCharacterInfo(&characters[i])
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.CharacterPage{
+			List:     list,
+			Total:    total,
+			Page:     page,
+			PageSize: pageSize,
+		},
+	}, nil
+}
@@ -0,0 +1,167 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/card"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// maxCharacterName 角色名称的最大字符数, 与数据库字段长度一致
+const maxCharacterName = 50
+
+type ImportCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 导入角色卡 (multipart form, 支持 PNG 和 JSON)
+func NewImportCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *ImportCharacterLogic {
+	return &ImportCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *ImportCharacterLogic) ImportCharacter(fileHeader *multipart.FileHeader) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	spec := imageUpload(l.svcCtx.Config)
+	if fileHeader.Size > spec.maxSize {
+		return &types.DataResp{
+			Code:    400,
+			Message: fmt.Sprintf("角色卡大小不能超过%dMB", spec.maxSize/1024/1024),
+		}, nil
+	}
+
+	source, err := fileHeader.Open()
+	if err != nil {
+		return nil, errors.New("读取文件失败")
+	}
+	data, err := io.ReadAll(source)
+	source.Close()
+	if err != nil {
+		return nil, errors.New("读取文件失败")
+	}
+
+	// PNG 角色卡的图片同时作为角色头像
+	var cardJSON, photo []byte
+	switch {
+	case bytes.HasPrefix(data, []byte("\x89PNG")):
+		cardJSON, err = card.Extract(data)
+		if err != nil {
+			return &types.DataResp{
+				Code:    400,
+				Message: "图片中没有角色卡数据",
+			}, nil
+		}
+		photo = data
+	case bytes.HasPrefix(bytes.TrimSpace(data), []byte("{")):
+		cardJSON = data
+	default:
+		return &types.DataResp{
+			Code:    400,
+			Message: "仅支持 PNG 或 JSON 格式的角色卡",
+		}, nil
+	}
+
+	cc, err := card.Parse(cardJSON)
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: "角色卡格式错误",
+		}, nil
+	}
+
+	form := cardForm(cc)
+	name := strings.TrimSpace(form.Name)
+	if len([]rune(name)) > maxCharacterName {
+		return &types.DataResp{
+			Code:    400,
+			Message: fmt.Sprintf("角色名称最多%d字", maxCharacterName),
+		}, nil
+	}
+	if msg := checkCharacterForm(&form); msg != "" {
+		return &types.DataResp{
+			Code:    400,
+			Message: msg,
+		}, nil
+	}
+
+	// 生成参数可能来自配置不同的实例, 不符合本实例的限制时使用默认值
+	generation, msg := parseGeneration(l.svcCtx.Config.LLM, form.Generation)
+	if msg != "" {
+		l.Infof("ignore generation settings of imported card: %s", msg)
+		generation = &model.GenerationSettings{}
+	}
+
+	var photoPath string
+	if photo != nil {
+		filename, _, err := spec.saveData(photo, fmt.Sprintf("char_photo_%d", userId))
+		if err != nil {
+			return &types.DataResp{
+				Code:    400,
+				Message: err.Error(),
+			}, nil
+		}
+		photoPath = fmt.Sprintf("/api/v1/uploads/characters/%s", filename)
+	}
+
+	character := &model.Character{
+		UserId:             userId,
+		Name:               name,
+		Profile:            form.Profile,
+		Photo:              photoPath,
+		Scenario:           form.Scenario,
+		Greeting:           form.Greeting,
+		AlternateGreetings: form.AlternateGreetings,
+		Personality:        form.Personality,
+		ExampleDialogues:   form.ExampleDialogues,
+		SpeakingStyle:      form.SpeakingStyle,
+		CreatorNotes:       form.CreatorNotes,
+		PromptTemplate:     form.PromptTemplate,
+		Generation:         *generation,
+		Visibility:         model.VisibilityPrivate,
+		Category:           form.Category,
+	}
+
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(character).Error; err != nil {
+			return err
+		}
+		return replaceTags(tx, character, form.Tags)
+	})
+	if err != nil {
+		if photoPath != "" {
+			os.Remove(filepath.Join(spec.dir, filepath.Base(photoPath)))
+		}
+		return nil, errors.New("导入角色失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "导入成功",
+		Data:    toCharacterInfo(character),
+	}, nil
+}
@@ -0,0 +1,142 @@
+package character
+
+import (
+	"aifriend/internal/model"
+	"aifriend/internal/types"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// 角色上的互动计数列
+const (
+	likeCountColumn     = "like_count"
+	favoriteCountColumn = "favorite_count"
+)
+
+const maxReviewLength = 1000
+
+// setReaction 幂等地添加或取消点赞、收藏. 只有记录实际插入或删除时才在同一事务中更新角色上的计数,
+// 重复请求不会改变计数
+func setReaction(db *gorm.DB, reaction interface{}, characterId, userId int64, counter string, on bool) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var result *gorm.DB
+		delta := 1
+		if on {
+			result = tx.Clauses(clause.OnConflict{DoNothing: true}).Create(reaction)
+		} else {
+			result = tx.Where("user_id = ? AND character_id = ?", userId, characterId).Delete(reaction)
+			delta = -1
+		}
+		if result.Error != nil {
+			return result.Error
+		}
+		if result.RowsAffected == 0 {
+			return nil
+		}
+		return tx.Model(&model.Character{}).Where("id = ?", characterId).
+			UpdateColumn(counter, gorm.Expr(counter+" + ?", delta)).Error
+	})
+}
+
+// setRating 添加或覆盖用户的评分, 同时维护角色的评分人数和总分
+func setRating(db *gorm.DB, characterId, userId int64, score int, review string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rating model.CharacterRating
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND character_id = ?", userId, characterId).
+			Limit(1).Find(&rating).Error; err != nil {
+			return err
+		}
+
+		if rating.Id == 0 {
+			rating = model.CharacterRating{
+				UserId:      userId,
+				CharacterId: characterId,
+				Score:       score,
+				Review:      review,
+			}
+			if err := tx.Create(&rating).Error; err != nil {
+				return err
+			}
+			return tx.Model(&model.Character{}).Where("id = ?", characterId).UpdateColumns(map[string]interface{}{
+				"rating_count": gorm.Expr("rating_count + 1"),
+				"rating_sum":   gorm.Expr("rating_sum + ?", score),
+			}).Error
+		}
+
+		delta := score - rating.Score
+		if err := tx.Model(&rating).Updates(map[string]interface{}{
+			"score":  score,
+			"review": review,
+		}).Error; err != nil {
+			return err
+		}
+		if delta == 0 {
+			return nil
+		}
+		return tx.Model(&model.Character{}).Where("id = ?", characterId).
+			UpdateColumn("rating_sum", gorm.Expr("rating_sum + ?", delta)).Error
+	})
+}
+
+// removeRating 删除用户的评分, 没有评分时不做任何操作
+func removeRating(db *gorm.DB, characterId, userId int64) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var rating model.CharacterRating
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).
+			Where("user_id = ? AND character_id = ?", userId, characterId).
+			Limit(1).Find(&rating).Error; err != nil {
+			return err
+		}
+		if rating.Id == 0 {
+			return nil
+		}
+
+		if err := tx.Delete(&rating).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Character{}).Where("id = ?", characterId).UpdateColumns(map[string]interface{}{
+			"rating_count": gorm.Expr("rating_count - 1"),
+			"rating_sum":   gorm.Expr("rating_sum - ?", rating.Score),
+		}).Error
+	})
+}
+
+// characterInteraction 查询当前用户与角色的互动状态和角色最新的互动统计
+func characterInteraction(db *gorm.DB, characterId, userId int64) (*types.CharacterInteraction, error) {
+	var character model.Character
+	if err := db.Select("id", "like_count", "favorite_count", "rating_count", "rating_sum").
+		First(&character, characterId).Error; err != nil {
+		return nil, err
+	}
+
+	var liked, favorited int64
+	if err := db.Model(&model.CharacterLike{}).
+		Where("user_id = ? AND character_id = ?", userId, characterId).
+		Count(&liked).Error; err != nil {
+		return nil, err
+	}
+	if err := db.Model(&model.CharacterFavorite{}).
+		Where("user_id = ? AND character_id = ?", userId, characterId).
+		Count(&favorited).Error; err != nil {
+		return nil, err
+	}
+
+	var rating model.CharacterRating
+	if err := db.Where("user_id = ? AND character_id = ?", userId, characterId).
+		Limit(1).Find(&rating).Error; err != nil {
+		return nil, err
+	}
+
+	return &types.CharacterInteraction{
+		Liked:         liked > 0,
+		Favorited:     favorited > 0,
+		Score:         rating.Score,
+		Review:        rating.Review,
+		LikeCount:     character.LikeCount,
+		FavoriteCount: character.FavoriteCount,
+		RatingCount:   character.RatingCount,
+		RatingAverage: character.RatingAverage(),
+	}, nil
+}
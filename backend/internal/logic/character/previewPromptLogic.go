@@ -0,0 +1,115 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/memory"
+	"aifriend/internal/pkg/prompt"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PreviewPromptLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 预览角色系统提示词
+func NewPreviewPromptLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PreviewPromptLogic {
+	return &PreviewPromptLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+// PreviewPrompt 以当前用户身份渲染角色的系统提示词.
+// 请求中携带 template 时渲染该草稿模板, 便于保存前调试.
+func (l *PreviewPromptLogic) PreviewPrompt(req *types.PreviewPromptReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	var character model.Character
+	if err := l.svcCtx.DB.First(&character, req.Id).Error; err != nil {
+		return &types.DataResp{
+			Code:    404,
+			Message: "角色不存在",
+		}, nil
+	}
+
+	// 验证所有权
+	if character.UserId != userId {
+		return &types.DataResp{
+			Code:    403,
+			Message: "无权访问此角色",
+		}, nil
+	}
+
+	var user model.User
+	if err := l.svcCtx.DB.First(&user, userId).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+
+	template := character.PromptTemplate
+	if req.Template != "" {
+		if len([]rune(req.Template)) > prompt.MaxTemplateLength {
+			return &types.DataResp{
+				Code:    400,
+				Message: fmt.Sprintf("提示词模板最多%d字", prompt.MaxTemplateLength),
+			}, nil
+		}
+		template = req.Template
+	}
+
+	isDefault := strings.TrimSpace(template) == ""
+	if isDefault {
+		template = prompt.DefaultTemplate
+	}
+
+	// 不针对具体消息检索, 展示置顶和最近的记忆
+	data := prompt.NewData(&character, &user)
+	memories, err := l.svcCtx.Memory.Relevant(l.ctx, userId, character.Id, "")
+	if err != nil {
+		l.Errorf("load memories of character %d failed: %v", character.Id, err)
+	}
+	data.Memories = memory.Contents(memories)
+
+	// 没有消息可供匹配, 只展示常驻的世界设定
+	entries, err := l.svcCtx.Knowledge.Lore(l.ctx, character.Id, nil)
+	if err != nil {
+		l.Errorf("load lorebook of character %d failed: %v", character.Id, err)
+	}
+	for _, e := range entries {
+		data.Lore = append(data.Lore, data.Expand(e.Content))
+	}
+
+	text, err := prompt.Render(template, data)
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: fmt.Sprintf("提示词模板渲染失败: %v", err),
+		}, nil
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.PromptPreview{
+			Prompt:    text,
+			Template:  template,
+			IsDefault: isDefault,
+		},
+	}, nil
+}
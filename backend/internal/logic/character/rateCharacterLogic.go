@@ -0,0 +1,83 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RateCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 评分角色
+func NewRateCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RateCharacterLogic {
+	return &RateCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RateCharacterLogic) RateCharacter(req *types.RateCharacterReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	if character.UserId == userId {
+		return &types.DataResp{
+			Code:    400,
+			Message: "不能给自己的角色评分",
+		}, nil
+	}
+
+	if req.Score < 1 || req.Score > 5 {
+		return &types.DataResp{
+			Code:    400,
+			Message: "评分只能是1到5星",
+		}, nil
+	}
+
+	review := strings.TrimSpace(req.Review)
+	if len([]rune(review)) > maxReviewLength {
+		return &types.DataResp{
+			Code:    400,
+			Message: fmt.Sprintf("评价最多%d字", maxReviewLength),
+		}, nil
+	}
+
+	if err := setRating(l.svcCtx.DB, character.Id, userId, req.Score, review); err != nil {
+		return nil, errors.New("评分失败")
+	}
+
+	interaction, err := characterInteraction(l.svcCtx.DB, character.Id, userId)
+	if err != nil {
+		return nil, errors.New("评分失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "评分成功",
+		Data:    interaction,
+	}, nil
+}
@@ -6,14 +6,13 @@ package character
 import (
 	"context"
 	"errors"
-	"os"
-	"path/filepath"
 
 	"aifriend/internal/model"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type RemoveCharacterLogic struct {
@@ -58,19 +57,29 @@ func (l *RemoveCharacterLogic) RemoveCharacter(req *types.CharacterIdReq) (resp
 		uploadDir = "uploads/characters"
 	}
 
-	// 删除关联的图片文件
-	if character.Photo != "" {
-		os.Remove(filepath.Join(uploadDir, filepath.Base(character.Photo)))
-	}
-	if character.BackgroundImage != "" {
-		os.Remove(filepath.Join(uploadDir, filepath.Base(character.BackgroundImage)))
+	var versions []model.CharacterVersion
+	if err := l.svcCtx.DB.Where("character_id = ?", character.Id).Find(&versions).Error; err != nil {
+		return nil, errors.New("删除角色失败")
 	}
 
-	// 删除角色记录
-	if err := l.svcCtx.DB.Delete(&character).Error; err != nil {
+	// 删除角色记录和历史版本
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("character_id = ?", character.Id).Delete(&model.CharacterVersion{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&character).Error
+	})
+	if err != nil {
 		return nil, errors.New("删除角色失败")
 	}
 
+	// 删除关联的图片文件, 包括历史版本中的图片
+	images := []string{character.Photo, character.BackgroundImage}
+	for i := range versions {
+		images = append(images, versions[i].Snapshot.Photo, versions[i].Snapshot.BackgroundImage)
+	}
+	removeImages(uploadDir, images)
+
 	return &types.BaseResp{
 		Code:    0,
 		Message: "删除成功",
@@ -0,0 +1,68 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RemoveKnowledgeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除知识库文档
+func NewRemoveKnowledgeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveKnowledgeLogic {
+	return &RemoveKnowledgeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveKnowledgeLogic) RemoveKnowledge(req *types.KnowledgeIdReq) (resp *types.BaseResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if _, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId); code != 0 {
+		return &types.BaseResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var document model.KnowledgeDocument
+	if err := l.svcCtx.DB.Where("id = ? AND character_id = ?", req.DocumentId, req.Id).
+		First(&document).Error; err != nil {
+		return &types.BaseResp{
+			Code:    404,
+			Message: "文档不存在",
+		}, nil
+	}
+
+	if err := l.svcCtx.Knowledge.RemoveDocument(l.ctx, &document); err != nil {
+		l.Errorf("remove knowledge document %d failed: %v", document.Id, err)
+		return nil, errors.New("删除文档失败")
+	}
+
+	// 删除文档文件
+	os.Remove(filepath.Join(documentUpload(l.svcCtx.Config).dir, filepath.Base(document.Path)))
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "删除成功",
+	}, nil
+}
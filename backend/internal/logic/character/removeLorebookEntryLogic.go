@@ -0,0 +1,61 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RemoveLorebookEntryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除世界设定条目
+func NewRemoveLorebookEntryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveLorebookEntryLogic {
+	return &RemoveLorebookEntryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveLorebookEntryLogic) RemoveLorebookEntry(req *types.LorebookEntryIdReq) (resp *types.BaseResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if _, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId); code != 0 {
+		return &types.BaseResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	result := l.svcCtx.DB.Where("id = ? AND character_id = ?", req.EntryId, req.Id).
+		Delete(&model.LorebookEntry{})
+	if result.Error != nil {
+		return nil, errors.New("删除世界设定失败")
+	}
+	if result.RowsAffected == 0 {
+		return &types.BaseResp{
+			Code:    404,
+			Message: "世界设定条目不存在",
+		}, nil
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "删除成功",
+	}, nil
+}
@@ -0,0 +1,59 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RemoveRatingLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除评分
+func NewRemoveRatingLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveRatingLogic {
+	return &RemoveRatingLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveRatingLogic) RemoveRating(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findCharacter(l.svcCtx.DB, req.Id)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	if err := removeRating(l.svcCtx.DB, character.Id, userId); err != nil {
+		return nil, errors.New("删除评分失败")
+	}
+
+	interaction, err := characterInteraction(l.svcCtx.DB, character.Id, userId)
+	if err != nil {
+		return nil, errors.New("删除评分失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "已删除评分",
+		Data:    interaction,
+	}, nil
+}
@@ -0,0 +1,83 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RollbackCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 回滚角色到历史版本
+func NewRollbackCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RollbackCharacterLogic {
+	return &RollbackCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RollbackCharacterLogic) RollbackCharacter(req *types.CharacterVersionReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	version, code, msg := findVersion(l.svcCtx.DB, character.Id, req.Version)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	if err := l.svcCtx.DB.Model(character).Association("Tags").Find(&character.Tags); err != nil {
+		return nil, errors.New("回滚角色失败")
+	}
+
+	// 回滚前的状态同样保存为新版本, 回滚本身也可以撤销.
+	// 回滚到最早的版本时该版本会被清理, 它的图片仍要保留给回滚后的角色使用
+	var unused []string
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if unused, err = saveVersion(tx, character, version.Snapshot.Photo, version.Snapshot.BackgroundImage); err != nil {
+			return err
+		}
+		if err := tx.Model(character).Updates(snapshotColumns(&version.Snapshot)).Error; err != nil {
+			return err
+		}
+		return replaceTags(tx, character, version.Snapshot.Tags)
+	})
+	if err != nil {
+		return nil, errors.New("回滚角色失败")
+	}
+	removeImages(imageUpload(l.svcCtx.Config).dir, unused)
+
+	preloadTags(l.svcCtx.DB).First(character, character.Id)
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "回滚成功",
+		Data:    toCharacterInfo(character),
+	}, nil
+}
@@ -0,0 +1,37 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SearchCharactersLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 搜索公开角色
+func NewSearchCharactersLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SearchCharactersLogic {
+	return &SearchCharactersLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SearchCharactersLogic) SearchCharacters(req *types.DiscoverCharactersReq) (resp *types.DataResp, err error) {
+	resp, err = discoverCharacters(l.svcCtx, req, true)
+	if err != nil {
+		return nil, errors.New("搜索角色失败")
+	}
+	return resp, nil
+}
@@ -0,0 +1,61 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UnfavoriteCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 取消收藏
+func NewUnfavoriteCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UnfavoriteCharacterLogic {
+	return &UnfavoriteCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UnfavoriteCharacterLogic) UnfavoriteCharacter(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findCharacter(l.svcCtx.DB, req.Id)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	reaction := &model.CharacterFavorite{UserId: userId, CharacterId: character.Id}
+	if err := setReaction(l.svcCtx.DB, reaction, character.Id, userId, favoriteCountColumn, false); err != nil {
+		return nil, errors.New("取消收藏失败")
+	}
+
+	interaction, err := characterInteraction(l.svcCtx.DB, character.Id, userId)
+	if err != nil {
+		return nil, errors.New("取消收藏失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "已取消收藏",
+		Data:    interaction,
+	}, nil
+}
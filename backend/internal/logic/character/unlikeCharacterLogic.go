@@ -0,0 +1,61 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UnlikeCharacterLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 取消点赞
+func NewUnlikeCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UnlikeCharacterLogic {
+	return &UnlikeCharacterLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UnlikeCharacterLogic) UnlikeCharacter(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findCharacter(l.svcCtx.DB, req.Id)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	reaction := &model.CharacterLike{UserId: userId, CharacterId: character.Id}
+	if err := setReaction(l.svcCtx.DB, reaction, character.Id, userId, likeCountColumn, false); err != nil {
+		return nil, errors.New("取消点赞失败")
+	}
+
+	interaction, err := characterInteraction(l.svcCtx.DB, character.Id, userId)
+	if err != nil {
+		return nil, errors.New("取消点赞失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "已取消点赞",
+		Data:    interaction,
+	}, nil
+}
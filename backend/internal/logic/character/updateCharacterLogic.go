@@ -7,10 +7,8 @@ import (
 	"context"
 	"errors"
 	"fmt"
-	"io"
 	"mime/multipart"
 	"os"
-	"path/filepath"
 	"strings"
 
 	"aifriend/internal/model"
@@ -18,6 +16,7 @@ import (
 	"aifriend/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
 )
 
 type UpdateCharacterLogic struct {
@@ -35,15 +34,15 @@ func NewUpdateCharacterLogic(ctx context.Context, svcCtx *svc.ServiceContext) *U
 	}
 }
 
-func (l *UpdateCharacterLogic) UpdateCharacter(characterId int64, name, profile string, photoHeader, bgHeader *multipart.FileHeader) (resp *types.DataResp, err error) {
+func (l *UpdateCharacterLogic) UpdateCharacter(characterId int64, form *types.CharacterForm, photoHeader, bgHeader *multipart.FileHeader) (resp *types.DataResp, err error) {
 	userId, err := userIdFromContext(l.ctx)
 	if err != nil {
 		return nil, errors.New("无效的用户身份")
 	}
 
-	// 查找角色
+	// 查找角色, 标签用于保存修改前的版本
 	var character model.Character
-	if err := l.svcCtx.DB.First(&character, characterId).Error; err != nil {
+	if err := preloadTags(l.svcCtx.DB).First(&character, characterId).Error; err != nil {
 		return &types.DataResp{
 			Code:    404,
 			Message: "角色不存在",
@@ -66,15 +65,69 @@ func (l *UpdateCharacterLogic) UpdateCharacter(characterId int64, name, profile
 	// 更新字段
 	updates := make(map[string]interface{})
 
-	name = strings.TrimSpace(name)
+	if msg := checkCharacterForm(form); msg != "" {
+		return &types.DataResp{
+			Code:    400,
+			Message: msg,
+		}, nil
+	}
+
+	name := strings.TrimSpace(form.Name)
 	if name != "" {
 		updates["name"] = name
 	}
 
-	if profile != "" {
-		updates["profile"] = profile
+	if form.Profile != "" {
+		updates["profile"] = form.Profile
+	}
+	if form.Scenario != "" {
+		updates["scenario"] = form.Scenario
+	}
+	if form.Greeting != "" {
+		updates["greeting"] = form.Greeting
+	}
+	// 传入备选开场白字段即整体替换, 只传空值可清空
+	if form.AlternateGreetings != nil {
+		updates["alternate_greetings"] = model.StringList(form.AlternateGreetings)
+	}
+	if form.Personality != "" {
+		updates["personality"] = form.Personality
+	}
+	if form.ExampleDialogues != "" {
+		updates["example_dialogues"] = form.ExampleDialogues
+	}
+	if form.SpeakingStyle != "" {
+		updates["speaking_style"] = form.SpeakingStyle
+	}
+	if form.CreatorNotes != "" {
+		updates["creator_notes"] = form.CreatorNotes
+	}
+	if form.PromptTemplate != "" {
+		updates["prompt_template"] = form.PromptTemplate
+	}
+	if form.Visibility != "" {
+		updates["visibility"] = form.Visibility
+	}
+	if form.Category != "" {
+		updates["category"] = form.Category
+	}
+	// 传入生成参数即整体替换, 传入 {} 可恢复默认
+	if form.Generation != "" {
+		generation, msg := parseGeneration(l.svcCtx.Config.LLM, form.Generation)
+		if msg != "" {
+			return &types.DataResp{
+				Code:    400,
+				Message: msg,
+			}, nil
+		}
+		for column, value := range generationColumns(generation) {
+			updates[column] = value
+		}
 	}
 
+	// 之后的步骤失败时删除本次上传的文件
+	var saved []string
+
 	// 处理新头像
 	if photoHeader != nil {
 		if err := os.MkdirAll(uploadDir, 0o755); err != nil {
@@ -89,109 +142,80 @@ func (l *UpdateCharacterLogic) UpdateCharacter(characterId int64, name, profile
 			}, nil
 		}
 
-		// 删除旧头像
-		if character.Photo != "" {
-			oldPath := filepath.Join(uploadDir, filepath.Base(character.Photo))
-			os.Remove(oldPath)
-		}
-
+		// 旧头像保留在历史版本中
 		updates["photo"] = path
+		saved = append(saved, path)
 	}
 
 	// 处理新背景图
 	if bgHeader != nil {
 		if err := os.MkdirAll(uploadDir, 0o755); err != nil {
+			removeImages(uploadDir, saved)
 			return nil, errors.New("创建上传目录失败")
 		}
 
 		path, err := l.saveFile(bgHeader, uploadDir, userId, "bg")
 		if err != nil {
+			removeImages(uploadDir, saved)
 			return &types.DataResp{
 				Code:    400,
 				Message: err.Error(),
 			}, nil
 		}
 
-		// 删除旧背景图
-		if character.BackgroundImage != "" {
-			oldPath := filepath.Join(uploadDir, filepath.Base(character.BackgroundImage))
-			os.Remove(oldPath)
-		}
-
+		// 旧背景图保留在历史版本中
 		updates["background_image"] = path
+		saved = append(saved, path)
 	}
 
-	if len(updates) == 0 {
+	if len(updates) == 0 && form.Tags == nil {
+		removeImages(uploadDir, saved)
 		return &types.DataResp{
 			Code:    400,
 			Message: "没有需要更新的数据",
 		}, nil
 	}
 
-	if err := l.svcCtx.DB.Model(&character).Updates(updates).Error; err != nil {
+	// 修改前的状态保存为新版本; 传入标签字段即整体替换, 只传空值可清空
+	var unused []string
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		var err error
+		if unused, err = saveVersion(tx, &character); err != nil {
+			return err
+		}
+		if len(updates) > 0 {
+			if err := tx.Model(&character).Updates(updates).Error; err != nil {
+				return err
+			}
+		}
+		if form.Tags != nil {
+			return replaceTags(tx, &character, form.Tags)
+		}
+		return nil
+	})
+	if err != nil {
+		removeImages(uploadDir, saved)
 		return nil, errors.New("更新角色失败")
 	}
+	removeImages(uploadDir, unused)
 
 	// 重新查询获取最新数据
-	l.svcCtx.DB.First(&character, characterId)
+	preloadTags(l.svcCtx.DB).First(&character, characterId)
 
 	return &types.DataResp{
 		Code:    0,
 		Message: "更新成功",
-		Data: types.CharacterInfo{
-			Id:              character.Id,
-			Name:            character.Name,
-			Photo:           character.Photo,
-			Profile:         character.Profile,
-			BackgroundImage: character.BackgroundImage,
-			CreatedAt:       character.CreatedAt.Format("2006-01-02 15:04:05"),
-			UpdatedAt:       character.UpdatedAt.Format("2006-01-02 15:04:05"),
-		},
+		Data:    toCharacterInfo(&character),
 	}, nil
 }
 
 func (l *UpdateCharacterLogic) saveFile(fileHeader *multipart.FileHeader, uploadDir string, userId int64, prefix string) (string, error) {
-	maxSize := l.svcCtx.Config.Upload.MaxCharacterSize
-	if maxSize <= 0 {
-		maxSize = 5 * 1024 * 1024
-	}
-
-	if fileHeader.Size > maxSize {
-		return "", fmt.Errorf("图片大小不能超过%dMB", maxSize/1024/1024)
-	}
+	spec := imageUpload(l.svcCtx.Config)
+	spec.dir = uploadDir
 
-	contentType, err := detectFileType(fileHeader)
+	filename, _, err := spec.save(fileHeader, fmt.Sprintf("char_%s_%d", prefix, userId))
 	if err != nil {
-		return "", errors.New("读取文件失败")
-	}
-
-	extension, ok := allowedImageTypes[contentType]
-	if !ok {
-		return "", errors.New("仅支持 JPG、PNG、GIF、WEBP 格式")
-	}
-
-	randomSuffix, err := randomHex(8)
-	if err != nil {
-		return "", errors.New("生成文件名失败")
-	}
-
-	filename := fmt.Sprintf("char_%s_%d_%s.%s", prefix, userId, randomSuffix, extension)
-	filePath := filepath.Join(uploadDir, filename)
-
-	source, err := fileHeader.Open()
-	if err != nil {
-		return "", errors.New("读取文件失败")
-	}
-	defer source.Close()
-
-	target, err := os.Create(filePath)
-	if err != nil {
-		return "", errors.New("保存文件失败")
-	}
-	defer target.Close()
-
-	if _, err := io.Copy(target, source); err != nil {
-		return "", errors.New("保存文件失败")
+		return "", err
 	}
 
 	return fmt.Sprintf("/api/v1/uploads/characters/%s", filename), nil
@@ -0,0 +1,79 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateLorebookEntryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 更新世界设定条目
+func NewUpdateLorebookEntryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateLorebookEntryLogic {
+	return &UpdateLorebookEntryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateLorebookEntryLogic) UpdateLorebookEntry(req *types.UpdateLorebookEntryReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if msg := checkLorebookEntry(req.Keywords, req.Content, req.Constant); msg != "" {
+		return &types.DataResp{
+			Code:    400,
+			Message: msg,
+		}, nil
+	}
+
+	if _, code, msg := findOwnedCharacter(l.svcCtx.DB, req.Id, userId); code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var entry model.LorebookEntry
+	if err := l.svcCtx.DB.Where("id = ? AND character_id = ?", req.EntryId, req.Id).
+		First(&entry).Error; err != nil {
+		return &types.DataResp{
+			Code:    404,
+			Message: "世界设定条目不存在",
+		}, nil
+	}
+
+	if err := l.svcCtx.DB.Model(&entry).Updates(map[string]interface{}{
+		"keywords": joinKeywords(req.Keywords),
+		"content":  strings.TrimSpace(req.Content),
+		"constant": req.Constant,
+		"enabled":  req.Enabled,
+		"priority": req.Priority,
+	}).Error; err != nil {
+		return nil, errors.New("更新世界设定失败")
+	}
+
+	l.svcCtx.DB.First(&entry, entry.Id)
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "更新成功",
+		Data:    toLorebookEntryInfo(&entry),
+	}, nil
+}
@@ -0,0 +1,192 @@
+package character
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"aifriend/internal/config"
+)
+
+var allowedImageTypes = map[string]string{
+	"image/jpeg": "jpg",
+	"image/png":  "png",
+	"image/gif":  "gif",
+	"image/webp": "webp",
+}
+
+var allowedDocumentTypes = map[string]string{
+	"text/plain":      "txt",
+	"text/markdown":   "md",
+	"application/pdf": "pdf",
+}
+
+// uploadSpec 一类上传文件的保存目录和校验规则
+type uploadSpec struct {
+	dir      string
+	maxSize  int64
+	allowed  map[string]string // MIME 类型 -> 扩展名
+	label    string            // 提示中的文件类别
+	typeHint string            // 格式不支持时的提示
+}
+
+func imageUpload(c config.Config) uploadSpec {
+	spec := uploadSpec{
+		dir:      c.Upload.CharacterDir,
+		maxSize:  c.Upload.MaxCharacterSize,
+		allowed:  allowedImageTypes,
+		label:    "图片",
+		typeHint: "仅支持 JPG、PNG、GIF、WEBP 格式",
+	}
+	if spec.dir == "" {
+		spec.dir = "uploads/characters"
+	}
+	if spec.maxSize <= 0 {
+		spec.maxSize = 5 * 1024 * 1024
+	}
+	return spec
+}
+
+func documentUpload(c config.Config) uploadSpec {
+	spec := uploadSpec{
+		dir:      c.Upload.KnowledgeDir,
+		maxSize:  c.Upload.MaxDocumentSize,
+		allowed:  allowedDocumentTypes,
+		label:    "文档",
+		typeHint: "仅支持 TXT、Markdown、PDF 格式",
+	}
+	if spec.dir == "" {
+		spec.dir = "uploads/knowledge"
+	}
+	if spec.maxSize <= 0 {
+		spec.maxSize = 10 * 1024 * 1024
+	}
+	return spec
+}
+
+// save 校验文件大小和类型后以 "<name>_<随机串>.<扩展名>" 保存, 返回文件名和识别出的类型
+func (s uploadSpec) save(fileHeader *multipart.FileHeader, name string) (string, string, error) {
+	if fileHeader.Size > s.maxSize {
+		return "", "", fmt.Errorf("%s大小不能超过%dMB", s.label, s.maxSize/1024/1024)
+	}
+
+	contentType, err := detectFileType(fileHeader)
+	if err != nil {
+		return "", "", errors.New("读取文件失败")
+	}
+
+	extension, ok := s.allowed[contentType]
+	if !ok {
+		return "", "", errors.New(s.typeHint)
+	}
+
+	source, err := fileHeader.Open()
+	if err != nil {
+		return "", "", errors.New("读取文件失败")
+	}
+	defer source.Close()
+
+	filename, err := s.write(source, name, extension)
+	if err != nil {
+		return "", "", err
+	}
+	return filename, contentType, nil
+}
+
+// saveData 与 save 相同, 用于已读入内存的文件内容
+func (s uploadSpec) saveData(data []byte, name string) (string, string, error) {
+	if int64(len(data)) > s.maxSize {
+		return "", "", fmt.Errorf("%s大小不能超过%dMB", s.label, s.maxSize/1024/1024)
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(data))
+	if err != nil {
+		return "", "", errors.New("读取文件失败")
+	}
+
+	extension, ok := s.allowed[contentType]
+	if !ok {
+		return "", "", errors.New(s.typeHint)
+	}
+
+	filename, err := s.write(bytes.NewReader(data), name, extension)
+	if err != nil {
+		return "", "", err
+	}
+	return filename, contentType, nil
+}
+
+// copy 把保存目录中的已有文件复制为 "<name>_<随机串>.<原扩展名>", 返回新文件名
+func (s uploadSpec) copy(filename, name string) (string, error) {
+	source, err := os.Open(filepath.Join(s.dir, filepath.Base(filename)))
+	if err != nil {
+		return "", errors.New("读取文件失败")
+	}
+	defer source.Close()
+
+	return s.write(source, name, strings.TrimPrefix(filepath.Ext(filename), "."))
+}
+
+// write 把内容写入 "<name>_<随机串>.<扩展名>", 返回文件名
+func (s uploadSpec) write(source io.Reader, name, extension string) (string, error) {
+	randomSuffix, err := randomHex(8)
+	if err != nil {
+		return "", errors.New("生成文件名失败")
+	}
+
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return "", errors.New("创建上传目录失败")
+	}
+
+	filename := fmt.Sprintf("%s_%s.%s", name, randomSuffix, extension)
+	filePath := filepath.Join(s.dir, filename)
+
+	target, err := os.Create(filePath)
+	if err != nil {
+		return "", errors.New("保存文件失败")
+	}
+	defer target.Close()
+
+	if _, err := io.Copy(target, source); err != nil {
+		os.Remove(filePath)
+		return "", errors.New("保存文件失败")
+	}
+
+	return filename, nil
+}
+
+// detectFileType 根据文件内容识别 MIME 类型, 纯文本按扩展名区分 Markdown
+func detectFileType(fileHeader *multipart.FileHeader) (string, error) {
+	file, err := fileHeader.Open()
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	buffer := make([]byte, 512)
+	n, err := file.Read(buffer)
+	if err != nil && err != io.EOF {
+		return "", err
+	}
+
+	contentType, _, err := mime.ParseMediaType(http.DetectContentType(buffer[:n]))
+	if err != nil {
+		return "", err
+	}
+
+	if contentType == "text/plain" {
+		switch strings.ToLower(filepath.Ext(fileHeader.Filename)) {
+		case ".md", ".markdown":
+			contentType = "text/markdown"
+		}
+	}
+
+	return contentType, nil
+}
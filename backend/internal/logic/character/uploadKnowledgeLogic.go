@@ -0,0 +1,128 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package character
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"mime/multipart"
+	"os"
+	"path/filepath"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/knowledge"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UploadKnowledgeLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 上传知识库文档
+func NewUploadKnowledgeLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UploadKnowledgeLogic {
+	return &UploadKnowledgeLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UploadKnowledgeLogic) UploadKnowledge(characterId int64, fileHeader *multipart.FileHeader) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findOwnedCharacter(l.svcCtx.DB, characterId, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	if maxDocuments := l.svcCtx.Config.Knowledge.MaxDocuments; maxDocuments > 0 {
+		var count int64
+		if err := l.svcCtx.DB.Model(&model.KnowledgeDocument{}).
+			Where("character_id = ?", character.Id).Count(&count).Error; err != nil {
+			return nil, errors.New("查询知识库失败")
+		}
+		if count >= int64(maxDocuments) {
+			return &types.DataResp{
+				Code:    400,
+				Message: fmt.Sprintf("每个角色最多上传%d个文档", maxDocuments),
+			}, nil
+		}
+	}
+
+	spec := documentUpload(l.svcCtx.Config)
+	filename, contentType, err := spec.save(fileHeader, fmt.Sprintf("doc_%d_%d", character.Id, userId))
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: err.Error(),
+		}, nil
+	}
+	path := filepath.Join(spec.dir, filename)
+
+	text, err := knowledge.ExtractText(path, contentType)
+	if err != nil {
+		os.Remove(path)
+		return &types.DataResp{
+			Code:    400,
+			Message: extractErrorMessage(err),
+		}, nil
+	}
+
+	document := &model.KnowledgeDocument{
+		CharacterId: character.Id,
+		UserId:      character.UserId,
+		Filename:    truncateRunes(filepath.Base(fileHeader.Filename), 255),
+		Path:        filename,
+		ContentType: contentType,
+		Size:        fileHeader.Size,
+	}
+	if err := l.svcCtx.Knowledge.AddDocument(l.ctx, document, text); err != nil {
+		os.Remove(path)
+		if errors.Is(err, knowledge.ErrTooManyChunks) {
+			return &types.DataResp{
+				Code:    400,
+				Message: "文档内容过长, 请拆分后上传",
+			}, nil
+		}
+		l.Errorf("add knowledge document of character %d failed: %v", character.Id, err)
+		return nil, errors.New("处理文档失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "上传成功",
+		Data:    toKnowledgeDocumentInfo(document),
+	}, nil
+}
+
+func extractErrorMessage(err error) string {
+	switch {
+	case errors.Is(err, knowledge.ErrInvalidEncoding):
+		return "文本文档需使用 UTF-8 编码"
+	case errors.Is(err, knowledge.ErrEmptyDocument):
+		return "文档中没有可识别的文字"
+	default:
+		return "解析文档失败"
+	}
+}
+
+func truncateRunes(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) > n {
+		return string(runes[:n])
+	}
+	return s
+}
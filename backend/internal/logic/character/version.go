@@ -0,0 +1,162 @@
+package character
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/types"
+
+	"gorm.io/gorm"
+)
+
+// maxCharacterVersions 每个角色保留的历史版本数, 更早的版本及其不再被引用的图片会被清理
+const maxCharacterVersions = 50
+
+// saveVersion 把角色修改前的状态保存为新版本, 返回因清理旧版本而不再被引用的图片,
+// 调用方应在事务提交后删除这些文件. keep 为角色修改后将使用的图片, 如回滚的目标版本的图片,
+// 即使所在的版本被清理也不会删除
+func saveVersion(tx *gorm.DB, character *model.Character, keep ...string) ([]string, error) {
+	var latest int
+	if err := tx.Model(&model.CharacterVersion{}).
+		Where("character_id = ?", character.Id).
+		Select("COALESCE(MAX(version), 0)").
+		Scan(&latest).Error; err != nil {
+		return nil, err
+	}
+
+	version := &model.CharacterVersion{
+		CharacterId: character.Id,
+		Version:     latest + 1,
+		Snapshot:    character.Snapshot(),
+	}
+	if err := tx.Create(version).Error; err != nil {
+		return nil, err
+	}
+
+	var expired []model.CharacterVersion
+	if err := tx.Where("character_id = ? AND version <= ?", character.Id, version.Version-maxCharacterVersions).
+		Find(&expired).Error; err != nil {
+		return nil, err
+	}
+	if len(expired) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]int64, len(expired))
+	for i := range expired {
+		ids[i] = expired[i].Id
+	}
+	if err := tx.Delete(&model.CharacterVersion{}, ids).Error; err != nil {
+		return nil, err
+	}
+
+	// 仍被当前角色、修改后的角色或保留的版本引用的图片不能删除
+	var kept []model.CharacterVersion
+	if err := tx.Where("character_id = ?", character.Id).Find(&kept).Error; err != nil {
+		return nil, err
+	}
+	inUse := map[string]bool{character.Photo: true, character.BackgroundImage: true}
+	for _, image := range keep {
+		inUse[image] = true
+	}
+	for i := range kept {
+		inUse[kept[i].Snapshot.Photo] = true
+		inUse[kept[i].Snapshot.BackgroundImage] = true
+	}
+
+	var unused []string
+	for i := range expired {
+		for _, image := range []string{expired[i].Snapshot.Photo, expired[i].Snapshot.BackgroundImage} {
+			if image != "" && !inUse[image] {
+				unused = append(unused, image)
+				inUse[image] = true
+			}
+		}
+	}
+	return unused, nil
+}
+
+// removeImages 删除角色上传目录中的图片文件
+func removeImages(uploadDir string, images []string) {
+	for _, image := range images {
+		if image != "" {
+			os.Remove(filepath.Join(uploadDir, filepath.Base(image)))
+		}
+	}
+}
+
+// snapshotColumns 返回把角色恢复为快照所需更新的列, 标签需要另外替换
+func snapshotColumns(s *model.CharacterSnapshot) map[string]interface{} {
+	columns := map[string]interface{}{
+		"name":                s.Name,
+		"photo":               s.Photo,
+		"profile":             s.Profile,
+		"background_image":    s.BackgroundImage,
+		"scenario":            s.Scenario,
+		"greeting":            s.Greeting,
+		"alternate_greetings": model.StringList(s.AlternateGreetings),
+		"personality":         s.Personality,
+		"example_dialogues":   s.ExampleDialogues,
+		"speaking_style":      s.SpeakingStyle,
+		"creator_notes":       s.CreatorNotes,
+		"prompt_template":     s.PromptTemplate,
+		"visibility":          s.Visibility,
+		"category":            s.Category,
+	}
+	for column, value := range generationColumns(&s.Generation) {
+		columns[column] = value
+	}
+	return columns
+}
+
+// diffSnapshots 逐字段比较两个快照, 返回有变化的字段
+func diffSnapshots(from, to *model.CharacterSnapshot) []types.FieldChange {
+	changes := make([]types.FieldChange, 0)
+	fromValue, toValue := reflect.ValueOf(*from), reflect.ValueOf(*to)
+	for i := 0; i < fromValue.NumField(); i++ {
+		a := snapshotValue(fromValue.Field(i).Interface())
+		b := snapshotValue(toValue.Field(i).Interface())
+		if reflect.DeepEqual(a, b) {
+			continue
+		}
+		changes = append(changes, types.FieldChange{
+			Field: strings.Split(fromValue.Type().Field(i).Tag.Get("json"), ",")[0],
+			From:  a,
+			To:    b,
+		})
+	}
+	return changes
+}
+
+// snapshotValue 转换为接口中的表示, 空列表与 nil 视为相同
+func snapshotValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case model.GenerationSettings:
+		return toGenerationSettings(&v)
+	case []string:
+		return append([]string{}, v...)
+	}
+	return v
+}
+
+func toCharacterVersionInfo(v *model.CharacterVersion) types.CharacterVersionInfo {
+	return types.CharacterVersionInfo{
+		Id:        v.Id,
+		Version:   v.Version,
+		Name:      v.Snapshot.Name,
+		Photo:     v.Snapshot.Photo,
+		CreatedAt: v.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+// findVersion 查询角色的指定版本
+func findVersion(db *gorm.DB, characterId int64, version int) (*model.CharacterVersion, int, string) {
+	var v model.CharacterVersion
+	if err := db.Where("character_id = ? AND version = ?", characterId, version).First(&v).Error; err != nil {
+		return nil, 404, "版本不存在"
+	}
+	return &v, 0, ""
+}
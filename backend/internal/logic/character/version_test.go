@@ -0,0 +1,92 @@
+package character
+
+import (
+	"path/filepath"
+	"slices"
+	"testing"
+
+	"aifriend/internal/model"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+func TestSaveVersion(t *testing.T) {
+	const characterId = 1
+
+	tests := []struct {
+		name     string
+		versions int                             // 已有的版本数, 版本 1 使用 old.png 和 bg.png
+		prepare  func(v *model.CharacterVersion) // 修改已有的版本
+		keep     []string
+		want     []string // 返回的不再被引用的图片
+	}{
+		{name: "未达到上限", versions: 10},
+		{name: "清理最早的版本", versions: maxCharacterVersions, want: []string{"old.png", "bg.png"}},
+		{
+			name:     "回滚到最早的版本",
+			versions: maxCharacterVersions,
+			keep:     []string{"old.png", "bg.png"},
+		},
+		{
+			name:     "图片仍被保留的版本引用",
+			versions: maxCharacterVersions,
+			prepare: func(v *model.CharacterVersion) {
+				if v.Version == 2 {
+					v.Snapshot.BackgroundImage = "bg.png"
+				}
+			},
+			want: []string{"old.png"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+				Logger: logger.Discard,
+			})
+			if err != nil {
+				t.Fatalf("open db: %v", err)
+			}
+			if err := db.AutoMigrate(&model.CharacterVersion{}); err != nil {
+				t.Fatalf("migrate: %v", err)
+			}
+			for i := 1; i <= tt.versions; i++ {
+				v := model.CharacterVersion{
+					CharacterId: characterId,
+					Version:     i,
+					Snapshot:    model.CharacterSnapshot{Name: "v", Photo: "mid.png"},
+				}
+				if i == 1 {
+					v.Snapshot.Photo, v.Snapshot.BackgroundImage = "old.png", "bg.png"
+				}
+				if tt.prepare != nil {
+					tt.prepare(&v)
+				}
+				if err := db.Create(&v).Error; err != nil {
+					t.Fatalf("create version: %v", err)
+				}
+			}
+
+			character := &model.Character{Id: characterId, Name: "当前", Photo: "current.png"}
+			var unused []string
+			err = db.Transaction(func(tx *gorm.DB) error {
+				unused, err = saveVersion(tx, character, tt.keep...)
+				return err
+			})
+			if err != nil {
+				t.Fatalf("saveVersion() error = %v", err)
+			}
+			if !slices.Equal(unused, tt.want) {
+				t.Errorf("unused = %v, want %v", unused, tt.want)
+			}
+
+			var count int64
+			db.Model(&model.CharacterVersion{}).Where("character_id = ?", characterId).Count(&count)
+			if want := int64(min(tt.versions+1, maxCharacterVersions)); count != want {
+				t.Errorf("%d versions kept, want %d", count, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,50 @@
+package conversation
+
+import (
+	"aifriend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// findVisibleCharacter 查询角色并校验可见性: 私有角色仅所有者可访问, userId 为 0 表示未登录
+func findVisibleCharacter(db *gorm.DB, characterId, userId int64) (*model.Character, int, string) {
+	var character model.Character
+	if err := db.First(&character, characterId).Error; err != nil {
+		return nil, 404, "角色不存在"
+	}
+
+	if !character.VisibleTo(userId) {
+		return nil, 403, "无权访问此角色"
+	}
+
+	return &character, 0, ""
+}
+
+// findOwnedConversation 查询角色下的会话并校验所有权, 校验失败时返回对应的状态码和提示
+func findOwnedConversation(db *gorm.DB, characterId, conversationId, userId int64) (*model.Conversation, int, string) {
+	conversation, code, msg := findConversation(db, conversationId, userId)
+	if code != 0 {
+		return nil, code, msg
+	}
+
+	if conversation.CharacterId != characterId {
+		return nil, 404, "会话不存在"
+	}
+
+	return conversation, 0, ""
+}
+
+// findConversation 按ID查询会话并校验所有权, 校验失败时返回对应的状态码和提示
+func findConversation(db *gorm.DB, conversationId, userId int64) (*model.Conversation, int, string) {
+	var conversation model.Conversation
+	if err := db.First(&conversation, conversationId).Error; err != nil {
+		return nil, 404, "会话不存在"
+	}
+
+	// 验证所有权
+	if conversation.UserId != userId {
+		return nil, 403, "无权访问此会话"
+	}
+
+	return &conversation, 0, ""
+}
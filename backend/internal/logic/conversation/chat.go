@@ -0,0 +1,320 @@
+package conversation
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/pkg/prompt"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+	"gorm.io/gorm"
+)
+
+const maxMessageLength = 4000
+
+// chatError 对话流程中的业务错误, Code 为返回给客户端的状态码
+type chatError struct {
+	Code    int
+	Message string
+}
+
+func (e *chatError) Error() string {
+	return e.Message
+}
+
+// chatTurn 一轮对话: 已保存的用户消息以及发送给模型的请求
+type chatTurn struct {
+	conversation *model.Conversation
+	character    *model.Character
+	userMessage  *model.Message
+	params       llm.Params
+	request      *llm.ChatRequest
+}
+
+// prepareChatTurn 在当前分支末尾保存用户消息并组装模型请求
+func prepareChatTurn(ctx context.Context, svcCtx *svc.ServiceContext, userId, conversationId int64, content string, override *types.GenerationSettings) (*chatTurn, error) {
+	content, err := checkMessageContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	turn, err := startTurn(svcCtx, userId, conversationId, override)
+	if err != nil {
+		return nil, err
+	}
+
+	parentId, err := activeMessageId(svcCtx.DB, turn.conversation)
+	if err != nil {
+		return nil, errors.New("加载会话历史失败")
+	}
+
+	if err := turn.addUserMessage(svcCtx, parentId, content); err != nil {
+		return nil, err
+	}
+	return turn, turn.build(ctx, svcCtx)
+}
+
+// prepareEditTurn 编辑用户消息: 在原消息旁新建一条分支重新对话, 原消息及其后续回复保留
+func prepareEditTurn(ctx context.Context, svcCtx *svc.ServiceContext, userId, conversationId, messageId int64, content string, override *types.GenerationSettings) (*chatTurn, error) {
+	content, err := checkMessageContent(content)
+	if err != nil {
+		return nil, err
+	}
+
+	turn, err := startTurn(svcCtx, userId, conversationId, override)
+	if err != nil {
+		return nil, err
+	}
+
+	// 先把早期会话串成分支, 保证原消息的父消息正确
+	if _, err := activeMessageId(svcCtx.DB, turn.conversation); err != nil {
+		return nil, errors.New("加载会话历史失败")
+	}
+
+	original, code, msg := findMessage(svcCtx.DB, conversationId, messageId)
+	if code != 0 {
+		return nil, &chatError{Code: code, Message: msg}
+	}
+	if original.Role != model.MessageRoleUser {
+		return nil, &chatError{Code: 400, Message: "只能编辑用户消息"}
+	}
+
+	if err := turn.addUserMessage(svcCtx, original.ParentId, content); err != nil {
+		return nil, err
+	}
+	return turn, turn.build(ctx, svcCtx)
+}
+
+// prepareRegenerateTurn 重新生成回复: 指定助手消息时新回复与其互为备选,
+// 指定用户消息时为它生成一条新回复. 已有的回复都会保留
+func prepareRegenerateTurn(ctx context.Context, svcCtx *svc.ServiceContext, userId, conversationId, messageId int64, override *types.GenerationSettings) (*chatTurn, error) {
+	turn, err := startTurn(svcCtx, userId, conversationId, override)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, err := activeMessageId(svcCtx.DB, turn.conversation); err != nil {
+		return nil, errors.New("加载会话历史失败")
+	}
+
+	message, code, msg := findMessage(svcCtx.DB, conversationId, messageId)
+	if code != 0 {
+		return nil, &chatError{Code: code, Message: msg}
+	}
+
+	switch message.Role {
+	case model.MessageRoleUser:
+		turn.userMessage = message
+	case model.MessageRoleAssistant:
+		parent, code, _ := findMessage(svcCtx.DB, conversationId, message.ParentId)
+		if code != 0 || parent.Role != model.MessageRoleUser {
+			return nil, &chatError{Code: 400, Message: "无法重新生成该消息"}
+		}
+		turn.userMessage = parent
+	default:
+		return nil, &chatError{Code: 400, Message: "无法重新生成该消息"}
+	}
+
+	return turn, turn.build(ctx, svcCtx)
+}
+
+func checkMessageContent(content string) (string, error) {
+	content = strings.TrimSpace(content)
+	if content == "" {
+		return "", &chatError{Code: 400, Message: "消息内容不能为空"}
+	}
+	if len([]rune(content)) > maxMessageLength {
+		return "", &chatError{Code: 400, Message: fmt.Sprintf("消息最多%d字", maxMessageLength)}
+	}
+	return content, nil
+}
+
+// startTurn 校验会话归属, 加载角色并确定本轮的生成参数
+func startTurn(svcCtx *svc.ServiceContext, userId, conversationId int64, override *types.GenerationSettings) (*chatTurn, error) {
+	conversation, code, msg := findConversation(svcCtx.DB, conversationId, userId)
+	if code != 0 {
+		return nil, &chatError{Code: code, Message: msg}
+	}
+
+	// 角色被所有者设为私有后, 其他用户不能继续对话
+	character, code, msg := findVisibleCharacter(svcCtx.DB, conversation.CharacterId, userId)
+	if code != 0 {
+		return nil, &chatError{Code: code, Message: msg}
+	}
+
+	params, err := generationParams(svcCtx.Config.LLM, character, override)
+	if err != nil {
+		return nil, err
+	}
+
+	return &chatTurn{
+		conversation: conversation,
+		character:    character,
+		params:       params,
+	}, nil
+}
+
+// addUserMessage 在 parentId 之后保存用户消息, 并切换到这条新分支
+func (t *chatTurn) addUserMessage(svcCtx *svc.ServiceContext, parentId int64, content string) error {
+	message := &model.Message{
+		ConversationId: t.conversation.Id,
+		ParentId:       parentId,
+		Role:           model.MessageRoleUser,
+		Content:        content,
+	}
+	if err := saveMessage(svcCtx, t.conversation, message); err != nil {
+		return errors.New("保存消息失败")
+	}
+
+	t.userMessage = message
+	return nil
+}
+
+// build 以用户消息所在分支为历史组装模型请求
+func (t *chatTurn) build(ctx context.Context, svcCtx *svc.ServiceContext) error {
+	request, err := buildChatRequest(ctx, svcCtx, t.character, t.conversation, t.userMessage.Id, t.params)
+	if err != nil {
+		return errors.New("加载会话历史失败")
+	}
+
+	t.request = request
+	return nil
+}
+
+// streamReply 流式生成助手回复, 生成结束、出错或客户端断开时保存已生成的内容.
+// ctx 取消时会中止上游请求.
+func (t *chatTurn) streamReply(ctx context.Context, svcCtx *svc.ServiceContext, fn llm.StreamFunc) (*model.Message, error) {
+	result, streamErr := svcCtx.LLM.ChatStream(ctx, t.request, fn)
+	if result == nil || result.Content == "" {
+		return nil, streamErr
+	}
+
+	// 请求可能已被取消, 使用不带请求上下文的连接保存
+	reply := &model.Message{
+		ConversationId: t.conversation.Id,
+		ParentId:       t.userMessage.Id,
+		Role:           model.MessageRoleAssistant,
+		Content:        result.Content,
+	}
+	if err := saveMessage(svcCtx, t.conversation, reply); err != nil {
+		return nil, errors.Join(streamErr, err)
+	}
+
+	if streamErr == nil {
+		t.extractMemories(svcCtx, reply)
+	}
+
+	return reply, streamErr
+}
+
+// extractMemories 在后台从本轮对话中提取长期记忆, 不阻塞回复
+func (t *chatTurn) extractMemories(svcCtx *svc.ServiceContext, reply *model.Message) {
+	if !svcCtx.Config.Memory.Enabled {
+		return
+	}
+
+	messages := []model.Message{*t.userMessage, *reply}
+	threading.GoSafe(func() {
+		ctx, cancel := context.WithTimeout(context.Background(), time.Duration(svcCtx.Config.LLM.Timeout)*time.Second)
+		defer cancel()
+
+		if _, err := svcCtx.Memory.Extract(ctx, t.conversation.UserId, t.character.Id, messages); err != nil {
+			logx.Errorf("extract memories of conversation %d failed: %v", t.conversation.Id, err)
+		}
+	})
+}
+
+// buildChatRequest 根据角色设定、检索到的上下文和以 leafId 结尾的分支历史组装模型请求,
+// 历史超出上下文预算时按配置的策略截断
+func buildChatRequest(ctx context.Context, svcCtx *svc.ServiceContext, character *model.Character, conversation *model.Conversation, leafId int64, params llm.Params) (*llm.ChatRequest, error) {
+	var user model.User
+	if err := svcCtx.DB.First(&user, conversation.UserId).Error; err != nil {
+		return nil, err
+	}
+
+	tree, err := loadTree(svcCtx.DB, conversation.Id)
+	if err != nil {
+		return nil, err
+	}
+	history, err := loadBranch(svcCtx.DB, tree, leafId)
+	if err != nil {
+		return nil, err
+	}
+
+	// 已纳入摘要的消息不再加载. 摘要只概括生成它时所在的分支, 当前分支不经过摘要的
+	// 最后一条消息时不使用该摘要
+	window := &contextWindow{svcCtx: svcCtx, conversation: conversation, replyTokens: params.MaxTokens}
+	if svcCtx.Config.Chat.TruncateStrategy == strategySummary && conversation.Summary != "" {
+		if i := slices.IndexFunc(history, func(m model.Message) bool { return m.Id == conversation.SummaryUntil }); i >= 0 {
+			history = history[i+1:]
+		} else {
+			detached := *conversation
+			detached.Summary = ""
+			window.conversation = &detached
+		}
+	}
+
+	data := prompt.NewData(character, &user)
+	recall(ctx, svcCtx, &data, character, conversation.UserId, history)
+
+	system, err := prompt.BuildSystemPrompt(character.PromptTemplate, data)
+	if err != nil {
+		if system == "" {
+			return nil, err
+		}
+		logx.WithContext(ctx).Errorf("render prompt template of character %d failed: %v", character.Id, err)
+	}
+
+	history, summary := window.fit(ctx, system, history)
+
+	messages := make([]llm.Message, 0, len(history)+2)
+	messages = append(messages, llm.Message{
+		Role:    llm.RoleSystem,
+		Content: system,
+	})
+	if summary != "" {
+		messages = append(messages, llm.Message{
+			Role:    llm.RoleSystem,
+			Content: "[之前的对话摘要]\n" + summary,
+		})
+	}
+	for _, m := range history {
+		messages = append(messages, llm.Message{
+			Role:    m.Role,
+			Content: m.Content,
+		})
+	}
+
+	request := &llm.ChatRequest{
+		Model:     svcCtx.Config.LLM.Model,
+		Messages:  messages,
+		MaxTokens: svcCtx.Config.Chat.ReplyTokens,
+	}
+	params.Apply(request)
+
+	return request, nil
+}
+
+// saveMessage 计算消息的 token 数后保存, 并把会话的当前分支移到这条消息
+func saveMessage(svcCtx *svc.ServiceContext, conversation *model.Conversation, message *model.Message) error {
+	message.TokenCount = svcCtx.Tokenizer.Count(message.Content)
+
+	return svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(message).Error; err != nil {
+			return err
+		}
+		return tx.Model(conversation).Updates(map[string]interface{}{
+			"active_message_id": message.Id,
+			"updated_at":        time.Now(),
+		}).Error
+	})
+}
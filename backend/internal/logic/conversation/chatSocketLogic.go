@@ -0,0 +1,251 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"aifriend/internal/pkg/jwt"
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/pkg/ws"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+)
+
+// WebSocket 客户端消息类型
+const (
+	socketSend       = "send"
+	socketRegenerate = "regenerate" // 重新生成 message_id 对应的回复
+	socketEdit       = "edit"       // 编辑 message_id 对应的用户消息
+	socketCancel     = "cancel"
+	socketTyping     = "typing"
+)
+
+// WebSocket 服务端推送类型, 其余复用流式事件类型
+const (
+	eventAck    = "ack"
+	eventTyping = "typing"
+)
+
+// 单个连接同时进行中的生成数量上限
+const maxSocketGenerations = 4
+
+// sessionCheckInterval 连接建立后重新检查登录会话的间隔. 开始生成前也会检查
+const sessionCheckInterval = 30 * time.Second
+
+type ChatSocketLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+	claims *jwt.Claims
+	userId int64
+	conn   *ws.Conn
+
+	mu          sync.Mutex
+	generations map[int64]context.CancelFunc // 会话ID -> 取消生成
+	wg          sync.WaitGroup
+}
+
+// WebSocket 实时对话
+func NewChatSocketLogic(ctx context.Context, svcCtx *svc.ServiceContext, claims *jwt.Claims, conn *ws.Conn) *ChatSocketLogic {
+	return &ChatSocketLogic{
+		Logger:      logx.WithContext(ctx),
+		ctx:         ctx,
+		svcCtx:      svcCtx,
+		claims:      claims,
+		userId:      claims.UserId,
+		conn:        conn,
+		generations: make(map[int64]context.CancelFunc),
+	}
+}
+
+// ChatSocket 处理连接上的消息直到断开. 一个连接可同时承载多个会话,
+// 断开时取消所有进行中的生成, 已生成的内容会被保存.
+// 登录会话被吊销或令牌版本变化后以 1008 关闭连接.
+func (l *ChatSocketLogic) ChatSocket() {
+	ctx, cancel := context.WithCancel(l.ctx)
+	defer func() {
+		cancel()
+		l.wg.Wait()
+	}()
+
+	threading.GoSafeCtx(ctx, func() {
+		ticker := time.NewTicker(sessionCheckInterval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ticker.C:
+				l.checkSession()
+			case <-ctx.Done():
+				return
+			}
+		}
+	})
+
+	l.conn.ReadLoop(func(data []byte) {
+		var req types.ChatSocketReq
+		if err := json.Unmarshal(data, &req); err != nil {
+			l.push(&types.ChatSocketEvent{Type: eventError, Code: 400, Error: "消息格式错误"})
+			return
+		}
+
+		switch req.Type {
+		case socketSend, socketRegenerate, socketEdit:
+			if l.checkSession() {
+				l.startGeneration(ctx, &req)
+			}
+		case socketCancel:
+			l.cancelGeneration(&req)
+		case socketTyping:
+			// 用户输入状态仅用于保持连接活跃, 无需处理
+		default:
+			l.push(&types.ChatSocketEvent{
+				Type:      eventError,
+				RequestId: req.RequestId,
+				Code:      400,
+				Error:     "不支持的消息类型",
+			})
+		}
+	})
+}
+
+// checkSession 确认登录会话仍然有效, 失效时关闭连接. 查询失败时不断开连接
+func (l *ChatSocketLogic) checkSession() bool {
+	err := session.Check(l.svcCtx.DB, l.claims.UserId, l.claims.TokenVersion, l.claims.ID)
+	if errors.Is(err, session.ErrRevoked) {
+		l.conn.CloseWith(ws.ClosePolicyViolation, "session revoked")
+		return false
+	}
+	if err != nil {
+		l.Errorf("检查登录会话失败: %v", err)
+	}
+	return true
+}
+
+func (l *ChatSocketLogic) startGeneration(ctx context.Context, req *types.ChatSocketReq) {
+	l.mu.Lock()
+	if _, ok := l.generations[req.ConversationId]; ok {
+		l.mu.Unlock()
+		l.pushError(req, &chatError{Code: 409, Message: "该会话正在生成回复"})
+		return
+	}
+	if len(l.generations) >= maxSocketGenerations {
+		l.mu.Unlock()
+		l.pushError(req, &chatError{Code: 429, Message: "同时进行的对话过多"})
+		return
+	}
+	genCtx, cancel := context.WithCancel(ctx)
+	l.generations[req.ConversationId] = cancel
+	l.mu.Unlock()
+
+	l.wg.Add(1)
+	threading.GoSafeCtx(genCtx, func() {
+		defer func() {
+			l.mu.Lock()
+			delete(l.generations, req.ConversationId)
+			l.mu.Unlock()
+			cancel()
+			l.wg.Done()
+		}()
+
+		l.generate(genCtx, req)
+	})
+}
+
+func (l *ChatSocketLogic) generate(ctx context.Context, req *types.ChatSocketReq) {
+	turn, err := l.prepare(ctx, req)
+	if err != nil {
+		l.pushError(req, err)
+		return
+	}
+
+	userMessage, _ := turn.describe(ctx, l.svcCtx, nil)
+	l.push(&types.ChatSocketEvent{
+		Type:           eventAck,
+		RequestId:      req.RequestId,
+		ConversationId: req.ConversationId,
+		UserMessage:    userMessage,
+	})
+	l.pushTyping(req, true)
+
+	reply, err := turn.streamReply(ctx, l.svcCtx, func(delta string) error {
+		return l.push(&types.ChatSocketEvent{
+			Type:           eventDelta,
+			RequestId:      req.RequestId,
+			ConversationId: req.ConversationId,
+			Content:        delta,
+		})
+	})
+
+	l.pushTyping(req, false)
+
+	cancelled := ctx.Err() != nil
+	if err != nil && !cancelled {
+		l.Errorf("stream reply failed: %v", err)
+		l.pushError(req, errors.New("生成回复失败"))
+		return
+	}
+
+	_, message := turn.describe(ctx, l.svcCtx, reply)
+	l.push(&types.ChatSocketEvent{
+		Type:           eventDone,
+		RequestId:      req.RequestId,
+		ConversationId: req.ConversationId,
+		Cancelled:      cancelled,
+		Message:        message,
+	})
+}
+
+// prepare 按消息类型准备一轮对话
+func (l *ChatSocketLogic) prepare(ctx context.Context, req *types.ChatSocketReq) (*chatTurn, error) {
+	switch req.Type {
+	case socketRegenerate:
+		return prepareRegenerateTurn(ctx, l.svcCtx, l.userId, req.ConversationId, req.MessageId, req.Generation)
+	case socketEdit:
+		return prepareEditTurn(ctx, l.svcCtx, l.userId, req.ConversationId, req.MessageId, req.Content, req.Generation)
+	default:
+		return prepareChatTurn(ctx, l.svcCtx, l.userId, req.ConversationId, req.Content, req.Generation)
+	}
+}
+
+func (l *ChatSocketLogic) cancelGeneration(req *types.ChatSocketReq) {
+	l.mu.Lock()
+	cancel, ok := l.generations[req.ConversationId]
+	l.mu.Unlock()
+
+	if ok {
+		cancel()
+	}
+}
+
+func (l *ChatSocketLogic) push(event *types.ChatSocketEvent) error {
+	return l.conn.SendJSON(event)
+}
+
+func (l *ChatSocketLogic) pushTyping(req *types.ChatSocketReq, typing bool) {
+	l.push(&types.ChatSocketEvent{
+		Type:           eventTyping,
+		RequestId:      req.RequestId,
+		ConversationId: req.ConversationId,
+		Typing:         typing,
+	})
+}
+
+func (l *ChatSocketLogic) pushError(req *types.ChatSocketReq, err error) {
+	event := errorEvent(err)
+	l.push(&types.ChatSocketEvent{
+		Type:           eventError,
+		RequestId:      req.RequestId,
+		ConversationId: req.ConversationId,
+		Code:           event.Code,
+		Error:          event.Error,
+	})
+}
@@ -0,0 +1,137 @@
+package conversation
+
+import (
+	"context"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/pkg/tokenizer"
+	"aifriend/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 上下文截断策略
+const (
+	strategyLatestTurns = "latest_turns" // 保留系统提示词和最近 N 轮
+	strategyDropOldest  = "drop_oldest"  // 从最早的消息开始丢弃
+	strategySummary     = "summary"      // 将较早的消息压缩为滚动摘要
+)
+
+const summaryPrompt = `请把下面的对话内容压缩成一段简洁的摘要，供之后继续对话时参考。
+要求：保留重要的事实、人物关系、约定和情节进展，使用第三人称，不超过300字，只输出摘要本身。`
+
+// contextWindow 按 token 预算裁剪会话历史
+type contextWindow struct {
+	svcCtx       *svc.ServiceContext
+	conversation *model.Conversation
+	replyTokens  int // 为回复预留的 token, 为 0 时使用全局配置
+}
+
+// fit 返回放得下预算的历史消息和需要注入的摘要.
+// 最新的一条消息总会保留, 即使它本身已超出预算.
+func (w *contextWindow) fit(ctx context.Context, system string, history []model.Message) ([]model.Message, string) {
+	c := w.svcCtx.Config.Chat
+	reply := c.ReplyTokens
+	if w.replyTokens > 0 {
+		reply = w.replyTokens
+	}
+	budget := c.ContextTokens - reply - w.count(system) - tokenizer.MessageOverhead
+
+	switch c.TruncateStrategy {
+	case strategyLatestTurns:
+		if n := c.LatestTurns * 2; n > 0 && len(history) > n {
+			history = history[len(history)-n:]
+		}
+		return history[w.fitFrom(history, budget):], ""
+	case strategySummary:
+		return w.summarize(ctx, history, budget)
+	default:
+		return history[w.fitFrom(history, budget):], ""
+	}
+}
+
+// summarize 历史超出预算时, 把放不下的较早消息并入滚动摘要
+func (w *contextWindow) summarize(ctx context.Context, history []model.Message, budget int) ([]model.Message, string) {
+	summary := w.conversation.Summary
+	if summary != "" {
+		budget -= w.count(summary) + tokenizer.MessageOverhead
+	}
+
+	start := w.fitFrom(history, budget)
+	if start == 0 {
+		return history, summary
+	}
+
+	// 只保留半个预算的最近消息, 留出余量避免之后每轮都重新生成摘要
+	keep := w.fitFrom(history, budget/2)
+	next, err := w.generateSummary(ctx, summary, history[:keep])
+	if err != nil {
+		logx.WithContext(ctx).Errorf("summarize conversation %d failed: %v", w.conversation.Id, err)
+		return history[start:], summary
+	}
+
+	until := history[keep-1].Id
+	if err := w.svcCtx.DB.Model(w.conversation).Updates(map[string]interface{}{
+		"summary":       next,
+		"summary_until": until,
+	}).Error; err != nil {
+		logx.WithContext(ctx).Errorf("save summary of conversation %d failed: %v", w.conversation.Id, err)
+	}
+
+	return history[keep:], next
+}
+
+func (w *contextWindow) generateSummary(ctx context.Context, previous string, messages []model.Message) (string, error) {
+	var sb strings.Builder
+	if previous != "" {
+		sb.WriteString("[已有摘要]\n")
+		sb.WriteString(previous)
+		sb.WriteString("\n\n")
+	}
+	sb.WriteString("[新的对话]\n")
+	for _, m := range messages {
+		sb.WriteString(m.Role)
+		sb.WriteString(": ")
+		sb.WriteString(m.Content)
+		sb.WriteString("\n")
+	}
+
+	resp, err := w.svcCtx.LLM.Chat(ctx, &llm.ChatRequest{
+		Model: w.svcCtx.Config.LLM.Model,
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: summaryPrompt},
+			{Role: llm.RoleUser, Content: sb.String()},
+		},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(resp.Content), nil
+}
+
+// fitFrom 从最新的消息向前累加, 返回放得下预算的最早下标
+func (w *contextWindow) fitFrom(history []model.Message, budget int) int {
+	used := 0
+	for i := len(history) - 1; i >= 0; i-- {
+		used += w.messageTokens(&history[i])
+		if used > budget && i < len(history)-1 {
+			return i + 1
+		}
+	}
+	return 0
+}
+
+func (w *contextWindow) messageTokens(m *model.Message) int {
+	// 兼容未记录 token 数的历史消息
+	if m.TokenCount == 0 && m.Content != "" {
+		m.TokenCount = w.count(m.Content)
+	}
+	return m.TokenCount + tokenizer.MessageOverhead
+}
+
+func (w *contextWindow) count(text string) int {
+	return w.svcCtx.Tokenizer.Count(text)
+}
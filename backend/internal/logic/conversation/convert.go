@@ -0,0 +1,28 @@
+package conversation
+
+import (
+	"aifriend/internal/model"
+	"aifriend/internal/types"
+)
+
+func toConversationInfo(c *model.Conversation) types.ConversationInfo {
+	return types.ConversationInfo{
+		Id:              c.Id,
+		CharacterId:     c.CharacterId,
+		Title:           c.Title,
+		ActiveMessageId: c.ActiveMessageId,
+		CreatedAt:       c.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:       c.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
+
+func toMessageInfo(m *model.Message) types.MessageInfo {
+	return types.MessageInfo{
+		Id:             m.Id,
+		ConversationId: m.ConversationId,
+		ParentId:       m.ParentId,
+		Role:           m.Role,
+		Content:        m.Content,
+		CreatedAt:      m.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,122 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"errors"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/prompt"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type CreateConversationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 创建会话
+func NewCreateConversationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *CreateConversationLogic {
+	return &CreateConversationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *CreateConversationLogic) CreateConversation(req *types.CreateConversationReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	character, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		title = character.Name
+	}
+	if len([]rune(title)) > 100 {
+		return &types.DataResp{
+			Code:    400,
+			Message: "会话标题最多100字",
+		}, nil
+	}
+
+	var user model.User
+	if err := l.svcCtx.DB.First(&user, userId).Error; err != nil {
+		return nil, errors.New("查询用户失败")
+	}
+
+	conversation := &model.Conversation{
+		UserId:      userId,
+		CharacterId: character.Id,
+		Title:       title,
+	}
+
+	// 会话以角色的开场白开始, 备选开场白作为同一位置的其他分支供用户切换
+	greetings := greetingMessages(l.svcCtx, character, &user)
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(conversation).Error; err != nil {
+			return err
+		}
+		if err := tx.Model(character).UpdateColumn("chat_count", gorm.Expr("chat_count + 1")).Error; err != nil {
+			return err
+		}
+		if len(greetings) == 0 {
+			return nil
+		}
+
+		for i := range greetings {
+			greetings[i].ConversationId = conversation.Id
+		}
+		if err := tx.Create(&greetings).Error; err != nil {
+			return err
+		}
+		conversation.ActiveMessageId = greetings[0].Id
+		return tx.Model(conversation).UpdateColumn("active_message_id", conversation.ActiveMessageId).Error
+	})
+	if err != nil {
+		return nil, errors.New("创建会话失败")
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "创建成功",
+		Data:    toConversationInfo(conversation),
+	}, nil
+}
+
+// greetingMessages 由角色的开场白和备选开场白生成会话的第一条消息, 展开其中的 {{char}}/{{user}} 宏
+func greetingMessages(svcCtx *svc.ServiceContext, character *model.Character, user *model.User) []model.Message {
+	data := prompt.NewData(character, user)
+
+	texts := append([]string{character.Greeting}, character.AlternateGreetings...)
+	messages := make([]model.Message, 0, len(texts))
+	for _, text := range texts {
+		text = strings.TrimSpace(data.Expand(text))
+		if text == "" {
+			continue
+		}
+		messages = append(messages, model.Message{
+			Role:       model.MessageRoleAssistant,
+			Content:    text,
+			TokenCount: svcCtx.Tokenizer.Count(text),
+		})
+	}
+	return messages
+}
@@ -0,0 +1,34 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type EditMessageStreamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 编辑消息并流式返回新的回复
+func NewEditMessageStreamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *EditMessageStreamLogic {
+	return &EditMessageStreamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *EditMessageStreamLogic) EditMessageStream(req *types.EditMessageReq, client chan<- *types.ChatStreamEvent) error {
+	return streamTurn(l.ctx, l.svcCtx, client, func(userId int64) (*chatTurn, error) {
+		return prepareEditTurn(l.ctx, l.svcCtx, userId, req.Id, req.MessageId, req.Content, req.Generation)
+	})
+}
@@ -0,0 +1,40 @@
+package conversation
+
+import (
+	"fmt"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/types"
+)
+
+// generationParams 以本次请求的生成参数覆盖角色的设置, 并按提供方限制校验
+func generationParams(c llm.Config, character *model.Character, override *types.GenerationSettings) (llm.Params, error) {
+	g := character.Generation
+	params := llm.Params{
+		Model:            g.Model,
+		Temperature:      g.Temperature,
+		TopP:             g.TopP,
+		MaxTokens:        g.MaxTokens,
+		PresencePenalty:  g.PresencePenalty,
+		FrequencyPenalty: g.FrequencyPenalty,
+		Stop:             g.Stop,
+	}
+	if override != nil {
+		params = params.Merge(llm.Params{
+			Model:            strings.TrimSpace(override.Model),
+			Temperature:      override.Temperature,
+			TopP:             override.TopP,
+			MaxTokens:        override.MaxTokens,
+			PresencePenalty:  override.PresencePenalty,
+			FrequencyPenalty: override.FrequencyPenalty,
+			Stop:             override.Stop,
+		})
+	}
+
+	if err := c.CheckParams(params); err != nil {
+		return llm.Params{}, &chatError{Code: 400, Message: fmt.Sprintf("生成参数错误: %v", err)}
+	}
+	return params, nil
+}
@@ -0,0 +1,78 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/cursor"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetConversationListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取会话列表
+func NewGetConversationListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetConversationListLogic {
+	return &GetConversationListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetConversationListLogic) GetConversationList(req *types.ConversationListReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if _, code, msg := findVisibleCharacter(l.svcCtx.DB, req.Id, userId); code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	page, err := cursor.Parse(req.Cursor, req.PageSize)
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: "无效的分页游标",
+		}, nil
+	}
+
+	// 最近有新消息的会话排在前面
+	var conversations []model.Conversation
+	if err := l.svcCtx.DB.Where("user_id = ? AND character_id = ?", userId, req.Id).
+		Scopes(page.Scope("conversations", "updated_at")).Find(&conversations).Error; err != nil {
+		return nil, errors.New("查询会话列表失败")
+	}
+	conversations, next, hasMore := cursor.Trim(page, conversations, func(c *model.Conversation) cursor.Cursor {
+		return cursor.Cursor{Time: c.UpdatedAt, Id: c.Id}
+	})
+
+	list := make([]types.ConversationInfo, len(conversations))
+	for i := range conversations {
+		list[i] = toConversationInfo(&conversations[i])
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.ConversationList{
+			List:       list,
+			NextCursor: next,
+			HasMore:    hasMore,
+		},
+	}, nil
+}
@@ -0,0 +1,85 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetMessageAlternativesLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取消息的备选分支
+func NewGetMessageAlternativesLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetMessageAlternativesLogic {
+	return &GetMessageAlternativesLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetMessageAlternativesLogic) GetMessageAlternatives(req *types.MessageIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	conversation, code, msg := findOwnedConversation(l.svcCtx.DB, req.Id, req.ConversationId, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	leafId, err := activeMessageId(l.svcCtx.DB, conversation)
+	if err != nil {
+		return nil, errors.New("查询消息失败")
+	}
+	tree, err := loadTree(l.svcCtx.DB, conversation.Id)
+	if err != nil {
+		return nil, errors.New("查询消息失败")
+	}
+	if !tree.has(req.MessageId) {
+		return &types.DataResp{
+			Code:    404,
+			Message: "消息不存在",
+		}, nil
+	}
+
+	var messages []model.Message
+	if err := l.svcCtx.DB.Where("id IN ?", tree.siblings(req.MessageId)).Order("id ASC").Find(&messages).Error; err != nil {
+		return nil, errors.New("查询消息失败")
+	}
+
+	branch := tree.path(leafId)
+	list := make([]types.MessageInfo, len(messages))
+	active := -1
+	for i := range messages {
+		list[i] = tree.describe(&messages[i])
+		if slices.Contains(branch, messages[i].Id) {
+			active = i
+		}
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.MessageAlternatives{
+			List:        list,
+			ActiveIndex: active,
+		},
+	}, nil
+}
@@ -0,0 +1,93 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/cursor"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetMessageListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取会话消息
+func NewGetMessageListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetMessageListLogic {
+	return &GetMessageListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetMessageListLogic) GetMessageList(req *types.GetMessageListReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	conversation, code, msg := findOwnedConversation(l.svcCtx.DB, req.Id, req.ConversationId, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	page, err := cursor.Parse(req.Cursor, req.PageSize)
+	if err != nil {
+		return &types.DataResp{
+			Code:    400,
+			Message: "无效的分页游标",
+		}, nil
+	}
+
+	// 只返回当前分支上的消息, 其他分支通过备选消息接口查看
+	leafId, err := activeMessageId(l.svcCtx.DB, conversation)
+	if err != nil {
+		return nil, errors.New("查询消息失败")
+	}
+	tree, err := loadTree(l.svcCtx.DB, conversation.Id)
+	if err != nil {
+		return nil, errors.New("查询消息失败")
+	}
+
+	// 从最新的消息开始向前翻页, 页内再按时间顺序排列
+	var messages []model.Message
+	if branch := tree.path(leafId); len(branch) > 0 {
+		if err := l.svcCtx.DB.Where("id IN ?", branch).
+			Scopes(page.Scope("messages", "created_at")).Find(&messages).Error; err != nil {
+			return nil, errors.New("查询消息失败")
+		}
+	}
+	messages, next, hasMore := cursor.Trim(page, messages, func(m *model.Message) cursor.Cursor {
+		return cursor.Cursor{Time: m.CreatedAt, Id: m.Id}
+	})
+	slices.Reverse(messages)
+
+	list := make([]types.MessageInfo, len(messages))
+	for i := range messages {
+		list[i] = tree.describe(&messages[i])
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data: types.MessageList{
+			List:       list,
+			NextCursor: next,
+			HasMore:    hasMore,
+		},
+	}, nil
+}
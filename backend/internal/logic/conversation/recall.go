@@ -0,0 +1,48 @@
+package conversation
+
+import (
+	"context"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/memory"
+	"aifriend/internal/pkg/prompt"
+	"aifriend/internal/svc"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// recall 按最近的消息检索长期记忆、知识库片段和世界设定条目, 填入提示词数据.
+// 检索失败只记录日志, 不影响对话.
+func recall(ctx context.Context, svcCtx *svc.ServiceContext, data *prompt.Data, character *model.Character, userId int64, history []model.Message) {
+	if len(history) == 0 {
+		return
+	}
+	query := history[len(history)-1].Content
+	logger := logx.WithContext(ctx)
+
+	memories, err := svcCtx.Memory.Relevant(ctx, userId, character.Id, query)
+	if err != nil {
+		logger.Errorf("load memories of character %d failed: %v", character.Id, err)
+	}
+	data.Memories = memory.Contents(memories)
+
+	chunks, err := svcCtx.Knowledge.Search(ctx, character, query)
+	if err != nil {
+		logger.Errorf("search knowledge of character %d failed: %v", character.Id, err)
+	}
+	for _, c := range chunks {
+		data.Knowledge = append(data.Knowledge, c.Content)
+	}
+
+	recent := make([]string, len(history))
+	for i, m := range history {
+		recent[i] = m.Content
+	}
+	entries, err := svcCtx.Knowledge.Lore(ctx, character.Id, recent)
+	if err != nil {
+		logger.Errorf("match lorebook of character %d failed: %v", character.Id, err)
+	}
+	for _, e := range entries {
+		data.Lore = append(data.Lore, data.Expand(e.Content))
+	}
+}
@@ -0,0 +1,34 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RegenerateMessageStreamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 重新生成回复并流式返回
+func NewRegenerateMessageStreamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RegenerateMessageStreamLogic {
+	return &RegenerateMessageStreamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RegenerateMessageStreamLogic) RegenerateMessageStream(req *types.RegenerateMessageReq, client chan<- *types.ChatStreamEvent) error {
+	return streamTurn(l.ctx, l.svcCtx, client, func(userId int64) (*chatTurn, error) {
+		return prepareRegenerateTurn(l.ctx, l.svcCtx, userId, req.Id, req.MessageId, req.Generation)
+	})
+}
@@ -0,0 +1,62 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+type RemoveConversationLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除会话
+func NewRemoveConversationLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveConversationLogic {
+	return &RemoveConversationLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveConversationLogic) RemoveConversation(req *types.ConversationIdReq) (resp *types.BaseResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	conversation, code, msg := findOwnedConversation(l.svcCtx.DB, req.Id, req.ConversationId, userId)
+	if code != 0 {
+		return &types.BaseResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	// 删除会话及其消息
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("conversation_id = ?", conversation.Id).Delete(&model.Message{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(conversation).Error
+	})
+	if err != nil {
+		return nil, errors.New("删除会话失败")
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "删除成功",
+	}, nil
+}
@@ -0,0 +1,72 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SelectBranchLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 切换会话分支
+func NewSelectBranchLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SelectBranchLogic {
+	return &SelectBranchLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SelectBranchLogic) SelectBranch(req *types.SelectBranchReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	conversation, code, msg := findOwnedConversation(l.svcCtx.DB, req.Id, req.ConversationId, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	// 先把早期会话串成分支, 再在树中定位
+	if _, err := activeMessageId(l.svcCtx.DB, conversation); err != nil {
+		return nil, errors.New("切换分支失败")
+	}
+	tree, err := loadTree(l.svcCtx.DB, conversation.Id)
+	if err != nil {
+		return nil, errors.New("切换分支失败")
+	}
+	if !tree.has(req.MessageId) {
+		return &types.DataResp{
+			Code:    404,
+			Message: "消息不存在",
+		}, nil
+	}
+
+	// 选中的消息之后若还有分支, 继续沿最新的分支到末尾
+	leafId := tree.latestLeaf(req.MessageId)
+	if err := l.svcCtx.DB.Model(conversation).UpdateColumn("active_message_id", leafId).Error; err != nil {
+		return nil, errors.New("切换分支失败")
+	}
+	conversation.ActiveMessageId = leafId
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "切换成功",
+		Data:    toConversationInfo(conversation),
+	}, nil
+}
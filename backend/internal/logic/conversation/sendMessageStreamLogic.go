@@ -0,0 +1,50 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package conversation
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// 流式事件类型
+const (
+	eventDelta = "delta"
+	eventDone  = "done"
+	eventError = "error"
+)
+
+type SendMessageStreamLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 发送消息并流式返回回复
+func NewSendMessageStreamLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SendMessageStreamLogic {
+	return &SendMessageStreamLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SendMessageStreamLogic) SendMessageStream(req *types.SendMessageReq, client chan<- *types.ChatStreamEvent) error {
+	return streamTurn(l.ctx, l.svcCtx, client, func(userId int64) (*chatTurn, error) {
+		return prepareChatTurn(l.ctx, l.svcCtx, userId, req.Id, req.Content, req.Generation)
+	})
+}
+
+func errorEvent(err error) *types.ChatStreamEvent {
+	var ce *chatError
+	if errors.As(err, &ce) {
+		return &types.ChatStreamEvent{Event: eventError, Code: ce.Code, Error: ce.Message}
+	}
+	return &types.ChatStreamEvent{Event: eventError, Code: 500, Error: err.Error()}
+}
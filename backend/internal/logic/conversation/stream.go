@@ -0,0 +1,79 @@
+package conversation
+
+import (
+	"context"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+// streamTurn 准备一轮对话并通过 client 推送增量内容和结果事件.
+// 业务错误以 error 事件推送, 只有生成失败时返回错误.
+func streamTurn(ctx context.Context, svcCtx *svc.ServiceContext, client chan<- *types.ChatStreamEvent,
+	prepare func(userId int64) (*chatTurn, error)) error {
+	userId, err := userIdFromContext(ctx)
+	if err != nil {
+		sendEvent(ctx, client, &types.ChatStreamEvent{Event: eventError, Code: 401, Error: "无效的用户身份"})
+		return nil
+	}
+
+	turn, err := prepare(userId)
+	if err != nil {
+		sendEvent(ctx, client, errorEvent(err))
+		return nil
+	}
+
+	reply, err := turn.streamReply(ctx, svcCtx, func(delta string) error {
+		if !sendEvent(ctx, client, &types.ChatStreamEvent{Event: eventDelta, Content: delta}) {
+			return ctx.Err()
+		}
+		return nil
+	})
+	if err != nil {
+		// 客户端已断开, 已生成的内容已经保存
+		if ctx.Err() != nil {
+			return nil
+		}
+		sendEvent(ctx, client, &types.ChatStreamEvent{Event: eventError, Code: 500, Error: "生成回复失败"})
+		return err
+	}
+
+	userMessage, message := turn.describe(ctx, svcCtx, reply)
+	sendEvent(ctx, client, &types.ChatStreamEvent{
+		Event:       eventDone,
+		UserMessage: userMessage,
+		Message:     message,
+	})
+
+	return nil
+}
+
+// sendEvent 推送事件, 客户端断开时返回 false
+func sendEvent(ctx context.Context, client chan<- *types.ChatStreamEvent, event *types.ChatStreamEvent) bool {
+	select {
+	case client <- event:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// describe 转换本轮的用户消息和回复, 并附带备选分支信息. reply 可以为空
+func (t *chatTurn) describe(ctx context.Context, svcCtx *svc.ServiceContext, reply *model.Message) (*types.MessageInfo, *types.MessageInfo) {
+	// 请求可能已被取消, 使用不带请求上下文的连接查询
+	tree, err := loadTree(svcCtx.DB, t.conversation.Id)
+	if err != nil {
+		logx.WithContext(ctx).Errorf("load message tree of conversation %d failed: %v", t.conversation.Id, err)
+		tree = &messageTree{}
+	}
+
+	userMessage := tree.describe(t.userMessage)
+	if reply == nil {
+		return &userMessage, nil
+	}
+	message := tree.describe(reply)
+	return &userMessage, &message
+}
@@ -0,0 +1,154 @@
+package conversation
+
+import (
+	"aifriend/internal/model"
+	"aifriend/internal/types"
+
+	"gorm.io/gorm"
+)
+
+// 会话消息以树的形式保存: 每条消息指向上一条消息. 重新生成和编辑都会在同一父消息下
+// 新增一条分支, 已有内容不会被覆盖. 会话记录当前分支的最后一条消息, 对话历史即从
+// 根消息到该消息的路径.
+
+// messageNode 消息在树中的位置
+type messageNode struct {
+	Id       int64
+	ParentId int64
+}
+
+// messageTree 会话内所有消息的父子关系
+type messageTree struct {
+	parents  map[int64]int64
+	children map[int64][]int64 // 父消息ID -> 子消息ID, 按创建顺序
+}
+
+// loadTree 查询会话内所有消息的父子关系
+func loadTree(db *gorm.DB, conversationId int64) (*messageTree, error) {
+	var nodes []messageNode
+	if err := db.Model(&model.Message{}).
+		Select("id", "parent_id").
+		Where("conversation_id = ?", conversationId).
+		Order("id ASC").
+		Find(&nodes).Error; err != nil {
+		return nil, err
+	}
+
+	tree := &messageTree{
+		parents:  make(map[int64]int64, len(nodes)),
+		children: make(map[int64][]int64),
+	}
+	for _, n := range nodes {
+		tree.parents[n.Id] = n.ParentId
+		tree.children[n.ParentId] = append(tree.children[n.ParentId], n.Id)
+	}
+	return tree, nil
+}
+
+// has 消息是否属于该会话
+func (t *messageTree) has(id int64) bool {
+	_, ok := t.parents[id]
+	return ok
+}
+
+// path 返回从根消息到 leafId 的消息ID, 按时间顺序
+func (t *messageTree) path(leafId int64) []int64 {
+	var ids []int64
+	for id := leafId; t.has(id); id = t.parents[id] {
+		ids = append(ids, id)
+	}
+	for i, j := 0, len(ids)-1; i < j; i, j = i+1, j-1 {
+		ids[i], ids[j] = ids[j], ids[i]
+	}
+	return ids
+}
+
+// latestLeaf 沿最新的子消息向下, 返回 id 所在分支的最后一条消息
+func (t *messageTree) latestLeaf(id int64) int64 {
+	for {
+		children := t.children[id]
+		if len(children) == 0 {
+			return id
+		}
+		id = children[len(children)-1]
+	}
+}
+
+// siblings 返回与 id 同一父消息的所有消息ID(包含自身)
+func (t *messageTree) siblings(id int64) []int64 {
+	return t.children[t.parents[id]]
+}
+
+// describe 转换为消息信息并填充备选分支的数量和位置
+func (t *messageTree) describe(m *model.Message) types.MessageInfo {
+	info := toMessageInfo(m)
+	siblings := t.siblings(m.Id)
+	info.SiblingCount = len(siblings)
+	for i, id := range siblings {
+		if id == m.Id {
+			info.SiblingIndex = i
+			break
+		}
+	}
+	return info
+}
+
+// activeMessageId 返回当前分支的最后一条消息ID, 会话还没有消息时返回 0.
+// 早期会话的消息没有父消息, 第一次访问时按时间顺序把它们串成一条分支.
+func activeMessageId(db *gorm.DB, conversation *model.Conversation) (int64, error) {
+	if conversation.ActiveMessageId != 0 {
+		return conversation.ActiveMessageId, nil
+	}
+
+	var ids []int64
+	if err := db.Model(&model.Message{}).
+		Where("conversation_id = ?", conversation.Id).
+		Order("id ASC").
+		Pluck("id", &ids).Error; err != nil {
+		return 0, err
+	}
+	if len(ids) == 0 {
+		return 0, nil
+	}
+
+	last := ids[len(ids)-1]
+	err := db.Transaction(func(tx *gorm.DB) error {
+		for i := 1; i < len(ids); i++ {
+			if err := tx.Model(&model.Message{}).Where("id = ?", ids[i]).
+				UpdateColumn("parent_id", ids[i-1]).Error; err != nil {
+				return err
+			}
+		}
+		return tx.Model(conversation).UpdateColumn("active_message_id", last).Error
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	conversation.ActiveMessageId = last
+	return last, nil
+}
+
+// loadBranch 查询从根消息到 leafId 的消息, 按时间顺序
+func loadBranch(db *gorm.DB, tree *messageTree, leafId int64) ([]model.Message, error) {
+	ids := tree.path(leafId)
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	// 子消息总是晚于父消息创建, 按ID排序即路径顺序
+	var messages []model.Message
+	if err := db.Where("id IN ?", ids).Order("id ASC").Find(&messages).Error; err != nil {
+		return nil, err
+	}
+	return messages, nil
+}
+
+// findMessage 查询会话内的消息
+func findMessage(db *gorm.DB, conversationId, messageId int64) (*model.Message, int, string) {
+	var message model.Message
+	if err := db.Where("conversation_id = ?", conversationId).First(&message, messageId).Error; err != nil {
+		return nil, 404, "消息不存在"
+	}
+	return &message, 0, ""
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"aifriend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// findCharacter 查询角色是否存在, 不存在时返回对应的状态码和提示
+func findCharacter(db *gorm.DB, characterId int64) (*model.Character, int, string) {
+	var character model.Character
+	if err := db.First(&character, characterId).Error; err != nil {
+		return nil, 404, "角色不存在"
+	}
+
+	return &character, 0, ""
+}
+
+// findOwnedMemory 查询角色下的记忆并校验所有权, 校验失败时返回对应的状态码和提示
+func findOwnedMemory(db *gorm.DB, characterId, memoryId, userId int64) (*model.Memory, int, string) {
+	var memory model.Memory
+	if err := db.First(&memory, memoryId).Error; err != nil || memory.CharacterId != characterId {
+		return nil, 404, "记忆不存在"
+	}
+
+	// 验证所有权
+	if memory.UserId != userId {
+		return nil, 403, "无权访问此记忆"
+	}
+
+	return &memory, 0, ""
+}
@@ -0,0 +1,18 @@
+package memory
+
+import (
+	"aifriend/internal/model"
+	"aifriend/internal/types"
+)
+
+func toMemoryInfo(m *model.Memory) types.MemoryInfo {
+	return types.MemoryInfo{
+		Id:              m.Id,
+		CharacterId:     m.CharacterId,
+		Content:         m.Content,
+		Pinned:          m.Pinned,
+		SourceMessageId: m.SourceMessageId,
+		CreatedAt:       m.CreatedAt.Format("2006-01-02 15:04:05"),
+		UpdatedAt:       m.UpdatedAt.Format("2006-01-02 15:04:05"),
+	}
+}
@@ -0,0 +1,61 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetMemoryListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取角色记住的记忆
+func NewGetMemoryListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetMemoryListLogic {
+	return &GetMemoryListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetMemoryListLogic) GetMemoryList(req *types.CharacterIdReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	if _, code, msg := findCharacter(l.svcCtx.DB, req.Id); code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	var memories []model.Memory
+	if err := l.svcCtx.DB.Where("user_id = ? AND character_id = ?", userId, req.Id).
+		Order("pinned DESC, updated_at DESC").Find(&memories).Error; err != nil {
+		return nil, errors.New("查询记忆失败")
+	}
+
+	list := make([]types.MemoryInfo, len(memories))
+	for i := range memories {
+		list[i] = toMemoryInfo(&memories[i])
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    list,
+	}, nil
+}
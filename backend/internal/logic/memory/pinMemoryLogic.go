@@ -0,0 +1,59 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type PinMemoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 置顶或取消置顶记忆
+func NewPinMemoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *PinMemoryLogic {
+	return &PinMemoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *PinMemoryLogic) PinMemory(req *types.PinMemoryReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	memory, code, msg := findOwnedMemory(l.svcCtx.DB, req.Id, req.MemoryId, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	if err := l.svcCtx.DB.Model(memory).Update("pinned", req.Pinned).Error; err != nil {
+		return nil, errors.New("更新记忆失败")
+	}
+
+	message := "已取消置顶"
+	if req.Pinned {
+		message = "已置顶"
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: message,
+		Data:    toMemoryInfo(memory),
+	}, nil
+}
@@ -0,0 +1,57 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RemoveMemoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 删除记忆
+func NewRemoveMemoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveMemoryLogic {
+	return &RemoveMemoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveMemoryLogic) RemoveMemory(req *types.MemoryIdReq) (resp *types.BaseResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	memory, code, msg := findOwnedMemory(l.svcCtx.DB, req.Id, req.MemoryId, userId)
+	if code != 0 {
+		return &types.BaseResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	if err := l.svcCtx.DB.Delete(memory).Error; err != nil {
+		return nil, errors.New("删除记忆失败")
+	}
+
+	if err := l.svcCtx.Memory.Forget(l.ctx, memory.Id); err != nil {
+		l.Errorf("remove embedding of memory %d failed: %v", memory.Id, err)
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "删除成功",
+	}, nil
+}
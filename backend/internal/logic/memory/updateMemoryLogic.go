@@ -0,0 +1,75 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package memory
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type UpdateMemoryLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 编辑记忆
+func NewUpdateMemoryLogic(ctx context.Context, svcCtx *svc.ServiceContext) *UpdateMemoryLogic {
+	return &UpdateMemoryLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *UpdateMemoryLogic) UpdateMemory(req *types.UpdateMemoryReq) (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		return &types.DataResp{
+			Code:    400,
+			Message: "记忆内容不能为空",
+		}, nil
+	}
+	if maxLength := l.svcCtx.Config.Memory.MaxLength; len([]rune(content)) > maxLength {
+		return &types.DataResp{
+			Code:    400,
+			Message: fmt.Sprintf("记忆最多%d字", maxLength),
+		}, nil
+	}
+
+	memory, code, msg := findOwnedMemory(l.svcCtx.DB, req.Id, req.MemoryId, userId)
+	if code != 0 {
+		return &types.DataResp{
+			Code:    code,
+			Message: msg,
+		}, nil
+	}
+
+	if err := l.svcCtx.DB.Model(memory).Update("content", content).Error; err != nil {
+		return nil, errors.New("更新记忆失败")
+	}
+
+	// 向量更新失败只影响检索排序, 不影响编辑结果
+	if err := l.svcCtx.Memory.Index(l.ctx, *memory); err != nil {
+		l.Errorf("index memory %d failed: %v", memory.Id, err)
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "更新成功",
+		Data:    toMemoryInfo(memory),
+	}, nil
+}
@@ -8,11 +8,13 @@ import (
 	"errors"
 
 	"aifriend/internal/model"
+	"aifriend/internal/pkg/session"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
 	"github.com/zeromicro/go-zero/core/logx"
 	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
 )
 
 type ChangePasswordLogic struct {
@@ -30,7 +32,7 @@ func NewChangePasswordLogic(ctx context.Context, svcCtx *svc.ServiceContext) *Ch
 	}
 }
 
-func (l *ChangePasswordLogic) ChangePassword(req *types.ChangePasswordReq) (resp *types.BaseResp, err error) {
+func (l *ChangePasswordLogic) ChangePassword(req *types.ChangePasswordReq) (resp *types.DataResp, err error) {
 	// 从context中获取用户ID
 	userId, err := userIdFromContext(l.ctx)
 	if err != nil {
@@ -45,7 +47,7 @@ func (l *ChangePasswordLogic) ChangePassword(req *types.ChangePasswordReq) (resp
 
 	// 验证旧密码
 	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.OldPassword)); err != nil {
-		return &types.BaseResp{
+		return &types.DataResp{
 			Code:    400,
 			Message: "旧密码错误",
 		}, nil
@@ -57,13 +59,34 @@ func (l *ChangePasswordLogic) ChangePassword(req *types.ChangePasswordReq) (resp
 		return nil, errors.New("密码加密失败")
 	}
 
-	// 更新密码
-	if err := l.svcCtx.DB.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+	// 更新密码并使其他设备上的令牌失效, 当前设备换用新版本的令牌继续登录
+	var tokens *session.Tokens
+	err = l.svcCtx.DB.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&user).Update("password", string(hashedPassword)).Error; err != nil {
+			return err
+		}
+		current := session.FromContext(l.ctx)
+		if err := session.InvalidateUser(tx, user.Id, current); err != nil {
+			return err
+		}
+		if err := tx.First(&user, user.Id).Error; err != nil {
+			return err
+		}
+		tokens, err = session.Issue(l.ctx, tx, l.svcCtx.Config.Auth, &user, current)
+		return err
+	})
+	if err != nil {
+		l.Errorf("修改密码失败: %v", err)
 		return nil, errors.New("修改密码失败")
 	}
 
-	return &types.BaseResp{
+	return &types.DataResp{
 		Code:    0,
-		Message: "密码修改成功",
+		Message: "密码修改成功, 其他设备已退出登录",
+		Data: types.TokenResp{
+			AccessToken:  tokens.AccessToken,
+			RefreshToken: tokens.RefreshToken,
+			ExpiresIn:    tokens.ExpiresIn,
+		},
 	}, nil
 }
@@ -0,0 +1,67 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package user
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/cursor"
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type GetSessionListLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 获取登录设备
+func NewGetSessionListLogic(ctx context.Context, svcCtx *svc.ServiceContext) *GetSessionListLogic {
+	return &GetSessionListLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *GetSessionListLogic) GetSessionList() (resp *types.DataResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	// 只列出最近使用的设备, 更早的设备可以通过退出所有设备吊销
+	var sessions []model.Session
+	if err := l.svcCtx.DB.Where("user_id = ? AND revoked_at IS NULL AND expires_at > ?", userId, time.Now()).
+		Order("last_used_at DESC").Limit(cursor.MaxPageSize).Find(&sessions).Error; err != nil {
+		return nil, errors.New("查询登录设备失败")
+	}
+
+	current := session.FromContext(l.ctx)
+	list := make([]types.SessionInfo, len(sessions))
+	for i, s := range sessions {
+		list[i] = types.SessionInfo{
+			Id:         s.Id,
+			DeviceName: s.DeviceName,
+			UserAgent:  s.UserAgent,
+			Ip:         s.Ip,
+			Current:    s.Id == current,
+			CreatedAt:  s.CreatedAt.Format("2006-01-02 15:04:05"),
+			LastUsedAt: s.LastUsedAt.Format("2006-01-02 15:04:05"),
+		}
+	}
+
+	return &types.DataResp{
+		Code:    0,
+		Message: "获取成功",
+		Data:    list,
+	}, nil
+}
@@ -43,11 +43,12 @@ func (l *GetUserInfoLogic) GetUserInfo() (resp *types.UserInfo, err error) {
 	}
 
 	return &types.UserInfo{
-		Id:        user.Id,
-		Username:  user.Username,
-		Email:     user.Email,
-		Avatar:    user.Avatar,
-		Profile:   user.Profile,
-		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
+		Id:            user.Id,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.EmailVerifiedAt != nil,
+		Avatar:        user.Avatar,
+		Profile:       user.Profile,
+		CreatedAt:     user.CreatedAt.Format("2006-01-02 15:04:05"),
 	}, nil
 }
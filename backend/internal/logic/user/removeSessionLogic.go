@@ -0,0 +1,56 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package user
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/session"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type RemoveSessionLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 下线登录设备
+func NewRemoveSessionLogic(ctx context.Context, svcCtx *svc.ServiceContext) *RemoveSessionLogic {
+	return &RemoveSessionLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *RemoveSessionLogic) RemoveSession(req *types.SessionIdReq) (resp *types.BaseResp, err error) {
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	var s model.Session
+	if err := l.svcCtx.DB.Where("id = ? AND user_id = ? AND revoked_at IS NULL", req.Id, userId).First(&s).Error; err != nil {
+		return &types.BaseResp{
+			Code:    404,
+			Message: "登录设备不存在",
+		}, nil
+	}
+
+	// 会话的刷新令牌一并吊销, 该设备上的访问令牌立即失效
+	if err := session.Revoke(l.svcCtx.DB, "id = ?", s.Id); err != nil {
+		return nil, errors.New("下线登录设备失败")
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "已下线",
+	}, nil
+}
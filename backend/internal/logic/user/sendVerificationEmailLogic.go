@@ -0,0 +1,72 @@
+// Code scaffolded by goctl. Safe to edit.
+// goctl 1.9.2
+
+package user
+
+import (
+	"context"
+	"errors"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/account"
+	"aifriend/internal/svc"
+	"aifriend/internal/types"
+
+	"github.com/zeromicro/go-zero/core/logx"
+)
+
+type SendVerificationEmailLogic struct {
+	logx.Logger
+	ctx    context.Context
+	svcCtx *svc.ServiceContext
+}
+
+// 发送邮箱验证邮件
+func NewSendVerificationEmailLogic(ctx context.Context, svcCtx *svc.ServiceContext) *SendVerificationEmailLogic {
+	return &SendVerificationEmailLogic{
+		Logger: logx.WithContext(ctx),
+		ctx:    ctx,
+		svcCtx: svcCtx,
+	}
+}
+
+func (l *SendVerificationEmailLogic) SendVerificationEmail() (resp *types.BaseResp, err error) {
+	// 从context中获取用户ID
+	userId, err := userIdFromContext(l.ctx)
+	if err != nil {
+		return nil, errors.New("无效的用户身份")
+	}
+
+	var user model.User
+	if err := l.svcCtx.DB.First(&user, userId).Error; err != nil {
+		return nil, errors.New("用户不存在")
+	}
+	if user.EmailVerifiedAt != nil {
+		return &types.BaseResp{
+			Code:    400,
+			Message: "邮箱已验证",
+		}, nil
+	}
+
+	if err := l.svcCtx.Emails.SendVerification(l.ctx, &user); err != nil {
+		switch {
+		case errors.Is(err, account.ErrNoEmail):
+			return &types.BaseResp{
+				Code:    400,
+				Message: "请先设置邮箱",
+			}, nil
+		case errors.Is(err, account.ErrTooFrequent):
+			return &types.BaseResp{
+				Code:    400,
+				Message: "发送过于频繁, 请稍后再试",
+			}, nil
+		}
+		l.Errorf("发送验证邮件失败: %v", err)
+		return nil, errors.New("发送验证邮件失败")
+	}
+
+	return &types.BaseResp{
+		Code:    0,
+		Message: "验证邮件已发送",
+	}, nil
+}
@@ -9,6 +9,7 @@ import (
 	"strings"
 
 	"aifriend/internal/model"
+	"aifriend/internal/pkg/account"
 	"aifriend/internal/svc"
 	"aifriend/internal/types"
 
@@ -64,7 +65,22 @@ func (l *UpdateUserInfoLogic) UpdateUserInfo(req *types.UpdateUserReq) (resp *ty
 
 	email := strings.TrimSpace(req.Email)
 	if email != "" {
-		updates["email"] = email
+		if !account.ValidEmail(email) {
+			return &types.BaseResp{
+				Code:    400,
+				Message: "邮箱格式不正确",
+			}, nil
+		}
+
+		var user model.User
+		if err := l.svcCtx.DB.Select("email").First(&user, userId).Error; err != nil {
+			return nil, errors.New("用户不存在")
+		}
+		// 更换邮箱后需要重新验证
+		if email != user.Email {
+			updates["email"] = email
+			updates["email_verified_at"] = nil
+		}
 	}
 
 	profile := strings.TrimSpace(req.Profile)
@@ -0,0 +1,24 @@
+package middleware
+
+import (
+	"net/http"
+
+	"aifriend/internal/pkg/client"
+)
+
+// ClientInfoMiddleware 把客户端地址和 User-Agent 存入请求上下文, 供登录和刷新令牌时记录设备信息
+type ClientInfoMiddleware struct {
+	resolver *client.Resolver
+}
+
+func NewClientInfoMiddleware(resolver *client.Resolver) *ClientInfoMiddleware {
+	return &ClientInfoMiddleware{
+		resolver: resolver,
+	}
+}
+
+func (m *ClientInfoMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		next(w, r.WithContext(client.NewContext(r.Context(), m.resolver.FromRequest(r))))
+	}
+}
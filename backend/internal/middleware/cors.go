@@ -22,7 +22,7 @@ func (m *CorsMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 		origin := r.Header.Get("Origin")
 
 		// 设置CORS头
-		if origin != "" && m.isOriginAllowed(origin) {
+		if origin != "" && m.IsOriginAllowed(origin) {
 			w.Header().Set("Access-Control-Allow-Origin", origin)
 			if m.AllowCredentials {
 				w.Header().Set("Access-Control-Allow-Credentials", "true")
@@ -43,7 +43,7 @@ func (m *CorsMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
 	}
 }
 
-func (m *CorsMiddleware) isOriginAllowed(origin string) bool {
+func (m *CorsMiddleware) IsOriginAllowed(origin string) bool {
 	for _, allowed := range m.AllowOrigins {
 		if allowed == "*" {
 			return true
@@ -0,0 +1,59 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"aifriend/internal/pkg/jwt"
+	"aifriend/internal/pkg/session"
+
+	"github.com/zeromicro/go-zero/core/logc"
+	"gorm.io/gorm"
+)
+
+// SessionCheckMiddleware 拒绝已吊销会话的访问令牌. 签名和过期时间已由 jwt 校验,
+// 这里按令牌的 jti 查询会话状态并核对用户的令牌版本, 使退出登录、远程下线和修改密码立即生效,
+// 不必等待访问令牌过期
+type SessionCheckMiddleware struct {
+	db     *gorm.DB
+	secret string
+}
+
+func NewSessionCheckMiddleware(db *gorm.DB, secret string) *SessionCheckMiddleware {
+	return &SessionCheckMiddleware{
+		db:     db,
+		secret: secret,
+	}
+}
+
+func (m *SessionCheckMiddleware) Handle(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		claims, err := jwt.ParseToken(bearerToken(r), m.secret)
+		if err != nil {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		if err := session.Check(m.db, claims.UserId, claims.TokenVersion, claims.ID); err != nil {
+			if errors.Is(err, session.ErrRevoked) {
+				w.WriteHeader(http.StatusUnauthorized)
+			} else {
+				logc.Errorf(r.Context(), "check session failed: %v", err)
+				w.WriteHeader(http.StatusInternalServerError)
+			}
+			return
+		}
+
+		next(w, r.WithContext(session.NewContext(r.Context(), claims.ID)))
+	}
+}
+
+// bearerToken 读取 Authorization 请求头中的令牌
+func bearerToken(r *http.Request) string {
+	header := r.Header.Get("Authorization")
+	if len(header) > 7 && strings.EqualFold(header[:7], "Bearer ") {
+		return header[7:]
+	}
+	return ""
+}
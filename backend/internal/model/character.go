@@ -1,23 +1,111 @@
 package model
 
 import (
+	"database/sql/driver"
+	"encoding/json"
+	"errors"
+	"math"
 	"time"
 
 	"gorm.io/gorm"
 )
 
+// 角色可见性
+const (
+	VisibilityPrivate  = "private"  // 仅所有者可见
+	VisibilityUnlisted = "unlisted" // 知道链接即可访问, 不出现在公开列表中
+	VisibilityPublic   = "public"   // 出现在公开列表中
+)
+
 type Character struct {
-	Id              int64          `gorm:"primaryKey;autoIncrement" json:"id"`
-	UserId          int64          `gorm:"index;not null" json:"user_id"`
-	Name            string         `gorm:"size:50;not null" json:"name"`
-	Photo           string         `gorm:"size:255" json:"photo"`
-	Profile         string         `gorm:"type:text" json:"profile"`
-	BackgroundImage string         `gorm:"size:255" json:"background_image"`
-	CreatedAt       time.Time      `json:"created_at"`
-	UpdatedAt       time.Time      `json:"updated_at"`
-	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+	Id                 int64              `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId             int64              `gorm:"index;not null" json:"user_id"`
+	Name               string             `gorm:"size:50;not null" json:"name"`
+	Photo              string             `gorm:"size:255" json:"photo"`
+	Profile            string             `gorm:"type:text" json:"profile"`
+	BackgroundImage    string             `gorm:"size:255" json:"background_image"`
+	Scenario           string             `gorm:"type:text" json:"scenario"`
+	Greeting           string             `gorm:"type:text" json:"greeting"`
+	AlternateGreetings StringList         `gorm:"type:text" json:"alternate_greetings"` // 备选开场白, 新会话中可切换
+	Personality        string             `gorm:"size:1000" json:"personality"`         // 性格概述
+	ExampleDialogues   string             `gorm:"type:text" json:"example_dialogues"`
+	SpeakingStyle      string             `gorm:"size:500" json:"speaking_style"`
+	CreatorNotes       string             `gorm:"type:text" json:"creator_notes"`   // 作者给使用者的说明, 不会发送给模型
+	PromptTemplate     string             `gorm:"type:text" json:"prompt_template"` // 自定义系统提示词模板, 为空时使用默认模板
+	Generation         GenerationSettings `gorm:"embedded;embeddedPrefix:gen_" json:"generation"`
+	Visibility         string             `gorm:"size:20;not null;default:private;index" json:"visibility"`
+	Category           string             `gorm:"size:30;index" json:"category"`
+	Tags               []Tag              `gorm:"many2many:character_tags" json:"tags"`
+	ForkedFromId       int64              `gorm:"index;not null;default:0" json:"forked_from_id"` // 复制来源角色, 0 表示原创
+	ForkCount          int64              `gorm:"not null;default:0" json:"fork_count"`           // 被复制的次数
+	ChatCount          int64              `gorm:"not null;default:0" json:"chat_count"`           // 会话数, 用于按热度排序
+	LikeCount          int64              `gorm:"not null;default:0" json:"like_count"`           // 点赞数, 用于按喜爱程度排序
+	FavoriteCount      int64              `gorm:"not null;default:0" json:"favorite_count"`       // 收藏数
+	RatingCount        int64              `gorm:"not null;default:0" json:"rating_count"`         // 评分人数
+	RatingSum          int64              `gorm:"not null;default:0" json:"rating_sum"`           // 评分总和, 与评分人数一起计算平均分
+	CreatedAt          time.Time          `json:"created_at"`
+	UpdatedAt          time.Time          `json:"updated_at"`
+	DeletedAt          gorm.DeletedAt     `gorm:"index" json:"-"`
 }
 
 func (Character) TableName() string {
 	return "characters"
 }
+
+// VisibleTo 角色是否对该用户可见, userId 为 0 表示未登录
+func (c *Character) VisibleTo(userId int64) bool {
+	if c.Visibility == VisibilityPublic || c.Visibility == VisibilityUnlisted {
+		return true
+	}
+	return userId != 0 && c.UserId == userId
+}
+
+// RatingAverage 平均评分, 保留两位小数, 没有评分时为 0
+func (c *Character) RatingAverage() float64 {
+	if c.RatingCount == 0 {
+		return 0
+	}
+	return math.Round(float64(c.RatingSum)/float64(c.RatingCount)*100) / 100
+}
+
+// GenerationSettings 角色的生成参数, 未设置的字段使用全局配置
+type GenerationSettings struct {
+	Model            string     `gorm:"size:100" json:"model"`
+	Temperature      *float64   `json:"temperature"`
+	TopP             *float64   `json:"top_p"`
+	MaxTokens        int        `gorm:"not null;default:0" json:"max_tokens"`
+	PresencePenalty  *float64   `json:"presence_penalty"`
+	FrequencyPenalty *float64   `json:"frequency_penalty"`
+	Stop             StringList `gorm:"type:text" json:"stop"`
+}
+
+// StringList 以 JSON 数组保存的字符串列表
+type StringList []string
+
+func (l StringList) Value() (driver.Value, error) {
+	if l == nil {
+		return "[]", nil
+	}
+	b, err := json.Marshal([]string(l))
+	return string(b), err
+}
+
+func (l *StringList) Scan(value interface{}) error {
+	var raw []byte
+	switch v := value.(type) {
+	case nil:
+		*l = nil
+		return nil
+	case []byte:
+		raw = v
+	case string:
+		raw = []byte(v)
+	default:
+		return errors.New("model: unsupported StringList value")
+	}
+	if len(raw) == 0 {
+		*l = nil
+		return nil
+	}
+	return json.Unmarshal(raw, (*[]string)(l))
+}
@@ -0,0 +1,64 @@
+package model
+
+import (
+	"time"
+)
+
+// CharacterVersion 角色每次修改前的快照, 快照中引用的图片文件不会随修改删除
+type CharacterVersion struct {
+	Id          int64             `gorm:"primaryKey;autoIncrement" json:"id"`
+	CharacterId int64             `gorm:"not null;uniqueIndex:idx_character_version" json:"character_id"`
+	Version     int               `gorm:"not null;uniqueIndex:idx_character_version" json:"version"` // 角色内从 1 开始递增
+	Snapshot    CharacterSnapshot `gorm:"type:text;serializer:json" json:"snapshot"`
+	CreatedAt   time.Time         `json:"created_at"`
+}
+
+func (CharacterVersion) TableName() string {
+	return "character_versions"
+}
+
+// CharacterSnapshot 角色可编辑的字段, 不包含统计数据
+type CharacterSnapshot struct {
+	Name               string             `json:"name"`
+	Photo              string             `json:"photo"`
+	Profile            string             `json:"profile"`
+	BackgroundImage    string             `json:"background_image"`
+	Scenario           string             `json:"scenario"`
+	Greeting           string             `json:"greeting"`
+	AlternateGreetings []string           `json:"alternate_greetings"`
+	Personality        string             `json:"personality"`
+	ExampleDialogues   string             `json:"example_dialogues"`
+	SpeakingStyle      string             `json:"speaking_style"`
+	CreatorNotes       string             `json:"creator_notes"`
+	PromptTemplate     string             `json:"prompt_template"`
+	Generation         GenerationSettings `json:"generation"`
+	Visibility         string             `json:"visibility"`
+	Category           string             `json:"category"`
+	Tags               []string           `json:"tags"`
+}
+
+// Snapshot 返回角色当前的快照, 标签需要已经加载
+func (c *Character) Snapshot() CharacterSnapshot {
+	tags := make([]string, len(c.Tags))
+	for i, tag := range c.Tags {
+		tags[i] = tag.Name
+	}
+	return CharacterSnapshot{
+		Name:               c.Name,
+		Photo:              c.Photo,
+		Profile:            c.Profile,
+		BackgroundImage:    c.BackgroundImage,
+		Scenario:           c.Scenario,
+		Greeting:           c.Greeting,
+		AlternateGreetings: append([]string{}, c.AlternateGreetings...),
+		Personality:        c.Personality,
+		ExampleDialogues:   c.ExampleDialogues,
+		SpeakingStyle:      c.SpeakingStyle,
+		CreatorNotes:       c.CreatorNotes,
+		PromptTemplate:     c.PromptTemplate,
+		Generation:         c.Generation,
+		Visibility:         c.Visibility,
+		Category:           c.Category,
+		Tags:               tags,
+	}
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Conversation struct {
+	Id              int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId          int64          `gorm:"index;not null" json:"user_id"`
+	CharacterId     int64          `gorm:"index;not null" json:"character_id"`
+	Title           string         `gorm:"size:100" json:"title"`
+	ActiveMessageId int64          `gorm:"not null;default:0" json:"active_message_id"` // 当前分支的最后一条消息ID
+	Summary         string         `gorm:"type:text" json:"summary"`                    // 早期消息的滚动摘要
+	SummaryUntil    int64          `gorm:"not null;default:0" json:"summary_until"`     // 已纳入摘要的最后一条消息ID
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Conversation) TableName() string {
+	return "conversations"
+}
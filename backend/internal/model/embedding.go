@@ -0,0 +1,20 @@
+package model
+
+import "time"
+
+// Embedding 文本向量, 由 Kind + RefId 关联到记忆、知识库片段等来源记录
+type Embedding struct {
+	Id          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Kind        string    `gorm:"size:20;not null;uniqueIndex:idx_embedding_ref;index:idx_embedding_owner" json:"kind"`
+	RefId       int64     `gorm:"not null;uniqueIndex:idx_embedding_ref" json:"ref_id"`
+	UserId      int64     `gorm:"not null;index:idx_embedding_owner" json:"user_id"`
+	CharacterId int64     `gorm:"not null;index:idx_embedding_owner" json:"character_id"`
+	Dimensions  int       `gorm:"not null" json:"dimensions"`
+	Vector      []byte    `gorm:"type:blob;not null" json:"-"` // 小端序 float32 数组
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (Embedding) TableName() string {
+	return "embeddings"
+}
@@ -0,0 +1,42 @@
+package model
+
+import (
+	"time"
+)
+
+// CharacterLike 用户点赞的角色
+type CharacterLike struct {
+	UserId      int64     `gorm:"primaryKey" json:"user_id"`
+	CharacterId int64     `gorm:"primaryKey;index" json:"character_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (CharacterLike) TableName() string {
+	return "character_likes"
+}
+
+// CharacterFavorite 用户收藏的角色
+type CharacterFavorite struct {
+	UserId      int64     `gorm:"primaryKey" json:"user_id"`
+	CharacterId int64     `gorm:"primaryKey;index" json:"character_id"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (CharacterFavorite) TableName() string {
+	return "character_favorites"
+}
+
+// CharacterRating 用户对角色的评分和评价, 每个用户对每个角色只有一条
+type CharacterRating struct {
+	Id          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId      int64     `gorm:"not null;uniqueIndex:idx_rating_user_character" json:"user_id"`
+	CharacterId int64     `gorm:"not null;uniqueIndex:idx_rating_user_character;index" json:"character_id"`
+	Score       int       `gorm:"not null" json:"score"` // 1-5 星
+	Review      string    `gorm:"type:text" json:"review"`
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+func (CharacterRating) TableName() string {
+	return "character_ratings"
+}
@@ -0,0 +1,59 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// KnowledgeDocument 角色知识库中的参考文档
+type KnowledgeDocument struct {
+	Id          int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CharacterId int64          `gorm:"index;not null" json:"character_id"`
+	UserId      int64          `gorm:"index;not null" json:"user_id"`
+	Filename    string         `gorm:"size:255;not null" json:"filename"` // 上传时的原始文件名
+	Path        string         `gorm:"size:255;not null" json:"-"`        // 保存在知识库目录下的文件名
+	ContentType string         `gorm:"size:50;not null" json:"content_type"`
+	Size        int64          `gorm:"not null;default:0" json:"size"`
+	ChunkCount  int            `gorm:"not null;default:0" json:"chunk_count"`
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (KnowledgeDocument) TableName() string {
+	return "knowledge_documents"
+}
+
+// KnowledgeChunk 文档切分后的片段, 向量保存在 embeddings 表
+type KnowledgeChunk struct {
+	Id          int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	DocumentId  int64     `gorm:"index;not null" json:"document_id"`
+	CharacterId int64     `gorm:"index;not null" json:"character_id"`
+	Seq         int       `gorm:"not null" json:"seq"` // 在文档中的顺序
+	Content     string    `gorm:"type:text" json:"content"`
+	TokenCount  int       `gorm:"not null;default:0" json:"token_count"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+func (KnowledgeChunk) TableName() string {
+	return "knowledge_chunks"
+}
+
+// LorebookEntry 关键词触发的世界设定条目, 最近的消息中出现任一关键词时注入提示词
+type LorebookEntry struct {
+	Id          int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	CharacterId int64          `gorm:"index;not null" json:"character_id"`
+	Keywords    string         `gorm:"size:500" json:"keywords"` // 逗号分隔的触发关键词
+	Content     string         `gorm:"type:text" json:"content"`
+	Constant    bool           `gorm:"not null;default:false" json:"constant"` // 常驻条目无需关键词触发
+	Enabled     bool           `gorm:"not null" json:"enabled"`
+	Priority    int            `gorm:"not null;default:0" json:"priority"` // 触发条目过多时优先注入数值大的
+	CreatedAt   time.Time      `json:"created_at"`
+	UpdatedAt   time.Time      `json:"updated_at"`
+	DeletedAt   gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (LorebookEntry) TableName() string {
+	return "lorebook_entries"
+}
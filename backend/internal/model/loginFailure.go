@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// 登录失败原因
+const (
+	LoginFailureUserNotFound  = "user_not_found"
+	LoginFailureWrongPassword = "wrong_password"
+	LoginFailureThrottled     = "throttled" // 失败次数过多, 在等待或锁定期间再次尝试
+)
+
+// LoginFailure 登录失败的审计记录
+type LoginFailure struct {
+	Id        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId    int64     `gorm:"index" json:"user_id"` // 用户不存在时为 0
+	Username  string    `gorm:"size:50;index;not null" json:"username"`
+	Ip        string    `gorm:"size:64;index" json:"ip"`
+	UserAgent string    `gorm:"size:255" json:"user_agent"`
+	Reason    string    `gorm:"size:20;not null" json:"reason"`
+	CreatedAt time.Time `gorm:"index" json:"created_at"`
+}
+
+func (LoginFailure) TableName() string {
+	return "login_failures"
+}
@@ -0,0 +1,24 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// Memory 角色记住的关于用户的长期记忆, 按用户和角色隔离
+type Memory struct {
+	Id              int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId          int64          `gorm:"index:idx_memory_owner;not null" json:"user_id"`
+	CharacterId     int64          `gorm:"index:idx_memory_owner;not null" json:"character_id"`
+	Content         string         `gorm:"size:500;not null" json:"content"`
+	Pinned          bool           `gorm:"not null;default:false" json:"pinned"`        // 置顶的记忆每次对话都会注入
+	SourceMessageId int64          `gorm:"not null;default:0" json:"source_message_id"` // 提取来源消息ID
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Memory) TableName() string {
+	return "memories"
+}
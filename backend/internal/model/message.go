@@ -0,0 +1,30 @@
+package model
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 消息角色
+const (
+	MessageRoleSystem    = "system"
+	MessageRoleUser      = "user"
+	MessageRoleAssistant = "assistant"
+)
+
+type Message struct {
+	Id             int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	ConversationId int64          `gorm:"index;not null" json:"conversation_id"`
+	ParentId       int64          `gorm:"index;not null;default:0" json:"parent_id"` // 上一条消息ID, 0 表示会话的第一条消息
+	Role           string         `gorm:"size:20;not null" json:"role"`
+	Content        string         `gorm:"type:text" json:"content"`
+	TokenCount     int            `gorm:"not null;default:0" json:"token_count"` // 保存时计算, 避免每次请求重新分词
+	CreatedAt      time.Time      `json:"created_at"`
+	UpdatedAt      time.Time      `json:"updated_at"`
+	DeletedAt      gorm.DeletedAt `gorm:"index" json:"-"`
+}
+
+func (Message) TableName() string {
+	return "messages"
+}
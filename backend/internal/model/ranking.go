@@ -0,0 +1,29 @@
+package model
+
+import (
+	"time"
+)
+
+// CharacterRanking 公开角色的热度分, 由后台任务定期重新计算
+type CharacterRanking struct {
+	CharacterId int64     `gorm:"primaryKey;autoIncrement:false" json:"character_id"`
+	Score       float64   `gorm:"not null;index" json:"score"`
+	ComputedAt  time.Time `json:"computed_at"`
+}
+
+func (CharacterRanking) TableName() string {
+	return "character_rankings"
+}
+
+// CharacterRecommendation 根据用户聊过的角色推荐的公开角色, 由后台任务定期重新计算
+type CharacterRecommendation struct {
+	UserId            int64     `gorm:"primaryKey;autoIncrement:false" json:"user_id"`
+	CharacterId       int64     `gorm:"primaryKey;autoIncrement:false" json:"character_id"`
+	SourceCharacterId int64     `gorm:"not null" json:"source_character_id"` // 推荐依据: 用户聊过的角色
+	Score             float64   `gorm:"not null" json:"score"`
+	ComputedAt        time.Time `json:"computed_at"`
+}
+
+func (CharacterRecommendation) TableName() string {
+	return "character_recommendations"
+}
@@ -0,0 +1,23 @@
+package model
+
+import (
+	"time"
+)
+
+// RefreshToken 已签发的刷新令牌. 每次刷新都会轮换出新令牌, 同一次登录轮换出的令牌属于同一族
+type RefreshToken struct {
+	Id         int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId     int64      `gorm:"index;not null" json:"user_id"`
+	FamilyId   string     `gorm:"size:32;index;not null" json:"family_id"`
+	TokenHash  string     `gorm:"size:64;uniqueIndex;not null" json:"-"` // 只保存令牌的 SHA-256 摘要
+	DeviceInfo string     `gorm:"size:255" json:"device_info"`           // 签发时客户端的 User-Agent
+	Ip         string     `gorm:"size:64" json:"ip"`
+	ExpiresAt  time.Time  `gorm:"index" json:"expires_at"`
+	RotatedAt  *time.Time `json:"rotated_at"` // 已轮换出新令牌的时间, 之后再次使用视为令牌泄露
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (RefreshToken) TableName() string {
+	return "refresh_tokens"
+}
@@ -0,0 +1,22 @@
+package model
+
+import (
+	"time"
+)
+
+// Session 一次登录, 对应一个刷新令牌族. 访问令牌的 jti 为会话ID, 会话被吊销后其访问令牌立即失效
+type Session struct {
+	Id         string     `gorm:"primaryKey;size:32" json:"id"`
+	UserId     int64      `gorm:"index;not null" json:"user_id"`
+	DeviceName string     `gorm:"size:100" json:"device_name"`
+	UserAgent  string     `gorm:"size:255" json:"user_agent"`
+	Ip         string     `gorm:"size:64" json:"ip"`
+	LastUsedAt time.Time  `json:"last_used_at"`
+	ExpiresAt  time.Time  `json:"expires_at"` // 最新刷新令牌的过期时间
+	RevokedAt  *time.Time `json:"revoked_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+func (Session) TableName() string {
+	return "user_sessions"
+}
@@ -0,0 +1,16 @@
+package model
+
+import (
+	"time"
+)
+
+// Tag 角色标签, 与角色通过 character_tags 表多对多关联
+type Tag struct {
+	Id        int64     `gorm:"primaryKey;autoIncrement" json:"id"`
+	Name      string    `gorm:"size:30;not null;uniqueIndex" json:"name"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+func (Tag) TableName() string {
+	return "tags"
+}
@@ -7,15 +7,17 @@ import (
 )
 
 type User struct {
-	Id        int64          `gorm:"primaryKey;autoIncrement" json:"id"`
-	Username  string         `gorm:"uniqueIndex;size:50;not null" json:"username"`
-	Password  string         `gorm:"size:255;not null" json:"-"`
-	Email     string         `gorm:"size:100" json:"email"`
-	Avatar    string         `gorm:"size:255" json:"avatar"`
-	Profile   string         `gorm:"size:500;not null;default:'谢谢你的关注'" json:"profile"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
-	DeletedAt gorm.DeletedAt `gorm:"index" json:"-"`
+	Id              int64          `gorm:"primaryKey;autoIncrement" json:"id"`
+	Username        string         `gorm:"uniqueIndex;size:50;not null" json:"username"`
+	Password        string         `gorm:"size:255;not null" json:"-"`
+	Email           string         `gorm:"size:100" json:"email"`
+	EmailVerifiedAt *time.Time     `json:"-"` // 邮箱通过验证的时间, 修改邮箱后清空
+	Avatar          string         `gorm:"size:255" json:"avatar"`
+	Profile         string         `gorm:"size:500;not null;default:'谢谢你的关注'" json:"profile"`
+	TokenVersion    int64          `gorm:"not null;default:0" json:"-"` // 写入令牌的版本号, 修改或重置密码时递增, 使之前签发的令牌全部失效
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"-"`
 }
 
 func (User) TableName() string {
@@ -0,0 +1,27 @@
+package model
+
+import (
+	"time"
+)
+
+// 一次性令牌的用途
+const (
+	TokenPurposeVerifyEmail   = "verify_email"
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// UserToken 通过邮件发送的一次性令牌, 用于验证邮箱和重置密码
+type UserToken struct {
+	Id        int64      `gorm:"primaryKey;autoIncrement" json:"id"`
+	UserId    int64      `gorm:"index;not null" json:"user_id"`
+	Purpose   string     `gorm:"size:20;not null" json:"purpose"`
+	TokenHash string     `gorm:"size:64;uniqueIndex;not null" json:"-"` // 只保存令牌的 SHA-256 摘要
+	Email     string     `gorm:"size:100;not null" json:"email"`        // 发送时的邮箱, 之后邮箱被修改则令牌失效
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+func (UserToken) TableName() string {
+	return "user_tokens"
+}
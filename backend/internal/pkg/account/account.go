@@ -0,0 +1,210 @@
+package account
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"net/mail"
+	"net/url"
+	"strings"
+	"time"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/mailer"
+	"aifriend/internal/pkg/session"
+
+	"gorm.io/gorm"
+)
+
+// 邮箱验证和密码重置: 令牌随机生成后通过邮件中的链接发给用户, 数据库只保存摘要.
+// 令牌只能使用一次, 重新发送时同一用途未使用的旧令牌随即失效.
+
+// Config 邮箱验证和密码重置配置
+type Config struct {
+	LinkBaseURL    string `json:",default=http://localhost:3000"` // 邮件中链接指向的前端地址
+	VerifyExpire   int64  `json:",default=86400"`                 // 邮箱验证链接有效期(秒)
+	ResetExpire    int64  `json:",default=1800"`                  // 密码重置链接有效期(秒)
+	ResendInterval int64  `json:",default=60"`                    // 同一用户同类邮件的最短发送间隔(秒)
+}
+
+var (
+	ErrNoEmail      = errors.New("account: user has no email")
+	ErrTooFrequent  = errors.New("account: email sent too frequently")
+	ErrInvalidToken = errors.New("account: invalid, used or expired token")
+)
+
+// Emails 发送验证邮件和重置密码邮件, 并校验邮件中的令牌
+type Emails struct {
+	db     *gorm.DB
+	mailer mailer.Mailer
+	c      Config
+}
+
+func NewEmails(db *gorm.DB, m mailer.Mailer, c Config) *Emails {
+	return &Emails{
+		db:     db,
+		mailer: m,
+		c:      c,
+	}
+}
+
+// ValidEmail 检查是否为不带名称的单个邮件地址
+func ValidEmail(email string) bool {
+	addr, err := mail.ParseAddress(email)
+	return err == nil && addr.Name == "" && addr.Address == email
+}
+
+// SendVerification 向用户当前的邮箱发送验证链接
+func (e *Emails) SendVerification(ctx context.Context, user *model.User) error {
+	return e.send(ctx, user, model.TokenPurposeVerifyEmail, mailer.TemplateVerifyEmail, "/verify-email", e.c.VerifyExpire)
+}
+
+// SendPasswordReset 向用户的邮箱发送重置密码链接
+func (e *Emails) SendPasswordReset(ctx context.Context, user *model.User) error {
+	return e.send(ctx, user, model.TokenPurposeResetPassword, mailer.TemplateResetPassword, "/reset-password", e.c.ResetExpire)
+}
+
+// VerifyEmail 使用验证令牌确认邮箱. 发送后邮箱被修改过的令牌视为无效
+func (e *Emails) VerifyEmail(ctx context.Context, token string) error {
+	return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		t, err := consume(tx, token, model.TokenPurposeVerifyEmail)
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&model.User{}).
+			Where("id = ? AND email = ?", t.UserId, t.Email).
+			UpdateColumn("email_verified_at", time.Now())
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidToken
+		}
+		return nil
+	})
+}
+
+// ResetPassword 使用重置令牌设置新密码, 并使该用户已签发的全部令牌失效.
+// 发送后邮箱被修改过的令牌视为无效. 能收到邮件说明邮箱属于用户, 同时标记为已验证
+func (e *Emails) ResetPassword(ctx context.Context, token, hashedPassword string) error {
+	return e.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		t, err := consume(tx, token, model.TokenPurposeResetPassword)
+		if err != nil {
+			return err
+		}
+		res := tx.Model(&model.User{}).
+			Where("id = ? AND email = ?", t.UserId, t.Email).
+			UpdateColumn("password", hashedPassword)
+		if res.Error != nil {
+			return res.Error
+		}
+		if res.RowsAffected == 0 {
+			return ErrInvalidToken
+		}
+		if err := tx.Model(&model.User{}).
+			Where("id = ? AND email_verified_at IS NULL", t.UserId).
+			UpdateColumn("email_verified_at", time.Now()).Error; err != nil {
+			return err
+		}
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", t.UserId, t.Purpose).
+			Delete(&model.UserToken{}).Error; err != nil {
+			return err
+		}
+		return session.InvalidateUser(tx, t.UserId, "")
+	})
+}
+
+// send 生成令牌并发送邮件. 发送失败时删除令牌, 用户可以立即重试
+func (e *Emails) send(ctx context.Context, user *model.User, purpose, template, path string, expire int64) error {
+	if user.Email == "" {
+		return ErrNoEmail
+	}
+
+	db := e.db.WithContext(ctx)
+	now := time.Now()
+	var recent int64
+	if err := db.Model(&model.UserToken{}).
+		Where("user_id = ? AND purpose = ? AND created_at > ?", user.Id, purpose, now.Add(-time.Duration(e.c.ResendInterval)*time.Second)).
+		Count(&recent).Error; err != nil {
+		return err
+	}
+	if recent > 0 {
+		return ErrTooFrequent
+	}
+
+	token, err := newToken()
+	if err != nil {
+		return err
+	}
+	t := model.UserToken{
+		UserId:    user.Id,
+		Purpose:   purpose,
+		TokenHash: hashToken(token),
+		Email:     user.Email,
+		ExpiresAt: now.Add(time.Duration(expire) * time.Second),
+	}
+	err = db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND purpose = ? AND used_at IS NULL", user.Id, purpose).
+			Delete(&model.UserToken{}).Error; err != nil {
+			return err
+		}
+		return tx.Create(&t).Error
+	})
+	if err != nil {
+		return err
+	}
+
+	msg, err := mailer.Render(template, user.Email, mailer.Data{
+		Username: user.Username,
+		Link:     strings.TrimRight(e.c.LinkBaseURL, "/") + path + "?token=" + url.QueryEscape(token),
+		Expire:   time.Duration(expire) * time.Second,
+	})
+	if err == nil {
+		err = e.mailer.Send(ctx, msg)
+	}
+	if err != nil {
+		db.Delete(&t)
+		return err
+	}
+	return nil
+}
+
+// consume 把令牌标记为已使用并返回. 条件更新保证并发请求中只有一个能使用成功
+func consume(tx *gorm.DB, token, purpose string) (*model.UserToken, error) {
+	if token == "" {
+		return nil, ErrInvalidToken
+	}
+
+	hash := hashToken(token)
+	now := time.Now()
+	res := tx.Model(&model.UserToken{}).
+		Where("token_hash = ? AND purpose = ? AND used_at IS NULL AND expires_at > ?", hash, purpose, now).
+		UpdateColumn("used_at", now)
+	if res.Error != nil {
+		return nil, res.Error
+	}
+	if res.RowsAffected == 0 {
+		return nil, ErrInvalidToken
+	}
+
+	var t model.UserToken
+	if err := tx.Where("token_hash = ?", hash).First(&t).Error; err != nil {
+		return nil, err
+	}
+	return &t, nil
+}
+
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,251 @@
+package account
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"regexp"
+	"sync"
+	"testing"
+	"time"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/mailer"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+var tokenPattern = regexp.MustCompile(`token=([0-9a-f]{64})`)
+
+// outbox 记录发出的邮件
+type outbox struct {
+	mu   sync.Mutex
+	sent []mailer.Message
+	err  error
+}
+
+func (o *outbox) Send(ctx context.Context, msg mailer.Message) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if o.err != nil {
+		return o.err
+	}
+	o.sent = append(o.sent, msg)
+	return nil
+}
+
+// token 返回最近一封邮件链接中的令牌
+func (o *outbox) token(t *testing.T) string {
+	t.Helper()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	if len(o.sent) == 0 {
+		t.Fatalf("no email sent")
+	}
+	m := tokenPattern.FindStringSubmatch(o.sent[len(o.sent)-1].Body)
+	if m == nil {
+		t.Fatalf("no token in email: %s", o.sent[len(o.sent)-1].Body)
+	}
+	return m[1]
+}
+
+func setup(t *testing.T) (*Emails, *outbox, *gorm.DB, *model.User) {
+	t.Helper()
+	db, err := gorm.Open(sqlite.Open(filepath.Join(t.TempDir(), "test.db")), &gorm.Config{
+		Logger: logger.Discard,
+	})
+	if err != nil {
+		t.Fatalf("open db: %v", err)
+	}
+	sqlDB, _ := db.DB()
+	sqlDB.SetMaxOpenConns(1)
+	t.Cleanup(func() { sqlDB.Close() })
+	if err := db.AutoMigrate(&model.User{}, &model.UserToken{}, &model.Session{}, &model.RefreshToken{}); err != nil {
+		t.Fatalf("migrate: %v", err)
+	}
+
+	user := &model.User{Username: "alice", Password: "old", Email: "alice@example.com"}
+	if err := db.Create(user).Error; err != nil {
+		t.Fatalf("create user: %v", err)
+	}
+	box := &outbox{}
+	return NewEmails(db, box, Config{LinkBaseURL: "http://localhost:3000/", VerifyExpire: 3600, ResetExpire: 1800}), box, db, user
+}
+
+func TestResetPassword(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// prepare 发送重置邮件后执行, 返回用于重置的令牌
+		prepare func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string
+		wantErr error
+	}{
+		{
+			name: "有效令牌",
+			prepare: func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string {
+				return box.token(t)
+			},
+		},
+		{
+			name: "令牌只能使用一次",
+			prepare: func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string {
+				token := box.token(t)
+				if err := e.ResetPassword(ctx, token, "first"); err != nil {
+					t.Fatalf("first ResetPassword() error = %v", err)
+				}
+				return token
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "已过期",
+			prepare: func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string {
+				db.Model(&model.UserToken{}).Where("user_id = ?", user.Id).UpdateColumn("expires_at", time.Now().Add(-time.Second))
+				return box.token(t)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "重新发送后旧令牌失效",
+			prepare: func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string {
+				token := box.token(t)
+				if err := e.SendPasswordReset(ctx, user); err != nil {
+					t.Fatalf("resend error = %v", err)
+				}
+				return token
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "发送后邮箱被修改",
+			prepare: func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string {
+				db.Model(user).UpdateColumn("email", "mallory@example.com")
+				return box.token(t)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "邮箱验证令牌",
+			prepare: func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string {
+				if err := e.SendVerification(ctx, user); err != nil {
+					t.Fatalf("SendVerification() error = %v", err)
+				}
+				return box.token(t)
+			},
+			wantErr: ErrInvalidToken,
+		},
+		{
+			name: "空令牌",
+			prepare: func(t *testing.T, e *Emails, box *outbox, db *gorm.DB, user *model.User) string {
+				return ""
+			},
+			wantErr: ErrInvalidToken,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, box, db, user := setup(t)
+			if err := e.SendPasswordReset(ctx, user); err != nil {
+				t.Fatalf("SendPasswordReset() error = %v", err)
+			}
+			token := tt.prepare(t, e, box, db, user)
+			var before model.User
+			db.First(&before, user.Id)
+
+			err := e.ResetPassword(ctx, token, "new")
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ResetPassword() error = %v, want %v", err, tt.wantErr)
+			}
+
+			var after model.User
+			db.First(&after, user.Id)
+			if tt.wantErr != nil {
+				if after.Password != before.Password || after.TokenVersion != before.TokenVersion {
+					t.Errorf("user changed by rejected reset: %+v", after)
+				}
+				return
+			}
+			if after.Password != "new" || after.TokenVersion != before.TokenVersion+1 || after.EmailVerifiedAt == nil {
+				t.Errorf("user after reset = %+v", after)
+			}
+		})
+	}
+}
+
+func TestVerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	e, box, db, user := setup(t)
+
+	if err := e.SendVerification(ctx, user); err != nil {
+		t.Fatalf("SendVerification() error = %v", err)
+	}
+	token := box.token(t)
+	if err := e.VerifyEmail(ctx, token); err != nil {
+		t.Fatalf("VerifyEmail() error = %v", err)
+	}
+	var u model.User
+	db.First(&u, user.Id)
+	if u.EmailVerifiedAt == nil {
+		t.Errorf("email not verified")
+	}
+	if err := e.VerifyEmail(ctx, token); !errors.Is(err, ErrInvalidToken) {
+		t.Errorf("second VerifyEmail() error = %v, want ErrInvalidToken", err)
+	}
+}
+
+func TestSend(t *testing.T) {
+	ctx := context.Background()
+	errSMTP := errors.New("smtp down")
+
+	tests := []struct {
+		name     string
+		prepare  func(e *Emails, box *outbox, user *model.User)
+		wantErr  error
+		wantRows int64 // 之后保存的令牌数
+	}{
+		{name: "发送", wantRows: 1},
+		{
+			name:    "没有邮箱",
+			prepare: func(e *Emails, box *outbox, user *model.User) { user.Email = "" },
+			wantErr: ErrNoEmail,
+		},
+		{
+			name: "发送过于频繁",
+			prepare: func(e *Emails, box *outbox, user *model.User) {
+				e.c.ResendInterval = 60
+				e.SendVerification(ctx, user)
+			},
+			wantErr:  ErrTooFrequent,
+			wantRows: 1,
+		},
+		{
+			name:    "发送失败时删除令牌",
+			prepare: func(e *Emails, box *outbox, user *model.User) { box.err = errSMTP },
+			wantErr: errSMTP,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			e, box, db, user := setup(t)
+			if tt.prepare != nil {
+				tt.prepare(e, box, user)
+			}
+			if err := e.SendVerification(ctx, user); !errors.Is(err, tt.wantErr) {
+				t.Fatalf("SendVerification() error = %v, want %v", err, tt.wantErr)
+			}
+			var rows int64
+			db.Model(&model.UserToken{}).Count(&rows)
+			if rows != tt.wantRows {
+				t.Errorf("%d tokens saved, want %d", rows, tt.wantRows)
+			}
+			if tt.wantErr == nil && box.sent[0].To != user.Email {
+				t.Errorf("sent to %q", box.sent[0].To)
+			}
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package card
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// 角色卡规范标识, 兼容 TavernAI / SillyTavern 等应用
+const (
+	SpecV2        = "chara_card_v2"
+	SpecV2Version = "2.0"
+	specV3        = "chara_card_v3" // V3 的 data 字段与 V2 兼容
+)
+
+var ErrInvalidCard = errors.New("card: invalid character card")
+
+// Card Character Card V2
+type Card struct {
+	Spec        string `json:"spec"`
+	SpecVersion string `json:"spec_version"`
+	Data        Data   `json:"data"`
+}
+
+// Data 角色卡字段, V1 角色卡的字段直接位于顶层
+type Data struct {
+	Name                    string                     `json:"name"`
+	Description             string                     `json:"description"`
+	Personality             string                     `json:"personality"`
+	Scenario                string                     `json:"scenario"`
+	FirstMes                string                     `json:"first_mes"`
+	MesExample              string                     `json:"mes_example"`
+	CreatorNotes            string                     `json:"creator_notes"`
+	SystemPrompt            string                     `json:"system_prompt"`
+	PostHistoryInstructions string                     `json:"post_history_instructions"`
+	AlternateGreetings      []string                   `json:"alternate_greetings"`
+	CharacterBook           json.RawMessage            `json:"character_book,omitempty"`
+	Tags                    []string                   `json:"tags"`
+	Creator                 string                     `json:"creator"`
+	CharacterVersion        string                     `json:"character_version"`
+	Extensions              map[string]json.RawMessage `json:"extensions"`
+}
+
+// New 由角色卡字段创建 V2 角色卡
+func New(data Data) *Card {
+	if data.AlternateGreetings == nil {
+		data.AlternateGreetings = []string{}
+	}
+	if data.Tags == nil {
+		data.Tags = []string{}
+	}
+	if data.Extensions == nil {
+		data.Extensions = map[string]json.RawMessage{}
+	}
+	return &Card{
+		Spec:        SpecV2,
+		SpecVersion: SpecV2Version,
+		Data:        data,
+	}
+}
+
+// Parse 解析 V2 角色卡, 没有 spec 字段时按 V1 角色卡解析. 角色名为空时返回 ErrInvalidCard
+func Parse(raw []byte) (*Card, error) {
+	var header struct {
+		Spec string `json:"spec"`
+	}
+	if err := json.Unmarshal(raw, &header); err != nil {
+		return nil, ErrInvalidCard
+	}
+
+	var data Data
+	switch header.Spec {
+	case SpecV2, specV3:
+		var c Card
+		if err := json.Unmarshal(raw, &c); err != nil {
+			return nil, ErrInvalidCard
+		}
+		data = c.Data
+	case "":
+		if err := json.Unmarshal(raw, &data); err != nil {
+			return nil, ErrInvalidCard
+		}
+	default:
+		return nil, ErrInvalidCard
+	}
+
+	if strings.TrimSpace(data.Name) == "" {
+		return nil, ErrInvalidCard
+	}
+	return New(data), nil
+}
+
+// Extension 读取扩展字段中 key 对应的内容, 不存在时返回 false
+func (c *Card) Extension(key string, v interface{}) (bool, error) {
+	raw, ok := c.Data.Extensions[key]
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+// SetExtension 写入扩展字段
+func (c *Card) SetExtension(key string, v interface{}) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	if c.Data.Extensions == nil {
+		c.Data.Extensions = map[string]json.RawMessage{}
+	}
+	c.Data.Extensions[key] = raw
+	return nil
+}
@@ -0,0 +1,132 @@
+package card
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"hash/crc32"
+)
+
+// cardKeyword 保存角色卡的 PNG 文本块关键字, 内容为 base64 编码的角色卡 JSON
+const cardKeyword = "chara"
+
+var pngSignature = []byte("\x89PNG\r\n\x1a\n")
+
+var (
+	ErrInvalidPNG = errors.New("card: invalid png")
+	ErrNoCard     = errors.New("card: png has no character card")
+)
+
+// chunk PNG 数据块
+type chunk struct {
+	typ  string
+	data []byte
+}
+
+// readChunks 按顺序读取 PNG 的所有数据块
+func readChunks(png []byte) ([]chunk, error) {
+	if !bytes.HasPrefix(png, pngSignature) {
+		return nil, ErrInvalidPNG
+	}
+
+	var chunks []chunk
+	rest := png[len(pngSignature):]
+	for len(rest) > 0 {
+		if len(rest) < 12 {
+			return nil, ErrInvalidPNG
+		}
+		length := binary.BigEndian.Uint32(rest[:4])
+		if uint64(length) > uint64(len(rest)-12) {
+			return nil, ErrInvalidPNG
+		}
+		typ := string(rest[4:8])
+		chunks = append(chunks, chunk{typ: typ, data: rest[8 : 8+length]})
+		rest = rest[12+length:]
+		if typ == "IEND" {
+			return chunks, nil
+		}
+	}
+	return nil, ErrInvalidPNG
+}
+
+// Extract 从 PNG 的 tEXt 或未压缩的 iTXt 块中取出角色卡 JSON
+func Extract(png []byte) ([]byte, error) {
+	chunks, err := readChunks(png)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, c := range chunks {
+		var text []byte
+		switch c.typ {
+		case "tEXt":
+			keyword, value, ok := bytes.Cut(c.data, []byte{0})
+			if !ok || string(keyword) != cardKeyword {
+				continue
+			}
+			text = value
+		case "iTXt":
+			// 关键字\0 压缩标志 压缩方法 语言\0 翻译关键字\0 文本
+			keyword, value, ok := bytes.Cut(c.data, []byte{0})
+			if !ok || string(keyword) != cardKeyword || len(value) < 2 || value[0] != 0 {
+				continue
+			}
+			parts := bytes.SplitN(value[2:], []byte{0}, 3)
+			if len(parts) != 3 {
+				continue
+			}
+			text = parts[2]
+		default:
+			continue
+		}
+
+		text = bytes.TrimSpace(text)
+		decoded, err := base64.StdEncoding.DecodeString(string(text))
+		if err != nil {
+			if decoded, err = base64.RawStdEncoding.DecodeString(string(bytes.TrimRight(text, "="))); err != nil {
+				return nil, ErrNoCard
+			}
+		}
+		return decoded, nil
+	}
+	return nil, ErrNoCard
+}
+
+// Embed 把角色卡 JSON 以 base64 写入 PNG 的 tEXt 块, 原有的角色卡块会被替换
+func Embed(png []byte, cardJSON []byte) ([]byte, error) {
+	chunks, err := readChunks(png)
+	if err != nil {
+		return nil, err
+	}
+
+	text := append([]byte(cardKeyword+"\x00"), base64.StdEncoding.EncodeToString(cardJSON)...)
+
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	for _, c := range chunks {
+		if c.typ == "tEXt" || c.typ == "iTXt" {
+			if keyword, _, _ := bytes.Cut(c.data, []byte{0}); string(keyword) == cardKeyword {
+				continue
+			}
+		}
+		if c.typ == "IEND" {
+			writeChunk(&buf, "tEXt", text)
+		}
+		writeChunk(&buf, c.typ, c.data)
+	}
+	return buf.Bytes(), nil
+}
+
+func writeChunk(buf *bytes.Buffer, typ string, data []byte) {
+	var header [8]byte
+	binary.BigEndian.PutUint32(header[:4], uint32(len(data)))
+	copy(header[4:], typ)
+	buf.Write(header[:])
+	buf.Write(data)
+
+	crc := crc32.NewIEEE()
+	crc.Write(header[4:])
+	crc.Write(data)
+	binary.Write(buf, binary.BigEndian, crc.Sum32())
+}
@@ -0,0 +1,165 @@
+package card
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"image"
+	"image/color"
+	"image/png"
+	"testing"
+)
+
+func testPNG(t *testing.T) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, 4, 4))
+	img.Set(1, 2, color.RGBA{R: 255, A: 255})
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatalf("png.Encode() error = %v", err)
+	}
+	return buf.Bytes()
+}
+
+// withChunk 在 IEND 之前插入一个数据块
+func withChunk(t *testing.T, src []byte, typ string, data []byte) []byte {
+	t.Helper()
+	chunks, err := readChunks(src)
+	if err != nil {
+		t.Fatalf("readChunks() error = %v", err)
+	}
+	var buf bytes.Buffer
+	buf.Write(pngSignature)
+	for _, c := range chunks {
+		if c.typ == "IEND" {
+			writeChunk(&buf, typ, data)
+		}
+		writeChunk(&buf, c.typ, c.data)
+	}
+	return buf.Bytes()
+}
+
+func TestEmbedExtract(t *testing.T) {
+	base := testPNG(t)
+
+	tests := []struct {
+		name string
+		png  []byte
+		card string
+	}{
+		{name: "普通图片", png: base, card: `{"spec":"chara_card_v2","data":{"name":"小明"}}`},
+		{name: "替换已有角色卡", png: withChunk(t, base, "tEXt", append([]byte("chara\x00"), base64.StdEncoding.EncodeToString([]byte(`{"name":"旧"}`))...)), card: `{"name":"新"}`},
+		{name: "保留其他文本块", png: withChunk(t, base, "tEXt", []byte("Comment\x00hello")), card: `{"name":"a"}`},
+		{name: "空角色卡", png: base, card: ``},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			out, err := Embed(tt.png, []byte(tt.card))
+			if err != nil {
+				t.Fatalf("Embed() error = %v", err)
+			}
+			got, err := Extract(out)
+			if err != nil {
+				t.Fatalf("Extract() error = %v", err)
+			}
+			if string(got) != tt.card {
+				t.Errorf("Extract() = %s, want %s", got, tt.card)
+			}
+
+			// 写入后仍是有效的图片, 只有一个角色卡块, 其他数据块原样保留
+			if _, err := png.Decode(bytes.NewReader(out)); err != nil {
+				t.Errorf("png.Decode() error = %v", err)
+			}
+			before, _ := readChunks(tt.png)
+			after, _ := readChunks(out)
+			cards := 0
+			var others [][]byte
+			for _, c := range after {
+				if c.typ == "tEXt" && bytes.HasPrefix(c.data, []byte(cardKeyword+"\x00")) {
+					cards++
+					continue
+				}
+				others = append(others, c.data)
+			}
+			if cards != 1 {
+				t.Errorf("%d card chunks, want 1", cards)
+			}
+			i := 0
+			for _, c := range before {
+				if c.typ == "tEXt" && bytes.HasPrefix(c.data, []byte(cardKeyword+"\x00")) {
+					continue
+				}
+				if i >= len(others) || !bytes.Equal(others[i], c.data) {
+					t.Fatalf("chunk %s not preserved", c.typ)
+				}
+				i++
+			}
+		})
+	}
+}
+
+func TestExtract(t *testing.T) {
+	base := testPNG(t)
+	card := []byte(`{"name":"小明"}`)
+	encoded := base64.StdEncoding.EncodeToString(card)
+
+	tests := []struct {
+		name    string
+		png     []byte
+		want    []byte
+		wantErr error
+	}{
+		{
+			name: "iTXt 块",
+			png:  withChunk(t, base, "iTXt", []byte("chara\x00\x00\x00zh\x00\x00"+encoded)),
+			want: card,
+		},
+		{
+			name: "缺少填充",
+			png:  withChunk(t, base, "tEXt", []byte("chara\x00"+base64.RawStdEncoding.EncodeToString(card))),
+			want: card,
+		},
+		{
+			name: "首尾空白",
+			png:  withChunk(t, base, "tEXt", []byte("chara\x00 "+encoded+"\n")),
+			want: card,
+		},
+		{
+			name:    "压缩的 iTXt 块",
+			png:     withChunk(t, base, "iTXt", []byte("chara\x00\x01\x00\x00\x00"+encoded)),
+			wantErr: ErrNoCard,
+		},
+		{
+			name:    "其他关键字",
+			png:     withChunk(t, base, "tEXt", []byte("Comment\x00"+encoded)),
+			wantErr: ErrNoCard,
+		},
+		{
+			name:    "不是 base64",
+			png:     withChunk(t, base, "tEXt", []byte("chara\x00!!!")),
+			wantErr: ErrNoCard,
+		},
+		{name: "没有角色卡", png: base, wantErr: ErrNoCard},
+		{name: "不是 PNG", png: []byte("GIF89a"), wantErr: ErrInvalidPNG},
+		{name: "截断", png: base[:len(base)-6], wantErr: ErrInvalidPNG},
+		{name: "缺少 IEND", png: base[:len(base)-12], wantErr: ErrInvalidPNG},
+		{name: "数据块长度越界", png: append(append([]byte{}, pngSignature...), 0xff, 0xff, 0xff, 0xff, 'I', 'H', 'D', 'R', 0, 0, 0, 0), wantErr: ErrInvalidPNG},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Extract(tt.png)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Extract() error = %v, want %v", err, tt.wantErr)
+			}
+			if !bytes.Equal(got, tt.want) {
+				t.Errorf("Extract() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := Embed([]byte("GIF89a"), card); !errors.Is(err, ErrInvalidPNG) {
+		t.Errorf("Embed() invalid png error = %v", err)
+	}
+}
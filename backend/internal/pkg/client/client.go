@@ -0,0 +1,156 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/netip"
+	"strings"
+)
+
+// maxUserAgentLength 保存的 User-Agent 最大字节数
+const maxUserAgentLength = 255
+
+type contextKey struct{}
+
+// Config 客户端地址配置
+type Config struct {
+	TrustedProxies []string `json:",optional"` // 可信反向代理的地址或网段, 直连地址属于其中时才读取 X-Forwarded-For
+}
+
+// Info 发起请求的客户端
+type Info struct {
+	Ip        string
+	UserAgent string
+}
+
+// Resolver 从请求中解析客户端信息. X-Forwarded-For 可以由客户端任意伪造,
+// 只有请求来自可信代理时才使用, 并从右向左跳过可信代理取第一个地址
+type Resolver struct {
+	proxies []netip.Prefix
+}
+
+func NewResolver(c Config) (*Resolver, error) {
+	r := &Resolver{}
+	for _, p := range c.TrustedProxies {
+		prefix, err := netip.ParsePrefix(p)
+		if err != nil {
+			addr, err := netip.ParseAddr(p)
+			if err != nil {
+				return nil, fmt.Errorf("client: invalid trusted proxy %q", p)
+			}
+			prefix = netip.PrefixFrom(addr.Unmap(), addr.Unmap().BitLen())
+		}
+		r.proxies = append(r.proxies, prefix.Masked())
+	}
+	return r, nil
+}
+
+// FromRequest 从请求中读取客户端地址和 User-Agent
+func (r *Resolver) FromRequest(req *http.Request) Info {
+	userAgent := req.UserAgent()
+	if len(userAgent) > maxUserAgentLength {
+		userAgent = userAgent[:maxUserAgentLength]
+	}
+	var ip string
+	if addr, ok := r.remoteAddr(req); ok {
+		ip = addr.String()
+	}
+	return Info{
+		Ip:        ip,
+		UserAgent: userAgent,
+	}
+}
+
+// remoteAddr 返回客户端地址. 经过的可信代理按顺序追加在 X-Forwarded-For 末尾,
+// 遇到第一个不可信或无法解析的地址时停止, 之前的一跳就是客户端
+func (r *Resolver) remoteAddr(req *http.Request) (netip.Addr, bool) {
+	addrPort, err := netip.ParseAddrPort(req.RemoteAddr)
+	if err != nil {
+		return netip.Addr{}, false
+	}
+	addr := addrPort.Addr().Unmap().WithZone("")
+	if !r.trusted(addr) {
+		return addr, true
+	}
+
+	hops := strings.Split(strings.Join(req.Header.Values("X-Forwarded-For"), ","), ",")
+	for i := len(hops) - 1; i >= 0; i-- {
+		hop, err := netip.ParseAddr(strings.TrimSpace(hops[i]))
+		if err != nil {
+			break
+		}
+		addr = hop.Unmap().WithZone("")
+		if !r.trusted(addr) {
+			break
+		}
+	}
+	return addr, true
+}
+
+func (r *Resolver) trusted(addr netip.Addr) bool {
+	for _, p := range r.proxies {
+		if p.Contains(addr) {
+			return true
+		}
+	}
+	return false
+}
+
+func NewContext(ctx context.Context, info Info) context.Context {
+	return context.WithValue(ctx, contextKey{}, info)
+}
+
+// FromContext 返回 ClientInfo 中间件保存的客户端信息, 未经过中间件时返回零值
+func FromContext(ctx context.Context) Info {
+	info, _ := ctx.Value(contextKey{}).(Info)
+	return info
+}
+
+// 按顺序匹配, 先匹配的优先: 如 Edge 和 Chrome 的 User-Agent 都包含 Chrome
+var (
+	browsers = []struct{ token, name string }{
+		{"MicroMessenger", "微信"},
+		{"Edg/", "Edge"},
+		{"OPR/", "Opera"},
+		{"Firefox/", "Firefox"},
+		{"Chrome/", "Chrome"},
+		{"Safari/", "Safari"},
+	}
+	systems = []struct{ token, name string }{
+		{"iPhone", "iPhone"},
+		{"iPad", "iPad"},
+		{"Android", "Android"},
+		{"Windows", "Windows"},
+		{"Mac OS X", "macOS"},
+		{"Linux", "Linux"},
+	}
+)
+
+// DeviceName 从 User-Agent 推断便于识别的设备名称, 如 "Chrome on Windows"
+func DeviceName(userAgent string) string {
+	browser, system := "", ""
+	for _, b := range browsers {
+		if strings.Contains(userAgent, b.token) {
+			browser = b.name
+			break
+		}
+	}
+	for _, s := range systems {
+		if strings.Contains(userAgent, s.token) {
+			system = s.name
+			break
+		}
+	}
+
+	switch {
+	case browser != "" && system != "":
+		return browser + " on " + system
+	case browser != "":
+		return browser
+	case system != "":
+		return system
+	default:
+		return "未知设备"
+	}
+}
@@ -0,0 +1,97 @@
+package client
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestFromRequest(t *testing.T) {
+	r, err := NewResolver(Config{TrustedProxies: []string{"10.0.0.0/8", "::1", "192.168.1.1"}})
+	if err != nil {
+		t.Fatalf("NewResolver() error = %v", err)
+	}
+
+	tests := []struct {
+		name       string
+		remoteAddr string
+		xff        []string
+		want       string
+	}{
+		{name: "直连", remoteAddr: "203.0.113.5:1234", want: "203.0.113.5"},
+		{name: "直连时忽略伪造的头", remoteAddr: "203.0.113.5:1234", xff: []string{"1.2.3.4"}, want: "203.0.113.5"},
+		{name: "经过一层代理", remoteAddr: "10.0.0.2:80", xff: []string{"203.0.113.5"}, want: "203.0.113.5"},
+		{name: "客户端在头中伪造地址", remoteAddr: "10.0.0.2:80", xff: []string{"1.2.3.4, 203.0.113.5"}, want: "203.0.113.5"},
+		{name: "经过多层代理", remoteAddr: "10.0.0.2:80", xff: []string{"203.0.113.5, 192.168.1.1", "10.1.1.1"}, want: "203.0.113.5"},
+		{name: "全部是可信代理", remoteAddr: "10.0.0.2:80", xff: []string{"10.0.0.3"}, want: "10.0.0.3"},
+		{name: "无法解析的地址", remoteAddr: "10.0.0.2:80", xff: []string{"1.2.3.4, unknown"}, want: "10.0.0.2"},
+		{name: "带端口的地址", remoteAddr: "10.0.0.2:80", xff: []string{"203.0.113.5:4000"}, want: "10.0.0.2"},
+		{name: "没有转发头", remoteAddr: "10.0.0.2:80", want: "10.0.0.2"},
+		{name: "IPv6 代理", remoteAddr: "[::1]:80", xff: []string{"2001:db8::1"}, want: "2001:db8::1"},
+		{name: "IPv4 映射地址", remoteAddr: "[::ffff:10.0.0.2]:80", xff: []string{"::ffff:203.0.113.5"}, want: "203.0.113.5"},
+		{name: "去掉 zone", remoteAddr: "[fe80::1%eth0]:80", want: "fe80::1"},
+		{name: "无法解析的直连地址", remoteAddr: "pipe", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest("GET", "/", nil)
+			req.RemoteAddr = tt.remoteAddr
+			for _, v := range tt.xff {
+				req.Header.Add("X-Forwarded-For", v)
+			}
+			if got := r.FromRequest(req).Ip; got != tt.want {
+				t.Errorf("FromRequest().Ip = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewResolver(t *testing.T) {
+	tests := []struct {
+		name    string
+		proxies []string
+		wantErr bool
+	}{
+		{name: "未配置"},
+		{name: "地址和网段", proxies: []string{"127.0.0.1", "10.0.0.0/8", "fd00::/8"}},
+		{name: "无效地址", proxies: []string{"localhost"}, wantErr: true},
+		{name: "无效网段", proxies: []string{"10.0.0.0/33"}, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := NewResolver(Config{TrustedProxies: tt.proxies}); (err != nil) != tt.wantErr {
+				t.Errorf("NewResolver() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestUserAgent(t *testing.T) {
+	req := httptest.NewRequest("GET", "/", nil)
+	req.Header.Set("User-Agent", strings.Repeat("a", 1000))
+	if got := len(new(Resolver).FromRequest(req).UserAgent); got != maxUserAgentLength {
+		t.Errorf("UserAgent length = %d, want %d", got, maxUserAgentLength)
+	}
+}
+
+func TestDeviceName(t *testing.T) {
+	tests := []struct {
+		userAgent string
+		want      string
+	}{
+		{"Mozilla/5.0 (Windows NT 10.0; Win64; x64) AppleWebKit/537.36 (KHTML, like Gecko) Chrome/120.0 Safari/537.36 Edg/120.0", "Edge on Windows"},
+		{"Mozilla/5.0 (Macintosh; Intel Mac OS X 10_15_7) AppleWebKit/605.1.15 (KHTML, like Gecko) Version/17.0 Safari/605.1.15", "Safari on macOS"},
+		{"Mozilla/5.0 (iPhone; CPU iPhone OS 17_0 like Mac OS X) AppleWebKit/605.1.15 MicroMessenger/8.0", "微信 on iPhone"},
+		{"Mozilla/5.0 (X11; Linux x86_64; rv:120.0) Gecko/20100101 Firefox/120.0", "Firefox on Linux"},
+		{"curl/8.0", "未知设备"},
+		{"", "未知设备"},
+	}
+
+	for _, tt := range tests {
+		if got := DeviceName(tt.userAgent); got != tt.want {
+			t.Errorf("DeviceName(%q) = %q, want %q", tt.userAgent, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,111 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// 游标分页: 列表按 (时间列, id) 降序排列, 时间列如 created_at 或 updated_at,
+// 游标记录上一页最后一条记录的这两个字段, 下一页从它之后开始.
+// 翻页期间有记录新增或删除时不会像偏移分页那样重复或遗漏.
+
+const (
+	DefaultPageSize = 20
+	MaxPageSize     = 100
+)
+
+var ErrInvalidCursor = errors.New("cursor: invalid token")
+
+// Cursor 列表中的一个位置
+type Cursor struct {
+	Time time.Time // 排序的时间列的值
+	Id   int64
+}
+
+// Encode 编码为客户端原样传回的不透明字符串
+func (c Cursor) Encode() string {
+	raw := strconv.FormatInt(c.Time.UnixNano(), 10) + ":" + strconv.FormatInt(c.Id, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+// Decode 解析 Encode 生成的字符串
+func Decode(token string) (Cursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return Cursor{}, ErrInvalidCursor
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return Cursor{}, ErrInvalidCursor
+	}
+	c := Cursor{Time: time.Unix(0, n)}
+	if c.Id, err = strconv.ParseInt(id, 10, 64); err != nil || c.Id <= 0 {
+		return Cursor{}, ErrInvalidCursor
+	}
+	return c, nil
+}
+
+// Page 一次分页查询的参数
+type Page struct {
+	After *Cursor // 为 nil 时从第一条开始
+	Size  int
+}
+
+// Size 规范化每页数量, 未指定时为 DefaultPageSize, 不超过 MaxPageSize.
+// 仍使用页码分页的列表也使用同一上限
+func Size(size int) int {
+	if size < 1 {
+		return DefaultPageSize
+	}
+	return min(size, MaxPageSize)
+}
+
+// Parse 解析请求中的游标和每页数量, 每页数量不超过 MaxPageSize
+func Parse(token string, size int) (Page, error) {
+	p := Page{Size: Size(size)}
+	if token != "" {
+		c, err := Decode(token)
+		if err != nil {
+			return Page{}, err
+		}
+		p.After = &c
+	}
+	return p, nil
+}
+
+// Scope 按 table 的 (column, id) 降序查询游标之后的记录, column 为时间列.
+// 多取一条用于判断是否还有下一页
+func (p Page) Scope(table, column string) func(*gorm.DB) *gorm.DB {
+	return p.ScopeColumns(table+"."+column, table+".id")
+}
+
+// ScopeColumns 与 Scope 相同, 时间列和ID列可以来自不同的表, 如按收藏时间排列角色.
+// 两列组合后必须唯一
+func (p Page) ScopeColumns(timeColumn, idColumn string) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		if p.After != nil {
+			db = db.Where(fmt.Sprintf("(%[1]s < ? OR (%[1]s = ? AND %[2]s < ?))", timeColumn, idColumn),
+				p.After.Time, p.After.Time, p.After.Id)
+		}
+		return db.Order(timeColumn + " DESC").Order(idColumn + " DESC").Limit(p.Size + 1)
+	}
+}
+
+// Trim 去掉 Scope 多取的一条, 返回本页的记录、下一页的游标和是否还有下一页
+func Trim[T any](p Page, rows []T, key func(*T) Cursor) ([]T, string, bool) {
+	if len(rows) <= p.Size {
+		return rows, "", false
+	}
+	rows = rows[:p.Size]
+	return rows, key(&rows[len(rows)-1]).Encode(), true
+}
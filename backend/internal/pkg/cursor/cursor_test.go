@@ -0,0 +1,144 @@
+package cursor
+
+import (
+	"encoding/base64"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestEncodeDecode(t *testing.T) {
+	tests := []struct {
+		name string
+		c    Cursor
+	}{
+		{name: "纳秒精度", c: Cursor{Time: time.Date(2024, 5, 1, 8, 30, 0, 123456789, time.UTC), Id: 42}},
+		{name: "Unix 起点之前", c: Cursor{Time: time.Date(1960, 1, 1, 0, 0, 0, 0, time.UTC), Id: 1}},
+		{name: "最大ID", c: Cursor{Time: time.Unix(1700000000, 0), Id: 1<<63 - 1}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := Decode(tt.c.Encode())
+			if err != nil {
+				t.Fatalf("Decode() error = %v", err)
+			}
+			if !got.Time.Equal(tt.c.Time) || got.Id != tt.c.Id {
+				t.Errorf("Decode() = %+v, want %+v", got, tt.c)
+			}
+		})
+	}
+}
+
+func TestDecodeInvalid(t *testing.T) {
+	valid := Cursor{Time: time.Unix(1700000000, 0), Id: 42}.Encode()
+	encode := func(raw string) string { return base64.RawURLEncoding.EncodeToString([]byte(raw)) }
+
+	tests := []struct {
+		name  string
+		token string
+	}{
+		{name: "空字符串", token: ""},
+		{name: "非 base64", token: "bad!"},
+		{name: "带填充", token: base64.URLEncoding.EncodeToString([]byte("1700000000000000000:42"))},
+		{name: "标准 base64 字符", token: valid[:2] + "+" + valid[3:]},
+		{name: "截断", token: valid[:len(valid)-1]},
+		{name: "追加字符", token: valid + "A"},
+		{name: "缺少分隔符", token: encode("170000000000000000042")},
+		{name: "时间非数字", token: encode("abc:42")},
+		{name: "时间溢出", token: encode("99999999999999999999:42")},
+		{name: "ID非数字", token: encode("1700000000000000000:abc")},
+		{name: "ID为0", token: encode("1700000000000000000:0")},
+		{name: "ID为负数", token: encode("1700000000000000000:-1")},
+		{name: "多余字段", token: encode("1700000000000000000:42:1")},
+		{name: "SQL注入", token: encode("1700000000000000000:42 OR 1=1")},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if c, err := Decode(tt.token); !errors.Is(err, ErrInvalidCursor) {
+				t.Errorf("Decode(%q) = %+v, %v, want ErrInvalidCursor", tt.token, c, err)
+			}
+		})
+	}
+}
+
+func TestParse(t *testing.T) {
+	valid := Cursor{Time: time.Unix(1700000000, 0), Id: 42}
+
+	tests := []struct {
+		name     string
+		token    string
+		size     int
+		wantSize int
+		wantErr  bool
+	}{
+		{name: "默认每页数量", size: 0, wantSize: DefaultPageSize},
+		{name: "负数", size: -1, wantSize: DefaultPageSize},
+		{name: "指定每页数量", size: 5, wantSize: 5},
+		{name: "超过上限", size: 1000, wantSize: MaxPageSize},
+		{name: "带游标", token: valid.Encode(), size: 10, wantSize: 10},
+		{name: "无效游标", token: "bad!", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p, err := Parse(tt.token, tt.size)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("Parse() error = %v, wantErr %v", err, tt.wantErr)
+			}
+			if tt.wantErr {
+				return
+			}
+			if p.Size != tt.wantSize {
+				t.Errorf("Size = %d, want %d", p.Size, tt.wantSize)
+			}
+			if (p.After != nil) != (tt.token != "") {
+				t.Errorf("After = %+v, token %q", p.After, tt.token)
+			}
+			if p.After != nil && (!p.After.Time.Equal(valid.Time) || p.After.Id != valid.Id) {
+				t.Errorf("After = %+v, want %+v", *p.After, valid)
+			}
+		})
+	}
+}
+
+func TestTrim(t *testing.T) {
+	key := func(id *int64) Cursor { return Cursor{Time: time.Unix(*id, 0), Id: *id} }
+
+	tests := []struct {
+		name     string
+		rows     []int64
+		size     int
+		want     int
+		wantMore bool
+	}{
+		{name: "空列表", rows: nil, size: 2, want: 0},
+		{name: "不足一页", rows: []int64{3}, size: 2, want: 1},
+		{name: "恰好一页", rows: []int64{3, 2}, size: 2, want: 2},
+		{name: "多取一条", rows: []int64{3, 2, 1}, size: 2, want: 2, wantMore: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rows, next, more := Trim(Page{Size: tt.size}, tt.rows, key)
+			if len(rows) != tt.want || more != tt.wantMore {
+				t.Fatalf("Trim() = %v, %v, want %d rows, more %v", rows, more, tt.want, tt.wantMore)
+			}
+			if !more {
+				if next != "" {
+					t.Errorf("next = %q without more", next)
+				}
+				return
+			}
+			c, err := Decode(next)
+			if err != nil {
+				t.Fatalf("Decode(next) error = %v", err)
+			}
+			// 下一页从本页最后一条之后开始
+			if last := rows[len(rows)-1]; c.Id != last {
+				t.Errorf("next cursor id = %d, want %d", c.Id, last)
+			}
+		})
+	}
+}
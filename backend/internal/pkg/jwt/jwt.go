@@ -1,6 +1,8 @@
 package jwt
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"time"
 
 	"github.com/golang-jwt/jwt/v5"
@@ -9,15 +11,28 @@ import (
 type Claims struct {
 	UserId   int64  `json:"user_id"`
 	Username string `json:"username"`
+	// TokenVersion 签发时用户的令牌版本, 与用户当前版本不一致的令牌视为失效
+	TokenVersion int64 `json:"token_version"`
 	jwt.RegisteredClaims
 }
 
-// GenerateToken 生成访问令牌
-func GenerateToken(userId int64, username string, secret string, expireSeconds int64) (string, error) {
+// NewTokenId 生成随机的令牌ID, 写入 jti 声明使每个令牌都不相同
+func NewTokenId() (string, error) {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// GenerateToken 生成令牌
+func GenerateToken(userId int64, username string, tokenVersion int64, tokenId string, secret string, expireSeconds int64) (string, error) {
 	claims := Claims{
-		UserId:   userId,
-		Username: username,
+		UserId:       userId,
+		Username:     username,
+		TokenVersion: tokenVersion,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        tokenId,
 			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Duration(expireSeconds) * time.Second)),
 			IssuedAt:  jwt.NewNumericDate(time.Now()),
 			NotBefore: jwt.NewNumericDate(time.Now()),
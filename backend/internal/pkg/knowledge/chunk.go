@@ -0,0 +1,106 @@
+package knowledge
+
+import (
+	"regexp"
+	"strings"
+
+	"aifriend/internal/pkg/tokenizer"
+)
+
+var paragraphSeparator = regexp.MustCompile(`\n\s*\n`)
+
+// Split 把文本切分为不超过 size 个 token 的片段, 相邻片段之间保留约 overlap 个 token 的重叠.
+// 优先在段落边界切分, 段落过长时按句子切分, 单句过长时按字符硬切.
+func Split(text string, size, overlap int, tok tokenizer.Tokenizer) []string {
+	if size <= 0 {
+		return nil
+	}
+
+	var segments []string
+	for _, paragraph := range paragraphSeparator.Split(text, -1) {
+		paragraph = strings.TrimSpace(paragraph)
+		if paragraph == "" {
+			continue
+		}
+		if tok.Count(paragraph) <= size {
+			segments = append(segments, paragraph)
+			continue
+		}
+		for _, sentence := range sentences(paragraph) {
+			if tok.Count(sentence) <= size {
+				segments = append(segments, sentence)
+			} else {
+				segments = append(segments, hardSplit(sentence, size, tok)...)
+			}
+		}
+	}
+
+	var chunks []string
+	var current []string
+	used := 0
+	for _, segment := range segments {
+		n := tok.Count(segment)
+		if used+n > size && len(current) > 0 {
+			chunks = append(chunks, strings.Join(current, "\n"))
+			current, used = tail(current, overlap, size-n, tok)
+		}
+		current = append(current, segment)
+		used += n
+	}
+	if len(current) > 0 {
+		chunks = append(chunks, strings.Join(current, "\n"))
+	}
+	return chunks
+}
+
+// tail 取末尾总量不超过 overlap 和 room 的若干片段, 作为下一个片段的开头
+func tail(segments []string, overlap, room int, tok tokenizer.Tokenizer) ([]string, int) {
+	limit := min(overlap, room)
+	used := 0
+	start := len(segments)
+	for start > 0 {
+		n := tok.Count(segments[start-1])
+		if used+n > limit {
+			break
+		}
+		used += n
+		start--
+	}
+	return append([]string(nil), segments[start:]...), used
+}
+
+// sentences 在句末标点和换行处切分, 标点保留在句子末尾
+func sentences(text string) []string {
+	var result []string
+	var sb strings.Builder
+	for _, r := range text {
+		sb.WriteRune(r)
+		switch r {
+		case '。', '！', '？', '；', '!', '?', ';', '.', '\n':
+			if s := strings.TrimSpace(sb.String()); s != "" {
+				result = append(result, s)
+			}
+			sb.Reset()
+		}
+	}
+	if s := strings.TrimSpace(sb.String()); s != "" {
+		result = append(result, s)
+	}
+	return result
+}
+
+func hardSplit(text string, size int, tok tokenizer.Tokenizer) []string {
+	var result []string
+	var piece []rune
+	for _, r := range text {
+		piece = append(piece, r)
+		if tok.Count(string(piece)) >= size {
+			result = append(result, string(piece))
+			piece = piece[:0]
+		}
+	}
+	if len(piece) > 0 {
+		result = append(result, string(piece))
+	}
+	return result
+}
@@ -0,0 +1,75 @@
+package knowledge
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"os"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/ledongthuc/pdf"
+)
+
+var (
+	ErrUnsupportedType = errors.New("knowledge: unsupported document type")
+	ErrInvalidEncoding = errors.New("knowledge: document is not valid utf-8")
+	ErrEmptyDocument   = errors.New("knowledge: document has no text")
+)
+
+// ExtractText 读取文档的纯文本内容. 支持 UTF-8 编码的 TXT、Markdown 以及带文本层的 PDF.
+func ExtractText(path, contentType string) (string, error) {
+	var text string
+	switch contentType {
+	case "text/plain", "text/markdown":
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return "", err
+		}
+		data = bytes.TrimPrefix(data, []byte("\xef\xbb\xbf"))
+		if !utf8.Valid(data) {
+			return "", ErrInvalidEncoding
+		}
+		text = string(data)
+	case "application/pdf":
+		var err error
+		if text, err = extractPDF(path); err != nil {
+			return "", err
+		}
+	default:
+		return "", ErrUnsupportedType
+	}
+
+	text = strings.TrimSpace(strings.ReplaceAll(text, "\r\n", "\n"))
+	if text == "" {
+		return "", ErrEmptyDocument
+	}
+	return text, nil
+}
+
+// extractPDF 提取 PDF 的文本层, 扫描件等没有文本层的 PDF 返回空字符串
+func extractPDF(path string) (text string, err error) {
+	// 解析库遇到损坏的文件可能直接 panic
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("knowledge: malformed pdf")
+		}
+	}()
+
+	file, reader, err := pdf.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer file.Close()
+
+	plain, err := reader.GetPlainText()
+	if err != nil {
+		return "", err
+	}
+
+	data, err := io.ReadAll(plain)
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
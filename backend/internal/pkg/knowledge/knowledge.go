@@ -0,0 +1,251 @@
+package knowledge
+
+import (
+	"context"
+	"errors"
+	"sort"
+	"strings"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/pkg/tokenizer"
+	"aifriend/internal/pkg/vector"
+
+	"gorm.io/gorm"
+)
+
+// Config 角色知识库配置
+type Config struct {
+	ChunkTokens    int     `json:",default=300"` // 每个片段的最大 token 数
+	ChunkOverlap   int     `json:",default=50"`  // 相邻片段重叠的 token 数
+	MaxChunks      int     `json:",default=500"` // 单个文档最多的片段数
+	MaxDocuments   int     `json:",default=20"`  // 每个角色最多的文档数
+	TopK           int     `json:",default=3"`   // 每次对话最多注入的片段数
+	MinScore       float64 `json:",default=0.2"` // 注入片段所需的最低相似度
+	LoreScanDepth  int     `json:",default=4"`   // 匹配世界设定关键词时扫描的最近消息条数
+	MaxLoreEntries int     `json:",default=10"`  // 每次对话最多注入的世界设定条目数
+}
+
+var ErrTooManyChunks = errors.New("knowledge: document has too many chunks")
+
+// 每次请求向量化的片段数
+const embedBatchSize = 32
+
+// Base 角色知识库: 文档片段的向量检索和关键词触发的世界设定
+type Base struct {
+	db        *gorm.DB
+	embedder  llm.Embedder
+	index     vector.Index
+	tokenizer tokenizer.Tokenizer
+	c         Config
+}
+
+func NewBase(db *gorm.DB, embedder llm.Embedder, index vector.Index, tok tokenizer.Tokenizer, c Config) *Base {
+	return &Base{
+		db:        db,
+		embedder:  embedder,
+		index:     index,
+		tokenizer: tok,
+		c:         c,
+	}
+}
+
+// AddDocument 切分文档文本, 保存文档和片段并写入向量索引.
+// 任一步骤失败时撤销已保存的记录, doc.Id 不会被保留.
+func (b *Base) AddDocument(ctx context.Context, doc *model.KnowledgeDocument, text string) error {
+	contents := Split(text, b.c.ChunkTokens, b.c.ChunkOverlap, b.tokenizer)
+	if len(contents) == 0 {
+		return ErrEmptyDocument
+	}
+	if b.c.MaxChunks > 0 && len(contents) > b.c.MaxChunks {
+		return ErrTooManyChunks
+	}
+
+	// 先完成向量化, 避免模型调用失败时留下不完整的文档
+	vectors := make([][]float32, 0, len(contents))
+	for start := 0; start < len(contents); start += embedBatchSize {
+		end := min(start+embedBatchSize, len(contents))
+		batch, err := b.embedder.Embed(ctx, contents[start:end])
+		if err != nil {
+			return err
+		}
+		vectors = append(vectors, batch...)
+	}
+
+	chunks := make([]model.KnowledgeChunk, len(contents))
+	for i, content := range contents {
+		chunks[i] = model.KnowledgeChunk{
+			CharacterId: doc.CharacterId,
+			Seq:         i,
+			Content:     content,
+			TokenCount:  b.tokenizer.Count(content),
+		}
+	}
+	doc.ChunkCount = len(chunks)
+
+	err := b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(doc).Error; err != nil {
+			return err
+		}
+		for i := range chunks {
+			chunks[i].DocumentId = doc.Id
+		}
+		return tx.CreateInBatches(&chunks, 100).Error
+	})
+	if err != nil {
+		doc.Id = 0
+		return err
+	}
+
+	items := make([]vector.Item, len(chunks))
+	for i, chunk := range chunks {
+		items[i] = vector.Item{
+			Kind:        vector.KindKnowledge,
+			RefId:       chunk.Id,
+			UserId:      doc.UserId,
+			CharacterId: doc.CharacterId,
+			Vector:      vectors[i],
+		}
+	}
+	if err := b.index.Upsert(ctx, items...); err != nil {
+		// 向量写入失败时撤销文档, 未建立向量的片段无法被检索到
+		if removeErr := b.RemoveDocument(ctx, doc); removeErr != nil {
+			err = errors.Join(err, removeErr)
+		}
+		doc.Id = 0
+		return err
+	}
+	return nil
+}
+
+// RemoveDocument 删除文档及其片段和向量
+func (b *Base) RemoveDocument(ctx context.Context, doc *model.KnowledgeDocument) error {
+	var ids []int64
+	if err := b.db.WithContext(ctx).Model(&model.KnowledgeChunk{}).
+		Where("document_id = ?", doc.Id).Pluck("id", &ids).Error; err != nil {
+		return err
+	}
+
+	// 先删除向量, 即使之后删除记录失败, 残留的片段也不会再被检索到
+	if err := b.index.Delete(ctx, vector.KindKnowledge, ids...); err != nil {
+		return err
+	}
+
+	return b.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("document_id = ?", doc.Id).Delete(&model.KnowledgeChunk{}).Error; err != nil {
+			return err
+		}
+		return tx.Delete(doc).Error
+	})
+}
+
+// Search 检索与 query 最相关的知识片段, 按相似度从高到低排序
+func (b *Base) Search(ctx context.Context, character *model.Character, query string) ([]model.KnowledgeChunk, error) {
+	if b.c.TopK <= 0 || strings.TrimSpace(query) == "" {
+		return nil, nil
+	}
+
+	vectors, err := b.embedder.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := b.index.Search(ctx, vectors[0], vector.Filter{
+		Kind:        vector.KindKnowledge,
+		UserId:      character.UserId,
+		CharacterId: character.Id,
+	}, b.c.TopK)
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]int64, 0, len(results))
+	for _, r := range results {
+		if r.Score >= b.c.MinScore {
+			ids = append(ids, r.RefId)
+		}
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+
+	var chunks []model.KnowledgeChunk
+	if err := b.db.WithContext(ctx).Where("id IN ?", ids).Find(&chunks).Error; err != nil {
+		return nil, err
+	}
+
+	// 按检索结果的顺序返回
+	byId := make(map[int64]model.KnowledgeChunk, len(chunks))
+	for _, c := range chunks {
+		byId[c.Id] = c
+	}
+	ordered := make([]model.KnowledgeChunk, 0, len(chunks))
+	for _, id := range ids {
+		if c, ok := byId[id]; ok {
+			ordered = append(ordered, c)
+		}
+	}
+	return ordered, nil
+}
+
+// Lore 返回被最近消息触发的世界设定条目: 常驻条目总会返回, 其余条目在消息中出现任一关键词时返回.
+// 结果按优先级从高到低排序.
+func (b *Base) Lore(ctx context.Context, characterId int64, recent []string) ([]model.LorebookEntry, error) {
+	if b.c.MaxLoreEntries <= 0 {
+		return nil, nil
+	}
+
+	var entries []model.LorebookEntry
+	if err := b.db.WithContext(ctx).Where("character_id = ? AND enabled = ?", characterId, true).
+		Order("priority DESC, id ASC").Find(&entries).Error; err != nil {
+		return nil, err
+	}
+
+	if depth := b.c.LoreScanDepth; depth > 0 && len(recent) > depth {
+		recent = recent[len(recent)-depth:]
+	}
+	text := strings.ToLower(strings.Join(recent, "\n"))
+
+	var matched []model.LorebookEntry
+	for _, entry := range entries {
+		if entry.Constant || matchKeywords(text, ParseKeywords(entry.Keywords)) {
+			matched = append(matched, entry)
+		}
+	}
+
+	sort.SliceStable(matched, func(i, j int) bool {
+		return matched[i].Priority > matched[j].Priority
+	})
+	if len(matched) > b.c.MaxLoreEntries {
+		matched = matched[:b.c.MaxLoreEntries]
+	}
+	return matched, nil
+}
+
+// ParseKeywords 解析逗号 (中英文均可) 或换行分隔的关键词, 去除空白和重复项
+func ParseKeywords(s string) []string {
+	fields := strings.FieldsFunc(s, func(r rune) bool {
+		return r == ',' || r == '，' || r == '、' || r == '\n'
+	})
+
+	seen := make(map[string]bool, len(fields))
+	keywords := make([]string, 0, len(fields))
+	for _, f := range fields {
+		f = strings.TrimSpace(f)
+		if f == "" || seen[strings.ToLower(f)] {
+			continue
+		}
+		seen[strings.ToLower(f)] = true
+		keywords = append(keywords, f)
+	}
+	return keywords
+}
+
+func matchKeywords(text string, keywords []string) bool {
+	for _, k := range keywords {
+		if strings.Contains(text, strings.ToLower(k)) {
+			return true
+		}
+	}
+	return false
+}
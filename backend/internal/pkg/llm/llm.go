@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// 支持的模型提供方
+const (
+	ProviderMock   = "mock"
+	ProviderOpenAI = "openai"
+)
+
+// 消息角色, 与 OpenAI Chat Completions 保持一致
+const (
+	RoleSystem    = "system"
+	RoleUser      = "user"
+	RoleAssistant = "assistant"
+)
+
+var ErrEmptyMessages = errors.New("llm: messages must not be empty")
+
+// Config 大模型配置
+type Config struct {
+	Provider       string   `json:",default=mock,options=mock|openai"`
+	BaseURL        string   `json:",optional"`
+	APIKey         string   `json:",optional"`
+	Model          string   `json:",optional"`
+	Timeout        int64    `json:",default=60"` // 请求超时(秒), 流式请求仅限制等待响应头的时间
+	MockReplies    []string `json:",optional"`   // mock 模式下按顺序循环返回的回复, 为空时回显用户消息
+	MockChunkDelay int64    `json:",optional"`   // mock 模式下流式分片间隔(毫秒)
+
+	MaxOutputTokens int      `json:",default=4096"` // 角色和请求可设置的最大回复token数
+	AllowedModels   []string `json:",optional"`     // 角色和请求可选的模型, 为空时不限制
+
+	EmbeddingModel      string `json:",optional"`    // 向量模型
+	EmbeddingDimensions int    `json:",default=256"` // mock 模式下哈希向量的维度
+}
+
+type Message struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type ChatRequest struct {
+	Model            string
+	Messages         []Message
+	Temperature      *float64
+	TopP             *float64
+	MaxTokens        int
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	Stop             []string
+}
+
+type Usage struct {
+	PromptTokens     int `json:"prompt_tokens"`
+	CompletionTokens int `json:"completion_tokens"`
+	TotalTokens      int `json:"total_tokens"`
+}
+
+type ChatResponse struct {
+	Model        string
+	Content      string
+	FinishReason string
+	Usage        Usage
+}
+
+type Model struct {
+	Id      string `json:"id"`
+	OwnedBy string `json:"owned_by"`
+}
+
+// StreamFunc 接收流式增量内容, 返回错误时终止生成
+type StreamFunc func(delta string) error
+
+// Embedder 文本向量化
+type Embedder interface {
+	// Embed 按输入顺序返回每段文本的向量
+	Embed(ctx context.Context, texts []string) ([][]float32, error)
+}
+
+// Provider 大模型提供方
+type Provider interface {
+	Embedder
+
+	// Chat 非流式对话补全
+	Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error)
+	// ChatStream 流式对话补全, 出错或被取消时同时返回已生成的部分内容
+	ChatStream(ctx context.Context, req *ChatRequest, fn StreamFunc) (*ChatResponse, error)
+	// ListModels 列出可用模型
+	ListModels(ctx context.Context) ([]Model, error)
+}
+
+// NewProvider 根据配置创建模型提供方
+func NewProvider(c Config) (Provider, error) {
+	switch c.Provider {
+	case "", ProviderMock:
+		provider := NewMockProvider(c.MockReplies, time.Duration(c.MockChunkDelay)*time.Millisecond)
+		provider.embedder = NewHashEmbedder(c.EmbeddingDimensions)
+		return provider, nil
+	case ProviderOpenAI:
+		if c.BaseURL == "" {
+			return nil, errors.New("llm: BaseURL is required for openai provider")
+		}
+		return NewOpenAIProvider(c), nil
+	default:
+		return nil, fmt.Errorf("llm: unknown provider %q", c.Provider)
+	}
+}
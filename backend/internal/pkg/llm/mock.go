@@ -0,0 +1,128 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+const (
+	mockModel     = "mock"
+	mockChunkSize = 4 // 流式输出时每个分片的字符数
+)
+
+// MockProvider 进程内的模拟提供方, 按顺序循环返回预设回复, 未预设时回显最后一条用户消息.
+// 不依赖网络, 用于测试和离线开发环境.
+type MockProvider struct {
+	mu      sync.Mutex
+	replies []string
+	next    int
+	delay   time.Duration
+
+	embedder *HashEmbedder
+}
+
+func NewMockProvider(replies []string, delay time.Duration) *MockProvider {
+	return &MockProvider{
+		replies:  replies,
+		delay:    delay,
+		embedder: NewHashEmbedder(defaultHashDimensions),
+	}
+}
+
+func (p *MockProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	if len(req.Messages) == 0 {
+		return nil, ErrEmptyMessages
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	content := p.reply(req)
+	return &ChatResponse{
+		Model:        p.modelName(req),
+		Content:      content,
+		FinishReason: "stop",
+		Usage:        p.usage(req, content),
+	}, nil
+}
+
+func (p *MockProvider) ChatStream(ctx context.Context, req *ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	if len(req.Messages) == 0 {
+		return nil, ErrEmptyMessages
+	}
+
+	content := []rune(p.reply(req))
+	result := &ChatResponse{Model: p.modelName(req)}
+
+	for start := 0; start < len(content); start += mockChunkSize {
+		end := min(start+mockChunkSize, len(content))
+
+		if p.delay > 0 {
+			select {
+			case <-ctx.Done():
+				return result, ctx.Err()
+			case <-time.After(p.delay):
+			}
+		} else if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		delta := string(content[start:end])
+		result.Content += delta
+		if err := fn(delta); err != nil {
+			return result, err
+		}
+	}
+
+	result.FinishReason = "stop"
+	result.Usage = p.usage(req, result.Content)
+	return result, nil
+}
+
+func (p *MockProvider) ListModels(ctx context.Context) ([]Model, error) {
+	return []Model{{Id: mockModel, OwnedBy: "aifriend"}}, nil
+}
+
+// Embed 使用哈希向量, 共享词语的文本彼此相近
+func (p *MockProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	return p.embedder.Embed(ctx, texts)
+}
+
+func (p *MockProvider) reply(req *ChatRequest) string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.replies) > 0 {
+		reply := p.replies[p.next%len(p.replies)]
+		p.next++
+		return reply
+	}
+
+	for i := len(req.Messages) - 1; i >= 0; i-- {
+		if req.Messages[i].Role == RoleUser {
+			return "你说: " + req.Messages[i].Content
+		}
+	}
+	return "你好"
+}
+
+func (p *MockProvider) modelName(req *ChatRequest) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return mockModel
+}
+
+func (p *MockProvider) usage(req *ChatRequest, content string) Usage {
+	prompt := 0
+	for _, m := range req.Messages {
+		prompt += len([]rune(m.Content))
+	}
+	completion := len([]rune(content))
+	return Usage{
+		PromptTokens:     prompt,
+		CompletionTokens: completion,
+		TotalTokens:      prompt + completion,
+	}
+}
@@ -0,0 +1,147 @@
+package llm
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestMockChatStream(t *testing.T) {
+	tests := []struct {
+		name    string
+		replies []string
+		req     ChatRequest
+		want    []string // 依次收到的分片
+		model   string
+	}{
+		{
+			name: "回显用户消息",
+			req: ChatRequest{Messages: []Message{
+				{Role: RoleSystem, Content: "系统"},
+				{Role: RoleUser, Content: "今天天气"},
+				{Role: RoleAssistant, Content: "好"},
+			}},
+			want:  []string{"你说: ", "今天天气"},
+			model: mockModel,
+		},
+		{
+			name:    "预设回复按分片输出",
+			replies: []string{"一二三四五六七八九"},
+			req:     ChatRequest{Model: "gpt", Messages: []Message{{Role: RoleUser, Content: "hi"}}},
+			want:    []string{"一二三四", "五六七八", "九"},
+			model:   "gpt",
+		},
+		{
+			name:  "没有用户消息",
+			req:   ChatRequest{Messages: []Message{{Role: RoleSystem, Content: "系统"}}},
+			want:  []string{"你好"},
+			model: mockModel,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewMockProvider(tt.replies, 0)
+			var got []string
+			resp, err := p.ChatStream(context.Background(), &tt.req, func(delta string) error {
+				got = append(got, delta)
+				return nil
+			})
+			if err != nil {
+				t.Fatalf("ChatStream() error = %v", err)
+			}
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("deltas = %q, want %q", got, tt.want)
+			}
+			if resp.Content != strings.Join(tt.want, "") {
+				t.Errorf("content = %q, want %q", resp.Content, strings.Join(tt.want, ""))
+			}
+			if resp.Model != tt.model || resp.FinishReason != "stop" {
+				t.Errorf("model = %q, finish = %q", resp.Model, resp.FinishReason)
+			}
+			if u := resp.Usage; u.CompletionTokens != len([]rune(resp.Content)) || u.TotalTokens != u.PromptTokens+u.CompletionTokens {
+				t.Errorf("usage = %+v", u)
+			}
+		})
+	}
+}
+
+func TestMockChatStreamStop(t *testing.T) {
+	errStop := errors.New("stop")
+	req := &ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+
+	tests := []struct {
+		name    string
+		ctx     func(t *testing.T) context.Context
+		fn      func() error
+		delay   time.Duration
+		wantErr error
+		want    string // 返回的部分内容
+	}{
+		{
+			name:    "回调返回错误",
+			ctx:     func(t *testing.T) context.Context { return context.Background() },
+			fn:      func() error { return errStop },
+			wantErr: errStop,
+			want:    "一二三四",
+		},
+		{
+			name: "已取消",
+			ctx: func(t *testing.T) context.Context {
+				ctx, cancel := context.WithCancel(context.Background())
+				cancel()
+				return ctx
+			},
+			fn:      func() error { return nil },
+			wantErr: context.Canceled,
+			want:    "",
+		},
+		{
+			name: "分片间超时",
+			ctx: func(t *testing.T) context.Context {
+				ctx, cancel := context.WithTimeout(context.Background(), 150*time.Millisecond)
+				t.Cleanup(cancel)
+				return ctx
+			},
+			fn:      func() error { return nil },
+			delay:   100 * time.Millisecond,
+			wantErr: context.DeadlineExceeded,
+			want:    "一二三四",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			p := NewMockProvider([]string{"一二三四五六七八九"}, tt.delay)
+			resp, err := p.ChatStream(tt.ctx(t), req, func(delta string) error {
+				return tt.fn()
+			})
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("ChatStream() error = %v, want %v", err, tt.wantErr)
+			}
+			if resp == nil || resp.Content != tt.want {
+				t.Errorf("partial = %+v, want %q", resp, tt.want)
+			}
+		})
+	}
+}
+
+func TestMockReplies(t *testing.T) {
+	p := NewMockProvider([]string{"a", "b"}, 0)
+	req := &ChatRequest{Messages: []Message{{Role: RoleUser, Content: "hi"}}}
+	for _, want := range []string{"a", "b", "a"} {
+		resp, err := p.Chat(context.Background(), req)
+		if err != nil {
+			t.Fatalf("Chat() error = %v", err)
+		}
+		if resp.Content != want {
+			t.Errorf("Chat() = %q, want %q", resp.Content, want)
+		}
+	}
+
+	if _, err := p.ChatStream(context.Background(), &ChatRequest{}, func(string) error { return nil }); !errors.Is(err, ErrEmptyMessages) {
+		t.Errorf("ChatStream() empty error = %v", err)
+	}
+}
@@ -0,0 +1,334 @@
+package llm
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const defaultTimeout = 60 * time.Second
+
+// OpenAIProvider 兼容 OpenAI Chat Completions 协议的 HTTP 客户端
+type OpenAIProvider struct {
+	baseURL   string
+	apiKey    string
+	model     string
+	embedding string
+	timeout   time.Duration
+	client    *http.Client
+}
+
+func NewOpenAIProvider(c Config) *OpenAIProvider {
+	timeout := time.Duration(c.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = defaultTimeout
+	}
+
+	transport := http.DefaultTransport.(*http.Transport).Clone()
+	transport.ResponseHeaderTimeout = timeout
+
+	return &OpenAIProvider{
+		baseURL:   strings.TrimRight(c.BaseURL, "/"),
+		apiKey:    c.APIKey,
+		model:     c.Model,
+		embedding: c.EmbeddingModel,
+		timeout:   timeout,
+		// 不设置 Client.Timeout, 否则会截断长时间的流式响应
+		client: &http.Client{Transport: transport},
+	}
+}
+
+type openAIChatRequest struct {
+	Model            string    `json:"model"`
+	Messages         []Message `json:"messages"`
+	Stream           bool      `json:"stream,omitempty"`
+	Temperature      *float64  `json:"temperature,omitempty"`
+	TopP             *float64  `json:"top_p,omitempty"`
+	MaxTokens        int       `json:"max_tokens,omitempty"`
+	Stop             []string  `json:"stop,omitempty"`
+	PresencePenalty  *float64  `json:"presence_penalty,omitempty"`
+	FrequencyPenalty *float64  `json:"frequency_penalty,omitempty"`
+}
+
+type openAIChatResponse struct {
+	Model   string `json:"model"`
+	Choices []struct {
+		Message      Message `json:"message"`
+		Delta        Message `json:"delta"`
+		FinishReason string  `json:"finish_reason"`
+	} `json:"choices"`
+	Usage *Usage `json:"usage"`
+}
+
+type openAIEmbeddingRequest struct {
+	Model string   `json:"model"`
+	Input []string `json:"input"`
+}
+
+type openAIEmbeddingResponse struct {
+	Data []struct {
+		Index     int       `json:"index"`
+		Embedding []float32 `json:"embedding"`
+	} `json:"data"`
+}
+
+type openAIErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
+
+func (p *OpenAIProvider) Chat(ctx context.Context, req *ChatRequest) (*ChatResponse, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	resp, err := p.doChat(ctx, req, false)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var body openAIChatResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("llm: decode response: %w", err)
+	}
+	if len(body.Choices) == 0 {
+		return nil, fmt.Errorf("llm: empty choices in response")
+	}
+
+	result := &ChatResponse{
+		Model:        body.Model,
+		Content:      body.Choices[0].Message.Content,
+		FinishReason: body.Choices[0].FinishReason,
+	}
+	if body.Usage != nil {
+		result.Usage = *body.Usage
+	}
+
+	return result, nil
+}
+
+func (p *OpenAIProvider) ChatStream(ctx context.Context, req *ChatRequest, fn StreamFunc) (*ChatResponse, error) {
+	resp, err := p.doChat(ctx, req, true)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ChatResponse{Model: p.modelName(req)}
+	var content strings.Builder
+
+	reader := bufio.NewReader(resp.Body)
+	for {
+		line, err := reader.ReadString('\n')
+		if err != nil {
+			result.Content = content.String()
+			if ctx.Err() != nil {
+				return result, ctx.Err()
+			}
+			if err == io.EOF {
+				return result, nil
+			}
+			return result, fmt.Errorf("llm: read stream: %w", err)
+		}
+
+		line = strings.TrimSpace(line)
+		if !strings.HasPrefix(line, "data:") {
+			continue
+		}
+
+		data := strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if data == "[DONE]" {
+			result.Content = content.String()
+			return result, nil
+		}
+
+		var chunk openAIChatResponse
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			result.Content = content.String()
+			return result, fmt.Errorf("llm: decode stream chunk: %w", err)
+		}
+		if chunk.Model != "" {
+			result.Model = chunk.Model
+		}
+		if chunk.Usage != nil {
+			result.Usage = *chunk.Usage
+		}
+		if len(chunk.Choices) == 0 {
+			continue
+		}
+
+		choice := chunk.Choices[0]
+		if choice.FinishReason != "" {
+			result.FinishReason = choice.FinishReason
+		}
+		if choice.Delta.Content == "" {
+			continue
+		}
+
+		content.WriteString(choice.Delta.Content)
+		if err := fn(choice.Delta.Content); err != nil {
+			result.Content = content.String()
+			return result, err
+		}
+	}
+}
+
+func (p *OpenAIProvider) ListModels(ctx context.Context) ([]Model, error) {
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, p.baseURL+"/models", nil)
+	if err != nil {
+		return nil, err
+	}
+	p.setHeaders(httpReq)
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("llm: list models: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readError(resp)
+	}
+
+	var body struct {
+		Data []Model `json:"data"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("llm: decode models: %w", err)
+	}
+
+	return body.Data, nil
+}
+
+func (p *OpenAIProvider) Embed(ctx context.Context, texts []string) ([][]float32, error) {
+	if len(texts) == 0 {
+		return nil, nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, p.timeout)
+	defer cancel()
+
+	payload, err := json.Marshal(openAIEmbeddingRequest{
+		Model: p.embedding,
+		Input: texts,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/embeddings", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	p.setHeaders(httpReq)
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("llm: request embeddings: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, readError(resp)
+	}
+
+	var body openAIEmbeddingResponse
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		return nil, fmt.Errorf("llm: decode embeddings: %w", err)
+	}
+
+	// 按 index 还原输入顺序
+	vectors := make([][]float32, len(texts))
+	for _, d := range body.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("llm: embedding index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	for i, v := range vectors {
+		if v == nil {
+			return nil, fmt.Errorf("llm: missing embedding for input %d", i)
+		}
+	}
+
+	return vectors, nil
+}
+
+func (p *OpenAIProvider) doChat(ctx context.Context, req *ChatRequest, stream bool) (*http.Response, error) {
+	if len(req.Messages) == 0 {
+		return nil, ErrEmptyMessages
+	}
+
+	payload, err := json.Marshal(openAIChatRequest{
+		Model:            p.modelName(req),
+		Messages:         req.Messages,
+		Stream:           stream,
+		Temperature:      req.Temperature,
+		TopP:             req.TopP,
+		MaxTokens:        req.MaxTokens,
+		Stop:             req.Stop,
+		PresencePenalty:  req.PresencePenalty,
+		FrequencyPenalty: req.FrequencyPenalty,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, p.baseURL+"/chat/completions", bytes.NewReader(payload))
+	if err != nil {
+		return nil, err
+	}
+	p.setHeaders(httpReq)
+	httpReq.Header.Set("Content-Type", "application/json")
+	if stream {
+		httpReq.Header.Set("Accept", "text/event-stream")
+	}
+
+	resp, err := p.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("llm: request chat completion: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		return nil, readError(resp)
+	}
+
+	return resp, nil
+}
+
+func (p *OpenAIProvider) modelName(req *ChatRequest) string {
+	if req.Model != "" {
+		return req.Model
+	}
+	return p.model
+}
+
+func (p *OpenAIProvider) setHeaders(req *http.Request) {
+	if p.apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+p.apiKey)
+	}
+}
+
+func readError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64*1024))
+
+	var errResp openAIErrorResponse
+	if err := json.Unmarshal(body, &errResp); err == nil && errResp.Error.Message != "" {
+		return fmt.Errorf("llm: status %d: %s", resp.StatusCode, errResp.Error.Message)
+	}
+
+	return fmt.Errorf("llm: status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+}
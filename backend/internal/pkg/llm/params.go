@@ -0,0 +1,112 @@
+package llm
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+)
+
+// 各生成参数的取值范围, 与 OpenAI Chat Completions 保持一致
+const (
+	MaxTemperature = 2.0
+	MaxTopP        = 1.0
+	MaxPenalty     = 2.0
+	MaxStop        = 4   // 最多的停止序列数
+	MaxStopLength  = 100 // 单个停止序列的最大字符数
+	MaxModelLength = 100
+)
+
+// Params 生成参数, 零值字段表示使用默认值
+type Params struct {
+	Model            string
+	Temperature      *float64
+	TopP             *float64
+	MaxTokens        int
+	PresencePenalty  *float64
+	FrequencyPenalty *float64
+	Stop             []string
+}
+
+// Merge 以 o 中已设置的字段覆盖 p
+func (p Params) Merge(o Params) Params {
+	if o.Model != "" {
+		p.Model = o.Model
+	}
+	if o.Temperature != nil {
+		p.Temperature = o.Temperature
+	}
+	if o.TopP != nil {
+		p.TopP = o.TopP
+	}
+	if o.MaxTokens > 0 {
+		p.MaxTokens = o.MaxTokens
+	}
+	if o.PresencePenalty != nil {
+		p.PresencePenalty = o.PresencePenalty
+	}
+	if o.FrequencyPenalty != nil {
+		p.FrequencyPenalty = o.FrequencyPenalty
+	}
+	if o.Stop != nil {
+		p.Stop = o.Stop
+	}
+	return p
+}
+
+// Apply 把已设置的参数写入请求
+func (p Params) Apply(req *ChatRequest) {
+	if p.Model != "" {
+		req.Model = p.Model
+	}
+	if p.MaxTokens > 0 {
+		req.MaxTokens = p.MaxTokens
+	}
+	req.Temperature = p.Temperature
+	req.TopP = p.TopP
+	req.PresencePenalty = p.PresencePenalty
+	req.FrequencyPenalty = p.FrequencyPenalty
+	req.Stop = p.Stop
+}
+
+// CheckParams 按提供方的取值范围和配置的限制校验生成参数
+func (c Config) CheckParams(p Params) error {
+	if p.Model != "" {
+		if len(p.Model) > MaxModelLength || strings.TrimSpace(p.Model) != p.Model {
+			return fmt.Errorf("invalid model name %q", p.Model)
+		}
+		if len(c.AllowedModels) > 0 && !slices.Contains(c.AllowedModels, p.Model) {
+			return fmt.Errorf("model %q is not allowed", p.Model)
+		}
+	}
+	if err := checkRange("temperature", p.Temperature, 0, MaxTemperature); err != nil {
+		return err
+	}
+	if err := checkRange("top_p", p.TopP, 0, MaxTopP); err != nil {
+		return err
+	}
+	if err := checkRange("presence_penalty", p.PresencePenalty, -MaxPenalty, MaxPenalty); err != nil {
+		return err
+	}
+	if err := checkRange("frequency_penalty", p.FrequencyPenalty, -MaxPenalty, MaxPenalty); err != nil {
+		return err
+	}
+	if p.MaxTokens < 0 || p.MaxTokens > c.MaxOutputTokens {
+		return fmt.Errorf("max_tokens must be between 1 and %d", c.MaxOutputTokens)
+	}
+	if len(p.Stop) > MaxStop {
+		return fmt.Errorf("at most %d stop sequences are allowed", MaxStop)
+	}
+	for _, s := range p.Stop {
+		if s == "" || len([]rune(s)) > MaxStopLength {
+			return fmt.Errorf("stop sequences must be 1 to %d characters", MaxStopLength)
+		}
+	}
+	return nil
+}
+
+func checkRange(name string, v *float64, min, max float64) error {
+	if v != nil && (*v < min || *v > max) {
+		return fmt.Errorf("%s must be between %g and %g", name, min, max)
+	}
+	return nil
+}
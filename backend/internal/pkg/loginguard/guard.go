@@ -0,0 +1,190 @@
+package loginguard
+
+import (
+	"context"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// 登录防暴力破解: 分别按用户名和IP统计连续失败次数. 失败次数达到阈值后每次失败都要等待一段时间才能再试,
+// 等待时间随失败次数翻倍; 达到上限后锁定一段时间. 登录成功只清零该用户名的计数,
+// 避免攻击者用自己的账号登录来清零IP的计数.
+//
+// 验证密码之前先原子地把本次尝试计为一次失败(预留), 并发的请求各自看到不同的失败次数,
+// 不会同时通过检查. 登录成功后再清零用户名的计数并撤销IP的预留, 撤销时同时恢复预留前的失败时间,
+// 成功的登录不会延长同一IP下其他用户的等待时间.
+
+// Config 登录失败限制配置
+type Config struct {
+	Enabled          bool            `json:",default=true"` // 是否启用
+	UserBackoffAfter int             `json:",default=3"`    // 同一用户名连续失败多少次后开始要求等待
+	UserMaxFailures  int             `json:",default=10"`   // 同一用户名连续失败多少次后锁定
+	IpBackoffAfter   int             `json:",default=10"`   // 同一IP连续失败多少次后开始要求等待
+	IpMaxFailures    int             `json:",default=50"`   // 同一IP连续失败多少次后锁定
+	CaptchaAfter     int             `json:",default=3"`    // 同一用户名连续失败多少次后提示客户端显示验证码, 0 表示不提示
+	BaseDelay        int64           `json:",default=1"`    // 开始要求等待时的等待时间(秒), 之后每次失败翻倍
+	MaxDelay         int64           `json:",default=300"`  // 最长等待时间(秒)
+	LockDuration     int64           `json:",default=900"`  // 锁定时长(秒)
+	Window           int64           `json:",default=3600"` // 失败记录的保留时间(秒), 期间没有新的失败则清零
+	Redis            redis.RedisConf `json:",optional"`     // 配置 Host 时失败记录保存在 Redis 中, 多个实例共享; 否则保存在内存中
+}
+
+// Status 当前是否允许登录
+type Status struct {
+	RetryAfter      time.Duration // 需要等待的时间, 为 0 时允许登录
+	Locked          bool          // 失败次数达到上限被锁定
+	CaptchaRequired bool          // 客户端应要求用户完成验证码
+	ReservedAt      time.Time     // 本次尝试预留的时间, 确认失败或登录成功时传回; 没有预留时为零值
+}
+
+// Guard 记录登录失败并判断是否允许登录
+type Guard struct {
+	store Store
+	c     Config
+}
+
+// NewGuard 根据配置创建, 配置了 Redis 时使用 Redis 保存失败记录
+func NewGuard(c Config) (*Guard, error) {
+	if c.Redis.Host == "" {
+		return &Guard{store: NewMemoryStore(), c: c}, nil
+	}
+	rds, err := redis.NewRedis(c.Redis)
+	if err != nil {
+		return nil, err
+	}
+	return &Guard{store: NewRedisStore(rds), c: c}, nil
+}
+
+// maxReserveRetries 预留时失败记录被并发修改后的最多重试次数
+const maxReserveRetries = 5
+
+// Attempt 登录前检查用户名和IP是否需要等待, 允许登录时把本次尝试预留为一次失败.
+// 返回的 RetryAfter 大于 0 时拒绝登录, 没有预留
+func (g *Guard) Attempt(ctx context.Context, username, ip string) (Status, error) {
+	if !g.c.Enabled {
+		return Status{}, nil
+	}
+
+	keys := []string{userKey(username), ipKey(ip)}
+	ttl := time.Duration(max(g.c.Window, g.c.LockDuration)) * time.Second
+	for range maxReserveRetries {
+		user, addr, err := g.get(ctx, keys)
+		if err != nil {
+			return Status{}, err
+		}
+		now := time.Now()
+		s := g.status(user, addr, now)
+		if s.RetryAfter > 0 {
+			return s, nil
+		}
+
+		// 读取之后记录被其他请求修改过时重新检查
+		ok, err := g.store.Reserve(ctx, keys, []int{user.Failures, addr.Failures}, now, ttl)
+		if err != nil {
+			return Status{}, err
+		}
+		if ok {
+			s.ReservedAt = now
+			return s, nil
+		}
+	}
+
+	// 同一用户名或IP的并发请求过多, 按最短等待时间拒绝
+	return Status{RetryAfter: time.Duration(max(g.c.BaseDelay, 1)) * time.Second}, nil
+}
+
+// Fail 确认 reservedAt 时预留的尝试失败, 返回之后的状态
+func (g *Guard) Fail(ctx context.Context, username, ip string, reservedAt time.Time) (Status, error) {
+	if !g.c.Enabled {
+		return Status{}, nil
+	}
+
+	keys := []string{userKey(username), ipKey(ip)}
+	if !reservedAt.IsZero() {
+		for _, key := range keys {
+			if err := g.store.Confirm(ctx, key, reservedAt); err != nil {
+				return Status{}, err
+			}
+		}
+	}
+	user, addr, err := g.get(ctx, keys)
+	if err != nil {
+		return Status{}, err
+	}
+	return g.status(user, addr, time.Now()), nil
+}
+
+// Succeed 登录成功后清零该用户名的失败次数, 并撤销IP在 reservedAt 时的预留
+func (g *Guard) Succeed(ctx context.Context, username, ip string, reservedAt time.Time) error {
+	if !g.c.Enabled {
+		return nil
+	}
+	if err := g.store.Reset(ctx, userKey(username)); err != nil {
+		return err
+	}
+	if reservedAt.IsZero() {
+		return nil
+	}
+	return g.store.Release(ctx, ipKey(ip), reservedAt)
+}
+
+func (g *Guard) get(ctx context.Context, keys []string) (user, addr Record, err error) {
+	if user, err = g.store.Get(ctx, keys[0]); err != nil {
+		return
+	}
+	addr, err = g.store.Get(ctx, keys[1])
+	return
+}
+
+func (g *Guard) status(user, addr Record, now time.Time) Status {
+	var s Status
+	for _, r := range []struct {
+		record       Record
+		backoffAfter int
+		maxFailures  int
+	}{
+		{user, g.c.UserBackoffAfter, g.c.UserMaxFailures},
+		{addr, g.c.IpBackoffAfter, g.c.IpMaxFailures},
+	} {
+		wait, locked := g.wait(r.record, r.backoffAfter, r.maxFailures, now)
+		if wait > s.RetryAfter {
+			s.RetryAfter = wait
+		}
+		s.Locked = s.Locked || locked
+	}
+	// 验证码只看用户名的失败次数, 同一出口IP下的其他用户不受影响
+	s.CaptchaRequired = g.c.CaptchaAfter > 0 && user.Failures >= g.c.CaptchaAfter
+	return s
+}
+
+// wait 计算一个失败记录还需要等待的时间
+func (g *Guard) wait(r Record, backoffAfter, maxFailures int, now time.Time) (time.Duration, bool) {
+	var until time.Time
+	locked := false
+	switch {
+	case maxFailures > 0 && r.Failures >= maxFailures:
+		until = r.LastFailedAt.Add(time.Duration(g.c.LockDuration) * time.Second)
+		locked = true
+	case backoffAfter > 0 && r.Failures >= backoffAfter:
+		delay := g.c.BaseDelay << min(r.Failures-backoffAfter, 30)
+		until = r.LastFailedAt.Add(time.Duration(min(delay, g.c.MaxDelay)) * time.Second)
+	default:
+		return 0, false
+	}
+
+	if wait := until.Sub(now); wait > 0 {
+		return wait, locked
+	}
+	return 0, false
+}
+
+// 用户名不区分大小写, 与 MySQL 默认的排序规则一致
+func userKey(username string) string {
+	return "user:" + strings.ToLower(strings.TrimSpace(username))
+}
+
+func ipKey(ip string) string {
+	return "ip:" + ip
+}
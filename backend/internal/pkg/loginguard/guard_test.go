@@ -0,0 +1,390 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+)
+
+func testConfig() Config {
+	return Config{
+		Enabled:          true,
+		UserBackoffAfter: 3,
+		UserMaxFailures:  5,
+		IpBackoffAfter:   10,
+		IpMaxFailures:    20,
+		CaptchaAfter:     2,
+		BaseDelay:        1,
+		MaxDelay:         4,
+		LockDuration:     900,
+		Window:           3600,
+	}
+}
+
+func TestStatus(t *testing.T) {
+	g := &Guard{c: testConfig()}
+	now := time.Now()
+	failed := func(failures int, ago time.Duration) Record {
+		return Record{Failures: failures, LastFailedAt: now.Add(-ago)}
+	}
+
+	tests := []struct {
+		name        string
+		user, addr  Record
+		wantRetry   time.Duration
+		wantLocked  bool
+		wantCaptcha bool
+	}{
+		{name: "没有失败", wantRetry: 0},
+		{name: "未达到等待阈值", user: failed(2, 0), wantCaptcha: true},
+		{name: "开始等待", user: failed(3, 0), wantRetry: time.Second, wantCaptcha: true},
+		{name: "等待时间翻倍", user: failed(4, 0), wantRetry: 2 * time.Second, wantCaptcha: true},
+		{name: "等待已结束", user: failed(4, 3*time.Second), wantCaptcha: true},
+		{name: "锁定", user: failed(5, time.Minute), wantRetry: 14 * time.Minute, wantLocked: true, wantCaptcha: true},
+		{name: "锁定已结束", user: failed(5, 15*time.Minute), wantCaptcha: true},
+		{name: "IP开始等待", addr: failed(10, 0), wantRetry: time.Second},
+		{name: "等待时间不超过上限", addr: failed(19, 0), wantRetry: 4 * time.Second},
+		{name: "IP锁定", addr: failed(20, 0), wantRetry: 15 * time.Minute, wantLocked: true},
+		{name: "取较长的等待", user: failed(3, 0), addr: failed(12, 0), wantRetry: 4 * time.Second, wantCaptcha: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := g.status(tt.user, tt.addr, now)
+			if s.RetryAfter != tt.wantRetry || s.Locked != tt.wantLocked || s.CaptchaRequired != tt.wantCaptcha {
+				t.Errorf("status() = %+v, want retry %v, locked %v, captcha %v", s, tt.wantRetry, tt.wantLocked, tt.wantCaptcha)
+			}
+		})
+	}
+}
+
+func TestAttempt(t *testing.T) {
+	ctx := context.Background()
+
+	tests := []struct {
+		name string
+		// run 依次执行的操作, 返回最后一次 Attempt 的结果
+		run        func(g *Guard) (Status, error)
+		wantRetry  bool
+		wantLocked bool
+	}{
+		{
+			name: "首次登录",
+			run:  func(g *Guard) (Status, error) { return g.Attempt(ctx, "alice", "10.0.0.1") },
+		},
+		{
+			name: "连续失败后等待",
+			run: func(g *Guard) (Status, error) {
+				for range 3 {
+					g.Attempt(ctx, "alice", "10.0.0.1")
+				}
+				return g.Attempt(ctx, "alice", "10.0.0.2")
+			},
+			wantRetry: true,
+		},
+		{
+			name: "用户名不区分大小写",
+			run: func(g *Guard) (Status, error) {
+				for range 3 {
+					g.Attempt(ctx, "Alice ", "10.0.0.1")
+				}
+				return g.Attempt(ctx, "alice", "10.0.0.1")
+			},
+			wantRetry: true,
+		},
+		{
+			name: "其他用户名不受影响",
+			run: func(g *Guard) (Status, error) {
+				for range 3 {
+					g.Attempt(ctx, "alice", "10.0.0.1")
+				}
+				return g.Attempt(ctx, "bob", "10.0.0.1")
+			},
+		},
+		{
+			name: "登录成功后清零",
+			run: func(g *Guard) (Status, error) {
+				for range 2 {
+					g.Attempt(ctx, "alice", "10.0.0.1")
+				}
+				s, _ := g.Attempt(ctx, "alice", "10.0.0.1")
+				g.Succeed(ctx, "alice", "10.0.0.1", s.ReservedAt)
+				return g.Attempt(ctx, "alice", "10.0.0.1")
+			},
+		},
+		{
+			name: "同一IP尝试多个用户名",
+			run: func(g *Guard) (Status, error) {
+				for i := range 10 {
+					g.Attempt(ctx, string(rune('a'+i)), "10.0.0.1")
+				}
+				return g.Attempt(ctx, "z", "10.0.0.1")
+			},
+			wantRetry: true,
+		},
+		{
+			name: "登录成功不清零IP的其他失败",
+			run: func(g *Guard) (Status, error) {
+				var s Status
+				for i := range 10 {
+					s, _ = g.Attempt(ctx, string(rune('a'+i)), "10.0.0.1")
+				}
+				g.Succeed(ctx, "j", "10.0.0.1", s.ReservedAt)
+				g.Attempt(ctx, "y", "10.0.0.1")
+				return g.Attempt(ctx, "z", "10.0.0.1")
+			},
+			wantRetry: true,
+		},
+		{
+			name: "未启用",
+			run: func(g *Guard) (Status, error) {
+				g.c.Enabled = false
+				for range 10 {
+					g.Attempt(ctx, "alice", "10.0.0.1")
+				}
+				return g.Attempt(ctx, "alice", "10.0.0.1")
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g, err := NewGuard(testConfig())
+			if err != nil {
+				t.Fatalf("NewGuard() error = %v", err)
+			}
+			s, err := tt.run(g)
+			if err != nil {
+				t.Fatalf("Attempt() error = %v", err)
+			}
+			if (s.RetryAfter > 0) != tt.wantRetry || s.Locked != tt.wantLocked {
+				t.Errorf("Attempt() = %+v, want retry %v, locked %v", s, tt.wantRetry, tt.wantLocked)
+			}
+		})
+	}
+}
+
+func TestAttemptLockout(t *testing.T) {
+	ctx := context.Background()
+	c := testConfig()
+	c.UserBackoffAfter = 0
+	g, _ := NewGuard(c)
+
+	for i := range c.UserMaxFailures {
+		if s, _ := g.Attempt(ctx, "alice", "10.0.0.1"); s.RetryAfter > 0 {
+			t.Fatalf("attempt %d rejected: %+v", i+1, s)
+		}
+	}
+	s, err := g.Fail(ctx, "alice", "10.0.0.1", time.Time{})
+	if err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+	if !s.Locked || s.RetryAfter <= 14*time.Minute || !s.CaptchaRequired {
+		t.Errorf("Fail() = %+v, want locked for LockDuration", s)
+	}
+	if s, _ := g.Attempt(ctx, "alice", "10.0.0.1"); !s.Locked {
+		t.Errorf("Attempt() after lockout = %+v", s)
+	}
+}
+
+// TestAttemptConcurrent 并发的请求不能同时通过检查
+func TestAttemptConcurrent(t *testing.T) {
+	c := testConfig()
+	c.UserBackoffAfter = 1
+	g, _ := NewGuard(c)
+
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	allowed := 0
+	for range 50 {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s, err := g.Attempt(context.Background(), "alice", "10.0.0.1")
+			if err != nil {
+				t.Error(err)
+				return
+			}
+			if s.RetryAfter == 0 {
+				mu.Lock()
+				allowed++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	if allowed != 1 {
+		t.Errorf("%d concurrent attempts allowed, want 1", allowed)
+	}
+}
+
+func TestMemoryStoreReserve(t *testing.T) {
+	ctx := context.Background()
+	now := time.Now()
+	keys := []string{"user:a", "ip:1"}
+
+	tests := []struct {
+		name     string
+		setup    func(s *MemoryStore)
+		expected []int
+		wantOk   bool
+		want     []int // 之后每个键的失败次数
+	}{
+		{name: "新记录", expected: []int{0, 0}, wantOk: true, want: []int{1, 1}},
+		{
+			name:     "次数一致",
+			setup:    func(s *MemoryStore) { s.Reserve(ctx, keys, []int{0, 0}, now, time.Hour) },
+			expected: []int{1, 1},
+			wantOk:   true,
+			want:     []int{2, 2},
+		},
+		{
+			name:     "其中一个键已被修改",
+			setup:    func(s *MemoryStore) { s.Reserve(ctx, keys[1:], []int{0}, now, time.Hour) },
+			expected: []int{0, 0},
+			want:     []int{0, 1},
+		},
+		{
+			name:     "已过期的记录按零处理",
+			setup:    func(s *MemoryStore) { s.Reserve(ctx, keys, []int{0, 0}, now.Add(-2*time.Hour), time.Hour) },
+			expected: []int{0, 0},
+			wantOk:   true,
+			want:     []int{1, 1},
+		},
+		{
+			name: "撤销预留",
+			setup: func(s *MemoryStore) {
+				s.Reserve(ctx, keys, []int{0, 0}, now, time.Hour)
+				s.Release(ctx, keys[1], now)
+				s.Release(ctx, keys[1], now)
+			},
+			expected: []int{1, 0},
+			wantOk:   true,
+			want:     []int{2, 1},
+		},
+		{
+			name: "清除",
+			setup: func(s *MemoryStore) {
+				s.Reserve(ctx, keys, []int{0, 0}, now, time.Hour)
+				s.Reset(ctx, keys[0])
+			},
+			expected: []int{0, 1},
+			wantOk:   true,
+			want:     []int{1, 2},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := NewMemoryStore()
+			if tt.setup != nil {
+				tt.setup(s)
+			}
+			ok, err := s.Reserve(ctx, keys, tt.expected, now, time.Hour)
+			if err != nil || ok != tt.wantOk {
+				t.Fatalf("Reserve() = %v, %v, want %v", ok, err, tt.wantOk)
+			}
+			for i, key := range keys {
+				r, _ := s.Get(ctx, key)
+				if r.Failures != tt.want[i] {
+					t.Errorf("%s failures = %d, want %d", key, r.Failures, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+// TestSucceedKeepsIpWait 登录成功撤销预留后恢复原来的失败时间, 不延长同一IP下其他用户的等待
+func TestSucceedKeepsIpWait(t *testing.T) {
+	ctx := context.Background()
+	c := testConfig()
+	c.IpBackoffAfter, c.BaseDelay, c.MaxDelay = 2, 60, 60
+	g, _ := NewGuard(c)
+	store := g.store.(*MemoryStore)
+
+	// 两次失败后开始等待, 把最后一次失败移到 50 秒前, 还需要等待 10 秒
+	for _, name := range []string{"a", "b"} {
+		s, _ := g.Attempt(ctx, name, "10.0.0.1")
+		g.Fail(ctx, name, "10.0.0.1", s.ReservedAt)
+	}
+	last := time.Now().Add(-50 * time.Second)
+	store.records[ipKey("10.0.0.1")].LastFailedAt = last
+
+	tests := []struct {
+		name    string
+		succeed func() // 等待期间其他用户从同一IP登录成功, 如 NAT 后的多个用户
+	}{
+		{
+			name: "登录成功",
+			succeed: func() {
+				// 等待期间的登录被拒绝, 模拟预留: 直接调用存储
+				now := time.Now()
+				store.Reserve(ctx, []string{userKey("c"), ipKey("10.0.0.1")}, []int{0, 2}, now, time.Hour)
+				g.Succeed(ctx, "c", "10.0.0.1", now)
+			},
+		},
+		{
+			name: "并发预留先后撤销",
+			succeed: func() {
+				first, second := time.Now(), time.Now().Add(time.Millisecond)
+				store.Reserve(ctx, []string{userKey("c"), ipKey("10.0.0.1")}, []int{0, 2}, first, time.Hour)
+				store.Reserve(ctx, []string{userKey("d"), ipKey("10.0.0.1")}, []int{0, 3}, second, time.Hour)
+				g.Succeed(ctx, "c", "10.0.0.1", first)
+				g.Succeed(ctx, "d", "10.0.0.1", second)
+			},
+		},
+		{
+			name: "并发预留后撤销先完成的",
+			succeed: func() {
+				first, second := time.Now(), time.Now().Add(time.Millisecond)
+				store.Reserve(ctx, []string{userKey("c"), ipKey("10.0.0.1")}, []int{0, 2}, first, time.Hour)
+				store.Reserve(ctx, []string{userKey("d"), ipKey("10.0.0.1")}, []int{0, 3}, second, time.Hour)
+				g.Succeed(ctx, "d", "10.0.0.1", second)
+				g.Succeed(ctx, "c", "10.0.0.1", first)
+			},
+		},
+		{
+			name:    "没有预留",
+			succeed: func() { g.Succeed(ctx, "c", "10.0.0.1", time.Time{}) },
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.succeed()
+			r, _ := store.Get(ctx, ipKey("10.0.0.1"))
+			if r.Failures != 2 || !r.LastFailedAt.Equal(last) {
+				t.Fatalf("ip record = %+v, want 2 failures at %v", r, last)
+			}
+			s, _ := g.Attempt(ctx, "e", "10.0.0.1")
+			if s.RetryAfter <= 0 || s.RetryAfter > 10*time.Second {
+				t.Errorf("Attempt() retry after %v, want <= 10s", s.RetryAfter)
+			}
+		})
+	}
+}
+
+// TestFailConfirms 确认失败后保留失败时间, 之后撤销不再生效
+func TestFailConfirms(t *testing.T) {
+	ctx := context.Background()
+	g, _ := NewGuard(testConfig())
+	store := g.store.(*MemoryStore)
+
+	s, _ := g.Attempt(ctx, "alice", "10.0.0.1")
+	if s.ReservedAt.IsZero() {
+		t.Fatalf("Attempt() did not reserve")
+	}
+	if _, err := g.Fail(ctx, "alice", "10.0.0.1", s.ReservedAt); err != nil {
+		t.Fatalf("Fail() error = %v", err)
+	}
+	store.Release(ctx, ipKey("10.0.0.1"), s.ReservedAt)
+	r, _ := store.Get(ctx, ipKey("10.0.0.1"))
+	if r.Failures != 1 || !r.LastFailedAt.Equal(s.ReservedAt) {
+		t.Errorf("ip record after confirmed failure = %+v", r)
+	}
+	if n := len(store.records[ipKey("10.0.0.1")].reserved); n != 0 {
+		t.Errorf("%d reservations left after Fail()", n)
+	}
+}
@@ -0,0 +1,126 @@
+package loginguard
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/stores/redis"
+)
+
+// keyPrefix Redis 中失败记录的键前缀. 使用同一个 hash tag, 集群模式下用户名和IP的记录在同一个槽,
+// 可以在一个脚本中同时修改
+const keyPrefix = "{aifriend:login}:"
+
+// reservedPrefix 未确认的预留保存为 "reserved:预留时间" 字段, 值为预留前的失败时间. 与脚本中的一致
+const reservedPrefix = "reserved:"
+
+// reserveScript 所有键的失败次数都等于预期时原子地增加失败次数、记录失败时间并设置过期时间,
+// 同时保存预留前的失败时间. ARGV: 失败时间(毫秒), 过期时间(毫秒), 每个键预期的失败次数
+var reserveScript = redis.NewScript(`
+for i, key in ipairs(KEYS) do
+  local n = tonumber(redis.call("HGET", key, "failures") or "0")
+  if n ~= tonumber(ARGV[i + 2]) then
+    return 0
+  end
+end
+for _, key in ipairs(KEYS) do
+  redis.call("HSET", key, "reserved:" .. ARGV[1], redis.call("HGET", key, "last") or "0")
+  redis.call("HINCRBY", key, "failures", 1)
+  redis.call("HSET", key, "last", ARGV[1])
+  redis.call("PEXPIRE", key, ARGV[2])
+end
+return 1
+`)
+
+// releaseScript 预留存在时失败次数减一并恢复预留前的失败时间, 不会重新创建已过期或已清除的记录.
+// 失败时间已被之后的预留覆盖时, 改为让之后的预留撤销时恢复到本次预留之前的时间.
+// ARGV: 预留时间(毫秒)
+var releaseScript = redis.NewScript(`
+local field = "reserved:" .. ARGV[1]
+local prev = redis.call("HGET", KEYS[1], field)
+if not prev then
+  return 0
+end
+redis.call("HDEL", KEYS[1], field)
+local n = tonumber(redis.call("HGET", KEYS[1], "failures") or "0")
+if n > 0 then
+  redis.call("HINCRBY", KEYS[1], "failures", -1)
+end
+if redis.call("HGET", KEYS[1], "last") == ARGV[1] then
+  redis.call("HSET", KEYS[1], "last", prev)
+  return 0
+end
+local fields = redis.call("HGETALL", KEYS[1])
+for i = 1, #fields, 2 do
+  if string.sub(fields[i], 1, 9) == "reserved:" and fields[i + 1] == ARGV[1] then
+    redis.call("HSET", KEYS[1], fields[i], prev)
+  end
+end
+return 0
+`)
+
+// RedisStore 保存在 Redis 中, 多个实例共享失败记录
+type RedisStore struct {
+	rds *redis.Redis
+}
+
+func NewRedisStore(rds *redis.Redis) *RedisStore {
+	return &RedisStore{
+		rds: rds,
+	}
+}
+
+func (s *RedisStore) Get(ctx context.Context, key string) (Record, error) {
+	fields, err := s.rds.HgetallCtx(ctx, keyPrefix+key)
+	if err != nil || len(fields) == 0 {
+		return Record{}, err
+	}
+
+	failures, err := strconv.Atoi(fields["failures"])
+	if err != nil {
+		return Record{}, fmt.Errorf("loginguard: bad failures of %s: %w", key, err)
+	}
+	last, err := strconv.ParseInt(fields["last"], 10, 64)
+	if err != nil {
+		return Record{}, fmt.Errorf("loginguard: bad last failure time of %s: %w", key, err)
+	}
+	return Record{
+		Failures:     failures,
+		LastFailedAt: time.UnixMilli(last),
+	}, nil
+}
+
+func (s *RedisStore) Reserve(ctx context.Context, keys []string, expected []int, now time.Time, ttl time.Duration) (bool, error) {
+	redisKeys := make([]string, len(keys))
+	args := []any{now.UnixMilli(), ttl.Milliseconds()}
+	for i, key := range keys {
+		redisKeys[i] = keyPrefix + key
+		args = append(args, expected[i])
+	}
+	v, err := s.rds.ScriptRunCtx(ctx, reserveScript, redisKeys, args...)
+	if err != nil {
+		return false, err
+	}
+	n, ok := v.(int64)
+	if !ok {
+		return false, fmt.Errorf("loginguard: unexpected script result %T", v)
+	}
+	return n == 1, nil
+}
+
+func (s *RedisStore) Confirm(ctx context.Context, key string, at time.Time) error {
+	_, err := s.rds.HdelCtx(ctx, keyPrefix+key, reservedPrefix+strconv.FormatInt(at.UnixMilli(), 10))
+	return err
+}
+
+func (s *RedisStore) Release(ctx context.Context, key string, at time.Time) error {
+	_, err := s.rds.ScriptRunCtx(ctx, releaseScript, []string{keyPrefix + key}, at.UnixMilli())
+	return err
+}
+
+func (s *RedisStore) Reset(ctx context.Context, key string) error {
+	_, err := s.rds.DelCtx(ctx, keyPrefix+key)
+	return err
+}
@@ -0,0 +1,144 @@
+package loginguard
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Record 一个用户名或IP的连续失败记录
+type Record struct {
+	Failures     int
+	LastFailedAt time.Time
+}
+
+// Store 保存失败记录
+type Store interface {
+	// Get 返回失败记录, 不存在或已过期时返回零值
+	Get(ctx context.Context, key string) (Record, error)
+	// Reserve 每个键当前的失败次数都等于 expected 时把它们都加一并记录失败时间, 返回 true;
+	// 否则不做修改并返回 false. 预留前的失败时间以 now 为标识保存, 撤销时恢复.
+	// 记录在 ttl 内没有新的失败时过期
+	Reserve(ctx context.Context, keys []string, expected []int, now time.Time, ttl time.Duration) (bool, error)
+	// Confirm 确认 at 时的预留是一次失败, 不再保存预留前的失败时间
+	Confirm(ctx context.Context, key string, at time.Time) error
+	// Release 撤销 at 时的预留, 失败次数减一并恢复预留前的失败时间.
+	// 预留不存在(已确认、已清除或已过期)时不做修改
+	Release(ctx context.Context, key string, at time.Time) error
+	// Reset 清除失败记录
+	Reset(ctx context.Context, key string) error
+}
+
+// MemoryStore 保存在进程内存中, 只适用于单实例部署
+type MemoryStore struct {
+	mu        sync.Mutex
+	records   map[string]*memoryRecord
+	lastSweep time.Time
+}
+
+type memoryRecord struct {
+	Record
+	expiresAt time.Time
+	reserved  map[int64]time.Time // 未确认的预留, 预留时间(纳秒) -> 预留前的失败时间
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{
+		records: make(map[string]*memoryRecord),
+	}
+}
+
+func (s *MemoryStore) Get(ctx context.Context, key string) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if !ok || time.Now().After(r.expiresAt) {
+		return Record{}, nil
+	}
+	return r.Record, nil
+}
+
+func (s *MemoryStore) Reserve(ctx context.Context, keys []string, expected []int, now time.Time, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// 按过期时间定期清理, 避免大量不同的用户名或IP占用内存
+	if now.Sub(s.lastSweep) > ttl {
+		for k, r := range s.records {
+			if now.After(r.expiresAt) {
+				delete(s.records, k)
+			}
+		}
+		s.lastSweep = now
+	}
+
+	for i, key := range keys {
+		failures := 0
+		if r, ok := s.records[key]; ok && !now.After(r.expiresAt) {
+			failures = r.Failures
+		}
+		if failures != expected[i] {
+			return false, nil
+		}
+	}
+	for _, key := range keys {
+		r, ok := s.records[key]
+		if !ok || now.After(r.expiresAt) {
+			r = &memoryRecord{reserved: make(map[int64]time.Time)}
+			s.records[key] = r
+		}
+		r.reserved[now.UnixNano()] = r.LastFailedAt
+		r.Failures++
+		r.LastFailedAt = now
+		r.expiresAt = now.Add(ttl)
+	}
+	return true, nil
+}
+
+func (s *MemoryStore) Confirm(ctx context.Context, key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if r, ok := s.records[key]; ok {
+		delete(r.reserved, at.UnixNano())
+	}
+	return nil
+}
+
+func (s *MemoryStore) Release(ctx context.Context, key string, at time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	r, ok := s.records[key]
+	if !ok {
+		return nil
+	}
+	prev, ok := r.reserved[at.UnixNano()]
+	if !ok {
+		return nil
+	}
+	delete(r.reserved, at.UnixNano())
+	if r.Failures > 0 {
+		r.Failures--
+	}
+	if r.LastFailedAt.Equal(at) {
+		r.LastFailedAt = prev
+		return nil
+	}
+	// 之后还有未确认的预留, 它们撤销时应恢复到本次预留之前的时间
+	for k, t := range r.reserved {
+		if t.Equal(at) {
+			r.reserved[k] = prev
+		}
+	}
+	return nil
+}
+
+func (s *MemoryStore) Reset(ctx context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.records, key)
+	return nil
+}
@@ -0,0 +1,61 @@
+package mailer
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logc"
+)
+
+// LogMailer 只把邮件内容写入日志, 用于本地开发
+type LogMailer struct{}
+
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	logc.Infof(ctx, "[mailer] to: %s, subject: %s\n%s", msg.To, msg.Subject, msg.Body)
+	return nil
+}
+
+// FileMailer 把每封邮件保存为 dir 下的 .eml 文件, 用于本地开发和测试
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{
+		dir:  dir,
+		from: from,
+	}
+}
+
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := encode(m.from, msg)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(m.dir, 0755); err != nil {
+		return err
+	}
+
+	// 文件名以时间开头便于按发送顺序查看, 收件人中的特殊字符替换为下划线
+	to := strings.Map(func(r rune) rune {
+		if r == '@' || r == '.' || r == '-' || r == '_' || r >= '0' && r <= '9' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' {
+			return r
+		}
+		return '_'
+	}, msg.To)
+	id, err := messageId()
+	if err != nil {
+		return err
+	}
+	name := fmt.Sprintf("%s-%s-%s.eml", time.Now().Format("20060102T150405.000000000"), to, id[:8])
+	return os.WriteFile(filepath.Join(m.dir, name), raw, 0644)
+}
@@ -0,0 +1,113 @@
+package mailer
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"mime"
+	"mime/quotedprintable"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// 支持的发送方式
+const (
+	DriverLog  = "log"
+	DriverFile = "file"
+	DriverSMTP = "smtp"
+)
+
+// Config 邮件发送配置
+type Config struct {
+	Driver   string `json:",default=log,options=log|file|smtp"` // log: 只写日志; file: 保存为 .eml 文件; smtp: 通过 SMTP 服务器发送
+	Host     string `json:",optional"`
+	Port     int    `json:",default=587"` // 465 使用 TLS 直连, 其他端口在服务器支持时使用 STARTTLS
+	Username string `json:",optional"`
+	Password string `json:",optional"`
+	From     string `json:",optional"`         // 发件地址, 为空时使用 Username
+	FromName string `json:",default=AIFriend"` // 发件人名称
+	Dir      string `json:",default=mails"`    // file 模式下邮件的保存目录
+}
+
+// Message 一封纯文本邮件
+type Message struct {
+	To      string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送方式
+type Mailer interface {
+	Send(ctx context.Context, msg Message) error
+}
+
+// NewMailer 根据配置创建邮件发送方式
+func NewMailer(c Config) (Mailer, error) {
+	from := c.From
+	if from == "" {
+		from = c.Username
+	}
+
+	switch c.Driver {
+	case "", DriverLog:
+		return NewLogMailer(), nil
+	case DriverFile:
+		return NewFileMailer(c.Dir, address(c.FromName, from)), nil
+	case DriverSMTP:
+		if c.Host == "" || from == "" {
+			return nil, fmt.Errorf("mailer: Host and From are required for smtp driver")
+		}
+		return NewSMTPMailer(c, from), nil
+	default:
+		return nil, fmt.Errorf("mailer: unknown driver %q", c.Driver)
+	}
+}
+
+// address 生成带名称的邮件地址, 非 ASCII 名称按 RFC 2047 编码
+func address(name, addr string) string {
+	if addr == "" {
+		addr = "noreply@localhost"
+	}
+	return (&mail.Address{Name: name, Address: addr}).String()
+}
+
+// encode 按 RFC 5322 生成邮件原文, 正文使用 quoted-printable 编码
+func encode(from string, msg Message) ([]byte, error) {
+	var buf bytes.Buffer
+	header := func(key, value string) {
+		buf.WriteString(key + ": " + value + "\r\n")
+	}
+	header("From", from)
+	header("To", msg.To)
+	header("Subject", mime.BEncoding.Encode("UTF-8", msg.Subject))
+	header("Date", time.Now().Format(time.RFC1123Z))
+	id, err := messageId()
+	if err != nil {
+		return nil, err
+	}
+	header("Message-ID", "<"+id+"@aifriend>")
+	header("MIME-Version", "1.0")
+	header("Content-Type", "text/plain; charset=UTF-8")
+	header("Content-Transfer-Encoding", "quoted-printable")
+	buf.WriteString("\r\n")
+
+	w := quotedprintable.NewWriter(&buf)
+	if _, err := w.Write([]byte(strings.ReplaceAll(msg.Body, "\n", "\r\n"))); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+func messageId() (string, error) {
+	b := make([]byte, 12)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(b), nil
+}
@@ -0,0 +1,85 @@
+package mailer
+
+import (
+	"context"
+	"crypto/tls"
+	"net"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// dialTimeout 连接 SMTP 服务器的超时时间
+const dialTimeout = 10 * time.Second
+
+// SMTPMailer 通过 SMTP 服务器发送邮件
+type SMTPMailer struct {
+	c    Config
+	from string
+}
+
+func NewSMTPMailer(c Config, from string) *SMTPMailer {
+	return &SMTPMailer{
+		c:    c,
+		from: from,
+	}
+}
+
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	raw, err := encode(address(m.c.FromName, m.from), msg)
+	if err != nil {
+		return err
+	}
+
+	addr := net.JoinHostPort(m.c.Host, strconv.Itoa(m.c.Port))
+	dialer := &net.Dialer{Timeout: dialTimeout}
+	var conn net.Conn
+	if m.c.Port == 465 {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: &tls.Config{ServerName: m.c.Host}}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return err
+	}
+	if deadline, ok := ctx.Deadline(); ok {
+		conn.SetDeadline(deadline)
+	}
+
+	client, err := smtp.NewClient(conn, m.c.Host)
+	if err != nil {
+		conn.Close()
+		return err
+	}
+	defer client.Close()
+
+	if ok, _ := client.Extension("STARTTLS"); ok && m.c.Port != 465 {
+		if err := client.StartTLS(&tls.Config{ServerName: m.c.Host}); err != nil {
+			return err
+		}
+	}
+	if m.c.Username != "" {
+		if err := client.Auth(smtp.PlainAuth("", m.c.Username, m.c.Password, m.c.Host)); err != nil {
+			return err
+		}
+	}
+
+	if err := client.Mail(m.from); err != nil {
+		return err
+	}
+	if err := client.Rcpt(msg.To); err != nil {
+		return err
+	}
+	w, err := client.Data()
+	if err != nil {
+		return err
+	}
+	if _, err := w.Write(raw); err != nil {
+		w.Close()
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return err
+	}
+	return client.Quit()
+}
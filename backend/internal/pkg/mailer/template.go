@@ -0,0 +1,114 @@
+package mailer
+
+import (
+	"fmt"
+	"strings"
+	"text/template"
+	"time"
+)
+
+// 邮件模板名称
+const (
+	TemplateVerifyEmail   = "verify_email"
+	TemplateResetPassword = "reset_password"
+)
+
+// Data 渲染邮件模板的数据
+type Data struct {
+	Username string
+	Link     string
+	Expire   time.Duration // 链接有效期
+}
+
+// 邮件同时包含中文和英文, 收件人不需要设置语言
+var templates = map[string]struct {
+	subject string
+	body    *template.Template
+}{
+	TemplateVerifyEmail: {
+		subject: "验证你的邮箱 / Verify your email",
+		body: parse(`{{.Username}}，你好：
+
+请点击下面的链接验证你的 AIFriend 邮箱，链接 {{zh .Expire}}内有效：
+
+{{.Link}}
+
+如果你没有注册 AIFriend 或绑定过这个邮箱，请忽略本邮件。
+
+----------------------------------------
+
+Hi {{.Username}},
+
+Please confirm your email address for AIFriend by opening the link below. The link expires in {{en .Expire}}.
+
+{{.Link}}
+
+If you did not sign up for AIFriend or add this email address, you can safely ignore this email.
+`),
+	},
+	TemplateResetPassword: {
+		subject: "重置你的密码 / Reset your password",
+		body: parse(`{{.Username}}，你好：
+
+我们收到了重置你的 AIFriend 密码的请求。请点击下面的链接设置新密码，链接 {{zh .Expire}}内有效且只能使用一次：
+
+{{.Link}}
+
+重置密码后，所有设备都需要使用新密码重新登录。如果这不是你本人的操作，请忽略本邮件，你的密码不会改变。
+
+----------------------------------------
+
+Hi {{.Username}},
+
+We received a request to reset your AIFriend password. Open the link below to choose a new one. The link expires in {{en .Expire}} and can only be used once.
+
+{{.Link}}
+
+After the reset you will need to sign in again with the new password on every device. If you did not request this, please ignore this email and your password will stay the same.
+`),
+	},
+}
+
+// Render 用指定模板生成发给 to 的邮件
+func Render(name, to string, data Data) (Message, error) {
+	t, ok := templates[name]
+	if !ok {
+		return Message{}, fmt.Errorf("mailer: unknown template %q", name)
+	}
+
+	var body strings.Builder
+	if err := t.body.Execute(&body, data); err != nil {
+		return Message{}, err
+	}
+	return Message{
+		To:      to,
+		Subject: t.subject,
+		Body:    body.String(),
+	}, nil
+}
+
+func parse(text string) *template.Template {
+	return template.Must(template.New("").Funcs(template.FuncMap{
+		"zh": zhDuration,
+		"en": enDuration,
+	}).Parse(text))
+}
+
+// zhDuration 整小时显示为小时, 否则显示为分钟
+func zhDuration(d time.Duration) string {
+	if d >= time.Hour && d%time.Hour == 0 {
+		return fmt.Sprintf("%d 小时", d/time.Hour)
+	}
+	return fmt.Sprintf("%d 分钟", max(d/time.Minute, 1))
+}
+
+func enDuration(d time.Duration) string {
+	n, unit := int64(max(d/time.Minute, 1)), "minute"
+	if d >= time.Hour && d%time.Hour == 0 {
+		n, unit = int64(d/time.Hour), "hour"
+	}
+	if n != 1 {
+		unit += "s"
+	}
+	return fmt.Sprintf("%d %s", n, unit)
+}
@@ -0,0 +1,334 @@
+package memory
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"unicode"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/pkg/vector"
+
+	"gorm.io/gorm"
+)
+
+// Config 长期记忆配置
+type Config struct {
+	Enabled    bool    `json:",default=true"` // 是否在对话后自动提取记忆
+	MaxInject  int     `json:",default=8"`    // 每次对话最多注入的记忆条数
+	MaxPerPair int     `json:",default=200"`  // 每个用户-角色对最多保存的记忆条数, 超出时淘汰最旧的未置顶记忆
+	MaxLength  int     `json:",default=200"`  // 单条记忆的最大字数
+	MinScore   float64 `json:",default=0.1"`  // 注入记忆所需的最低相似度
+}
+
+var ErrInvalidExtraction = errors.New("memory: extraction result is not a json array")
+
+const extractPrompt = `你负责为一个陪伴聊天应用整理长期记忆。请从下面的对话中找出值得长期记住的、关于用户本人的事实，
+例如姓名、喜好、家人和宠物、工作学习、重要经历和约定。
+要求：
+1. 每条记忆是一句简短、独立的陈述，以"用户"作为主语；
+2. 忽略寒暄、临时情绪和角色自己的设定；
+3. 不要重复[已有记忆]中已经包含的内容；
+4. 只输出 JSON 字符串数组，例如 ["用户的猫叫Mochi"]，没有新记忆时输出 []。`
+
+const (
+	extractContextSize = 50  // 提取时作为参考的已有记忆条数
+	indexBatchSize     = 100 // 补建向量时每批处理的记忆条数
+)
+
+// Store 长期记忆的存取、检索和提取
+type Store struct {
+	db       *gorm.DB
+	provider llm.Provider
+	index    vector.Index
+	model    string
+	c        Config
+}
+
+func NewStore(db *gorm.DB, provider llm.Provider, index vector.Index, model string, c Config) *Store {
+	return &Store{
+		db:       db,
+		provider: provider,
+		index:    index,
+		model:    model,
+		c:        c,
+	}
+}
+
+// Relevant 返回需要注入提示词的记忆: 置顶记忆优先, 其余按与 query 的语义相似度排序.
+// query 为空或向量检索失败时按更新时间取最近的记忆.
+func (s *Store) Relevant(ctx context.Context, userId, characterId int64, query string) ([]model.Memory, error) {
+	if s.c.MaxInject <= 0 {
+		return nil, nil
+	}
+
+	var memories []model.Memory
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND character_id = ?", userId, characterId).
+		Order("pinned DESC, updated_at DESC").Find(&memories).Error; err != nil {
+		return nil, err
+	}
+
+	result := make([]model.Memory, 0, s.c.MaxInject)
+	rest := make([]model.Memory, 0, len(memories))
+	for _, m := range memories {
+		if m.Pinned {
+			result = append(result, m)
+		} else {
+			rest = append(rest, m)
+		}
+	}
+
+	var err error
+	if query != "" && len(rest) > 0 {
+		var ranked []model.Memory
+		if ranked, err = s.rank(ctx, userId, characterId, query, rest); err == nil {
+			rest = ranked
+		}
+	}
+
+	result = append(result, rest...)
+	if len(result) > s.c.MaxInject {
+		result = result[:s.c.MaxInject]
+	}
+	return result, err
+}
+
+// rank 按与 query 的相似度筛选并排序记忆
+func (s *Store) rank(ctx context.Context, userId, characterId int64, query string, memories []model.Memory) ([]model.Memory, error) {
+	vectors, err := s.provider.Embed(ctx, []string{query})
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := s.index.Search(ctx, vectors[0], vector.Filter{
+		Kind:        vector.KindMemory,
+		UserId:      userId,
+		CharacterId: characterId,
+	}, s.c.MaxInject)
+	if err != nil {
+		return nil, err
+	}
+
+	byId := make(map[int64]model.Memory, len(memories))
+	for _, m := range memories {
+		byId[m.Id] = m
+	}
+
+	ranked := make([]model.Memory, 0, len(results))
+	for _, r := range results {
+		if m, ok := byId[r.RefId]; ok && r.Score >= s.c.MinScore {
+			ranked = append(ranked, m)
+		}
+	}
+	return ranked, nil
+}
+
+// Index 计算记忆的向量并写入索引, 新建或编辑记忆后调用
+func (s *Store) Index(ctx context.Context, memories ...model.Memory) error {
+	if len(memories) == 0 {
+		return nil
+	}
+
+	vectors, err := s.provider.Embed(ctx, Contents(memories))
+	if err != nil {
+		return err
+	}
+
+	items := make([]vector.Item, len(memories))
+	for i, m := range memories {
+		items[i] = vector.Item{
+			Kind:        vector.KindMemory,
+			RefId:       m.Id,
+			UserId:      m.UserId,
+			CharacterId: m.CharacterId,
+			Vector:      vectors[i],
+		}
+	}
+	return s.index.Upsert(ctx, items...)
+}
+
+// IndexMissing 为尚未写入索引的记忆补建向量, 用于启用向量检索前已存在的记忆
+func (s *Store) IndexMissing(ctx context.Context) error {
+	for {
+		var memories []model.Memory
+		if err := s.db.WithContext(ctx).Model(&model.Memory{}).
+			Joins("LEFT JOIN embeddings ON embeddings.kind = ? AND embeddings.ref_id = memories.id", vector.KindMemory).
+			Where("embeddings.id IS NULL").Order("memories.id ASC").Limit(indexBatchSize).
+			Find(&memories).Error; err != nil {
+			return err
+		}
+		if len(memories) == 0 {
+			return nil
+		}
+		if err := s.Index(ctx, memories...); err != nil {
+			return err
+		}
+	}
+}
+
+// Forget 从索引中移除已删除的记忆
+func (s *Store) Forget(ctx context.Context, ids ...int64) error {
+	return s.index.Delete(ctx, vector.KindMemory, ids...)
+}
+
+// Extract 调用模型从对话片段中提取新的记忆并保存, 返回新增的记忆
+func (s *Store) Extract(ctx context.Context, userId, characterId int64, messages []model.Message) ([]model.Memory, error) {
+	if !s.c.Enabled || len(messages) == 0 {
+		return nil, nil
+	}
+
+	var existing []model.Memory
+	if err := s.db.WithContext(ctx).Where("user_id = ? AND character_id = ?", userId, characterId).
+		Order("updated_at DESC").Limit(extractContextSize).Find(&existing).Error; err != nil {
+		return nil, err
+	}
+
+	resp, err := s.provider.Chat(ctx, &llm.ChatRequest{
+		Model: s.model,
+		Messages: []llm.Message{
+			{Role: llm.RoleSystem, Content: extractPrompt},
+			{Role: llm.RoleUser, Content: extractInput(existing, messages)},
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	facts, err := parseFacts(resp.Content)
+	if err != nil {
+		return nil, err
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, m := range existing {
+		seen[normalize(m.Content)] = true
+	}
+
+	sourceId := messages[len(messages)-1].Id
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == model.MessageRoleUser {
+			sourceId = messages[i].Id
+			break
+		}
+	}
+
+	var created []model.Memory
+	for _, fact := range facts {
+		fact = strings.TrimSpace(fact)
+		if fact == "" || len([]rune(fact)) > s.c.MaxLength || seen[normalize(fact)] {
+			continue
+		}
+		seen[normalize(fact)] = true
+		created = append(created, model.Memory{
+			UserId:          userId,
+			CharacterId:     characterId,
+			Content:         fact,
+			SourceMessageId: sourceId,
+		})
+	}
+	if len(created) == 0 {
+		return nil, nil
+	}
+
+	var evicted []int64
+	err = s.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Create(&created).Error; err != nil {
+			return err
+		}
+		evicted, err = s.evict(tx, userId, characterId)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.Forget(ctx, evicted...); err != nil {
+		return created, err
+	}
+	if err := s.Index(ctx, created...); err != nil {
+		return created, err
+	}
+	return created, nil
+}
+
+// evict 超出数量上限时淘汰最早更新的未置顶记忆, 返回被淘汰的记忆ID
+func (s *Store) evict(tx *gorm.DB, userId, characterId int64) ([]int64, error) {
+	if s.c.MaxPerPair <= 0 {
+		return nil, nil
+	}
+
+	var total int64
+	if err := tx.Model(&model.Memory{}).Where("user_id = ? AND character_id = ?", userId, characterId).
+		Count(&total).Error; err != nil {
+		return nil, err
+	}
+	overflow := int(total) - s.c.MaxPerPair
+	if overflow <= 0 {
+		return nil, nil
+	}
+
+	var ids []int64
+	if err := tx.Model(&model.Memory{}).
+		Where("user_id = ? AND character_id = ? AND pinned = ?", userId, characterId, false).
+		Order("updated_at ASC, id ASC").Limit(overflow).Pluck("id", &ids).Error; err != nil {
+		return nil, err
+	}
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	return ids, tx.Delete(&model.Memory{}, ids).Error
+}
+
+// Contents 取出记忆文本, 用于渲染提示词
+func Contents(memories []model.Memory) []string {
+	contents := make([]string, len(memories))
+	for i, m := range memories {
+		contents[i] = m.Content
+	}
+	return contents
+}
+
+func extractInput(existing []model.Memory, messages []model.Message) string {
+	var sb strings.Builder
+	if len(existing) > 0 {
+		sb.WriteString("[已有记忆]\n")
+		for _, m := range existing {
+			sb.WriteString("- ")
+			sb.WriteString(m.Content)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString("[对话]\n")
+	for _, m := range messages {
+		sb.WriteString(m.Role)
+		sb.WriteString(": ")
+		sb.WriteString(m.Content)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// parseFacts 解析模型输出的 JSON 数组, 兼容前后夹带说明文字或代码块的情况
+func parseFacts(content string) ([]string, error) {
+	start := strings.Index(content, "[")
+	end := strings.LastIndex(content, "]")
+	if start < 0 || end < start {
+		return nil, ErrInvalidExtraction
+	}
+
+	var facts []string
+	if err := json.Unmarshal([]byte(content[start:end+1]), &facts); err != nil {
+		return nil, ErrInvalidExtraction
+	}
+	return facts, nil
+}
+
+// normalize 去除标点和空白并转为小写, 用于判断记忆是否重复
+func normalize(s string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(s, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+	}), ""))
+}
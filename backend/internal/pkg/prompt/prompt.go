@@ -0,0 +1,137 @@
+package prompt
+
+import (
+	"strings"
+	"text/template"
+
+	"aifriend/internal/model"
+)
+
+// MaxTemplateLength 自定义模板的最大字符数
+const MaxTemplateLength = 8000
+
+// DefaultTemplate 默认系统提示词模板.
+// 模板使用 text/template 语法, {{char}} 和 {{user}} 分别展开为角色名和用户名.
+const DefaultTemplate = `你是{{char}}。请始终以{{char}}的身份、性格和语气与{{user}}对话，不要跳出角色，也不要提及自己是AI。
+{{if .Profile}}
+[角色设定]
+{{.Profile}}
+{{end}}{{if .Personality}}
+[性格]
+{{.Personality}}
+{{end}}{{if .SpeakingStyle}}
+[说话风格]
+{{.SpeakingStyle}}
+{{end}}{{if .Scenario}}
+[场景]
+{{.Scenario}}
+{{end}}{{if .Lore}}
+[世界设定]
+{{range .Lore}}{{.}}
+{{end}}{{end}}{{if .Knowledge}}
+[参考资料]
+{{range .Knowledge}}{{.}}
+---
+{{end}}{{end}}{{if .UserProfile}}
+[关于{{user}}]
+{{.UserProfile}}
+{{end}}{{if .Memories}}
+[你记得的关于{{user}}的事]
+{{range .Memories}}- {{.}}
+{{end}}{{end}}{{if .ExampleDialogues}}
+[对话示例]
+{{.ExampleDialogues}}
+{{end}}`
+
+// Data 渲染模板时可用的字段
+type Data struct {
+	Char             string
+	User             string
+	Profile          string
+	Personality      string
+	Scenario         string
+	Greeting         string
+	ExampleDialogues string
+	SpeakingStyle    string
+	UserProfile      string
+	Memories         []string // 与当前对话相关的长期记忆
+	Knowledge        []string // 知识库中与当前对话相关的片段
+	Lore             []string // 被最近消息触发的世界设定条目
+}
+
+// NewData 由角色和用户信息构造模板数据, 字段中的 {{char}}/{{user}} 宏会被展开
+func NewData(character *model.Character, user *model.User) Data {
+	data := Data{
+		Char: character.Name,
+		User: "用户",
+	}
+	if user != nil {
+		data.User = user.Username
+		data.UserProfile = user.Profile
+	}
+
+	data.Profile = data.Expand(character.Profile)
+	data.Personality = data.Expand(character.Personality)
+	data.Scenario = data.Expand(character.Scenario)
+	data.Greeting = data.Expand(character.Greeting)
+	data.ExampleDialogues = data.Expand(character.ExampleDialogues)
+	data.SpeakingStyle = data.Expand(character.SpeakingStyle)
+	data.UserProfile = data.Expand(data.UserProfile)
+
+	return data
+}
+
+// Validate 以空数据试渲染模板, 用于保存自定义模板前检查语法和字段引用
+func Validate(text string) error {
+	_, err := Render(text, Data{})
+	return err
+}
+
+// Render 渲染模板, text 为空时使用默认模板
+func Render(text string, data Data) (string, error) {
+	if strings.TrimSpace(text) == "" {
+		text = DefaultTemplate
+	}
+
+	tmpl, err := template.New("prompt").Option("missingkey=error").Funcs(data.funcs()).Parse(text)
+	if err != nil {
+		return "", err
+	}
+
+	var sb strings.Builder
+	if err := tmpl.Execute(&sb, data); err != nil {
+		return "", err
+	}
+
+	return strings.TrimSpace(sb.String()), nil
+}
+
+// BuildSystemPrompt 渲染角色的系统提示词.
+// 自定义模板渲染失败时回退到默认模板, 同时返回该错误以便记录.
+func BuildSystemPrompt(tmpl string, data Data) (string, error) {
+	text, err := Render(tmpl, data)
+	if err != nil {
+		fallback, fallbackErr := Render(DefaultTemplate, data)
+		if fallbackErr != nil {
+			return "", fallbackErr
+		}
+		return fallback, err
+	}
+
+	return text, nil
+}
+
+func (d Data) funcs() template.FuncMap {
+	return template.FuncMap{
+		"char": func() string { return d.Char },
+		"user": func() string { return d.User },
+	}
+}
+
+// Expand 展开文本中的 {{char}} 和 {{user}} 宏
+func (d Data) Expand(text string) string {
+	if text == "" {
+		return ""
+	}
+	return strings.NewReplacer("{{char}}", d.Char, "{{user}}", d.User).Replace(text)
+}
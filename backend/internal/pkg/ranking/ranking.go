@@ -0,0 +1,85 @@
+package ranking
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+)
+
+// Config 热门榜和推荐的计算配置
+type Config struct {
+	Enabled            bool    `json:",default=true"` // 是否在后台定期计算
+	Interval           int     `json:",default=600"`  // 计算间隔(秒)
+	WindowDays         int     `json:",default=7"`    // 热度统计最近多少天的行为
+	HalfLife           float64 `json:",default=24"`   // 热度衰减的半衰期(小时)
+	ConversationWeight float64 `json:",default=5"`    // 每个新会话计入的热度
+	MessageWeight      float64 `json:",default=1"`    // 每条用户消息计入的热度
+	LikeWeight         float64 `json:",default=3"`    // 每次点赞计入的热度
+	ForkWeight         float64 `json:",default=8"`    // 每次被复制计入的热度
+	HistoryDays        int     `json:",default=90"`   // 推荐时参考最近多少天的会话
+	SourceCharacters   int     `json:",default=3"`    // 推荐时参考每个用户最近聊过的角色数
+	MaxRecommendations int     `json:",default=20"`   // 每个用户保存的推荐数
+}
+
+// insertBatchSize 写入计算结果时每批插入的行数
+const insertBatchSize = 500
+
+// Ranker 计算公开角色的热门榜和每个用户的推荐, 结果写入数据库供接口直接查询
+type Ranker struct {
+	db *gorm.DB
+	c  Config
+}
+
+func NewRanker(db *gorm.DB, c Config) *Ranker {
+	return &Ranker{
+		db: db,
+		c:  c,
+	}
+}
+
+// Run 立即计算一次, 之后按配置的间隔定期计算, 直到 ctx 结束
+func (r *Ranker) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Duration(max(r.c.Interval, 1)) * time.Second)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(ctx); err != nil {
+			logx.Errorf("refresh rankings failed: %v", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh 重新计算热门榜和推荐
+func (r *Ranker) Refresh(ctx context.Context) error {
+	now := time.Now()
+	if err := r.refreshTrending(ctx, now); err != nil {
+		return fmt.Errorf("trending: %w", err)
+	}
+	if err := r.refreshRecommendations(ctx, now); err != nil {
+		return fmt.Errorf("recommendations: %w", err)
+	}
+	return nil
+}
+
+// replace 在事务中用 rows 替换表中的全部数据
+func replace[T any](db *gorm.DB, rows []T) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		var zero T
+		if err := tx.Where("1 = 1").Delete(&zero).Error; err != nil {
+			return err
+		}
+		if len(rows) == 0 {
+			return nil
+		}
+		return tx.CreateInBatches(rows, insertBatchSize).Error
+	})
+}
@@ -0,0 +1,165 @@
+package ranking
+
+import (
+	"cmp"
+	"context"
+	"slices"
+	"time"
+
+	"aifriend/internal/model"
+)
+
+// 推荐分由标签重合度和共同使用度加权得到, 两者都在 0-1 之间
+const (
+	tagWeight      = 0.4
+	coUsageWeight  = 0.6
+	maxUserHistory = 50 // 统计共同使用时每个用户最多参考的最近角色数
+)
+
+// usage 用户与角色的一个会话
+type usage struct {
+	UserId      int64
+	CharacterId int64
+}
+
+// characterTag 角色与标签的关联
+type characterTag struct {
+	CharacterId int64
+	TagId       int64
+}
+
+// refreshRecommendations 为最近有会话的用户推荐公开角色: 以用户最近聊过的几个角色为依据,
+// 候选角色与依据角色的标签越接近、被同一批用户聊过的次数越多, 推荐分越高.
+// 用户聊过的角色和自己创建的角色不会被推荐
+func (r *Ranker) refreshRecommendations(ctx context.Context, now time.Time) error {
+	db := r.db.WithContext(ctx)
+	since := now.AddDate(0, 0, -max(r.c.HistoryDays, 1))
+
+	// 用户ID -> 聊过的角色ID, 最近的在前
+	history := make(map[int64][]int64)
+	chatted := make(map[int64]map[int64]bool)
+	err := scan(db.Model(&model.Conversation{}).
+		Select("user_id, character_id").
+		Where("updated_at >= ?", since).
+		Order("updated_at DESC, id DESC"), func(u *usage) {
+		if chatted[u.UserId] == nil {
+			chatted[u.UserId] = make(map[int64]bool)
+		}
+		if !chatted[u.UserId][u.CharacterId] {
+			chatted[u.UserId][u.CharacterId] = true
+			history[u.UserId] = append(history[u.UserId], u.CharacterId)
+		}
+	})
+	if err != nil {
+		return err
+	}
+
+	// 角色ID -> 与之被同一用户聊过的角色ID -> 用户数
+	coUsage := make(map[int64]map[int64]int)
+	for _, ids := range history {
+		ids = ids[:min(len(ids), maxUserHistory)]
+		for i, a := range ids {
+			for _, b := range ids[i+1:] {
+				addCoUsage(coUsage, a, b)
+				addCoUsage(coUsage, b, a)
+			}
+		}
+	}
+
+	var public []model.Character
+	if err := db.Select("id", "user_id").
+		Where("visibility = ?", model.VisibilityPublic).
+		Find(&public).Error; err != nil {
+		return err
+	}
+	owners := make(map[int64]int64, len(public))
+	for _, c := range public {
+		owners[c.Id] = c.UserId
+	}
+
+	var links []characterTag
+	if err := db.Table("character_tags").Select("character_id, tag_id").Scan(&links).Error; err != nil {
+		return err
+	}
+	tags := make(map[int64][]int64)
+	tagged := make(map[int64][]int64) // 标签ID -> 带有该标签的公开角色ID
+	for _, l := range links {
+		tags[l.CharacterId] = append(tags[l.CharacterId], l.TagId)
+		if _, ok := owners[l.CharacterId]; ok {
+			tagged[l.TagId] = append(tagged[l.TagId], l.CharacterId)
+		}
+	}
+
+	var recommendations []model.CharacterRecommendation
+	for userId, ids := range history {
+		best := make(map[int64]model.CharacterRecommendation)
+		for _, source := range ids[:min(len(ids), max(r.c.SourceCharacters, 1))] {
+			candidates := make(map[int64]bool)
+			maxCoUsage := 0
+			for id, n := range coUsage[source] {
+				candidates[id] = true
+				maxCoUsage = max(maxCoUsage, n)
+			}
+			for _, tag := range tags[source] {
+				for _, id := range tagged[tag] {
+					candidates[id] = true
+				}
+			}
+
+			for id := range candidates {
+				owner, ok := owners[id]
+				if !ok || owner == userId || chatted[userId][id] {
+					continue
+				}
+				score := tagWeight * jaccard(tags[source], tags[id])
+				if maxCoUsage > 0 {
+					score += coUsageWeight * float64(coUsage[source][id]) / float64(maxCoUsage)
+				}
+				if score > best[id].Score {
+					best[id] = model.CharacterRecommendation{
+						UserId:            userId,
+						CharacterId:       id,
+						SourceCharacterId: source,
+						Score:             score,
+						ComputedAt:        now,
+					}
+				}
+			}
+		}
+
+		list := make([]model.CharacterRecommendation, 0, len(best))
+		for _, rec := range best {
+			list = append(list, rec)
+		}
+		slices.SortFunc(list, func(a, b model.CharacterRecommendation) int {
+			if c := cmp.Compare(b.Score, a.Score); c != 0 {
+				return c
+			}
+			return cmp.Compare(a.CharacterId, b.CharacterId)
+		})
+		recommendations = append(recommendations, list[:min(len(list), r.c.MaxRecommendations)]...)
+	}
+
+	return replace(db, recommendations)
+}
+
+func addCoUsage(coUsage map[int64]map[int64]int, a, b int64) {
+	if coUsage[a] == nil {
+		coUsage[a] = make(map[int64]int)
+	}
+	coUsage[a][b]++
+}
+
+// jaccard 两组标签的交集与并集之比
+func jaccard(a, b []int64) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	intersection := 0
+	for _, tag := range a {
+		if slices.Contains(b, tag) {
+			intersection++
+		}
+	}
+	return float64(intersection) / float64(len(a)+len(b)-intersection)
+}
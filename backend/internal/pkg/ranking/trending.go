@@ -0,0 +1,96 @@
+package ranking
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"aifriend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// activity 一次与角色相关的用户行为
+type activity struct {
+	CharacterId int64
+	CreatedAt   time.Time
+}
+
+// refreshTrending 按统计窗口内的会话、消息、点赞和复制计算公开角色的热度.
+// 每次行为的热度按发生时间指数衰减, 经过一个半衰期后只计一半
+func (r *Ranker) refreshTrending(ctx context.Context, now time.Time) error {
+	db := r.db.WithContext(ctx)
+	since := now.AddDate(0, 0, -max(r.c.WindowDays, 1))
+	halfLife := max(r.c.HalfLife, 1)
+
+	sources := []struct {
+		weight float64
+		query  *gorm.DB
+	}{
+		{r.c.ConversationWeight, db.Model(&model.Conversation{}).
+			Select("character_id, created_at").
+			Where("created_at >= ?", since)},
+		// 只统计用户消息, 回复和重新生成不计入热度
+		{r.c.MessageWeight, db.Model(&model.Message{}).
+			Select("conversations.character_id, messages.created_at").
+			Joins("JOIN conversations ON conversations.id = messages.conversation_id AND conversations.deleted_at IS NULL").
+			Where("messages.role = ? AND messages.created_at >= ?", model.MessageRoleUser, since)},
+		{r.c.LikeWeight, db.Model(&model.CharacterLike{}).
+			Select("character_id, created_at").
+			Where("created_at >= ?", since)},
+		{r.c.ForkWeight, db.Model(&model.Character{}).
+			Select("forked_from_id AS character_id, created_at").
+			Where("forked_from_id <> 0 AND created_at >= ?", since)},
+	}
+
+	scores := make(map[int64]float64)
+	for _, s := range sources {
+		if s.weight == 0 {
+			continue
+		}
+		err := scan(s.query, func(a *activity) {
+			age := max(now.Sub(a.CreatedAt).Hours(), 0)
+			scores[a.CharacterId] += s.weight * math.Exp2(-age/halfLife)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	var public []int64
+	if err := db.Model(&model.Character{}).
+		Where("visibility = ?", model.VisibilityPublic).
+		Pluck("id", &public).Error; err != nil {
+		return err
+	}
+
+	rankings := make([]model.CharacterRanking, 0, len(public))
+	for _, id := range public {
+		if score := scores[id]; score > 0 {
+			rankings = append(rankings, model.CharacterRanking{
+				CharacterId: id,
+				Score:       score,
+				ComputedAt:  now,
+			})
+		}
+	}
+	return replace(db, rankings)
+}
+
+// scan 逐行读取查询结果, 避免一次性加载统计窗口内的全部行为
+func scan[T any](query *gorm.DB, fn func(*T)) error {
+	rows, err := query.Rows()
+	if err != nil {
+		return err
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var row T
+		if err := query.ScanRows(rows, &row); err != nil {
+			return err
+		}
+		fn(&row)
+	}
+	return rows.Err()
+}
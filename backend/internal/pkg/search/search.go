@@ -0,0 +1,152 @@
+package search
+
+import (
+	"strings"
+	"unicode/utf8"
+
+	"aifriend/internal/model"
+
+	"github.com/zeromicro/go-zero/core/logx"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Config 角色搜索配置
+type Config struct {
+	FullText       bool `json:",default=true"` // MySQL 下使用 ngram 全文索引, 不可用时回退为 LIKE
+	NgramTokenSize int  `json:",default=2"`    // 与 MySQL 的 ngram_token_size 一致, 更短的词无法命中全文索引
+}
+
+// fullTextIndex 角色名称和简介上的全文索引
+const fullTextIndex = "ft_character_search"
+
+// booleanOperators BOOLEAN MODE 中有特殊含义的字符, 用户输入中的这些字符会被去掉
+const booleanOperators = `+-<>()~*"@`
+
+// Searcher 按关键词匹配角色的名称、简介和标签
+type Searcher struct {
+	fullText  bool
+	tokenSize int
+}
+
+// NewSearcher 创建搜索器. 数据库为 MySQL 且开启全文检索时确保全文索引存在,
+// 创建失败(如不支持 ngram 解析器)时记录日志并回退为 LIKE 匹配
+func NewSearcher(db *gorm.DB, c Config) *Searcher {
+	s := &Searcher{tokenSize: max(c.NgramTokenSize, 1)}
+	if !c.FullText || db.Dialector.Name() != "mysql" {
+		return s
+	}
+
+	if !db.Migrator().HasIndex(&model.Character{}, fullTextIndex) {
+		if err := db.Exec("CREATE FULLTEXT INDEX " + fullTextIndex +
+			" ON characters (name, profile) WITH PARSER ngram").Error; err != nil {
+			logx.Errorf("create fulltext index failed, falling back to LIKE: %v", err)
+			return s
+		}
+	}
+
+	s.fullText = true
+	return s
+}
+
+// FullText 是否使用全文索引
+func (s *Searcher) FullText() bool {
+	return s.fullText
+}
+
+// Match 为查询加上关键词条件: 名称或简介包含所有关键词, 或者带有与关键词同名的标签
+func (s *Searcher) Match(query *gorm.DB, keyword string) *gorm.DB {
+	terms := Terms(keyword)
+	if len(terms) == 0 {
+		return query
+	}
+
+	tagged := query.Session(&gorm.Session{NewDB: true}).
+		Table("character_tags").
+		Select("character_tags.character_id").
+		Joins("JOIN tags ON tags.id = character_tags.tag_id").
+		Where("tags.name IN ?", tagNames(keyword, terms))
+
+	if s.useFullText(terms) {
+		return query.Where("MATCH(characters.name, characters.profile) AGAINST (? IN BOOLEAN MODE) OR characters.id IN (?)",
+			booleanQuery(terms), tagged)
+	}
+
+	text := query.Session(&gorm.Session{NewDB: true})
+	for _, term := range terms {
+		pattern := "%" + EscapeLike(term) + "%"
+		text = text.Where("characters.name LIKE ? ESCAPE '!' OR characters.profile LIKE ? ESCAPE '!'", pattern, pattern)
+	}
+	return query.Where(query.Session(&gorm.Session{NewDB: true}).Where(text).Or("characters.id IN (?)", tagged))
+}
+
+// Relevance 返回按相关度降序排列的排序表达式: 全文检索按匹配得分, 否则名称命中的排在前面
+func (s *Searcher) Relevance(keyword string) clause.Expression {
+	terms := Terms(keyword)
+	if len(terms) == 0 {
+		return clause.Expr{SQL: "characters.id DESC"}
+	}
+	if s.useFullText(terms) {
+		return clause.Expr{
+			SQL:  "MATCH(characters.name, characters.profile) AGAINST (? IN BOOLEAN MODE) DESC, characters.id DESC",
+			Vars: []interface{}{booleanQuery(terms)},
+		}
+	}
+	return clause.Expr{
+		SQL:  "CASE WHEN characters.name LIKE ? ESCAPE '!' THEN 0 ELSE 1 END, characters.id DESC",
+		Vars: []interface{}{"%" + EscapeLike(terms[0]) + "%"},
+	}
+}
+
+// useFullText 所有关键词都不短于 ngram 长度时才能使用全文索引
+func (s *Searcher) useFullText(terms []string) bool {
+	if !s.fullText {
+		return false
+	}
+	for _, term := range terms {
+		if utf8.RuneCountInString(term) < s.tokenSize {
+			return false
+		}
+	}
+	return true
+}
+
+// Terms 按空白切分关键词并去掉 BOOLEAN MODE 运算符
+func Terms(keyword string) []string {
+	var terms []string
+	for _, field := range strings.Fields(keyword) {
+		field = strings.Map(func(r rune) rune {
+			if strings.ContainsRune(booleanOperators, r) {
+				return -1
+			}
+			return r
+		}, field)
+		if field != "" {
+			terms = append(terms, field)
+		}
+	}
+	return terms
+}
+
+// booleanQuery 每个关键词作为必须出现的短语
+func booleanQuery(terms []string) string {
+	quoted := make([]string, len(terms))
+	for i, term := range terms {
+		quoted[i] = `+"` + term + `"`
+	}
+	return strings.Join(quoted, " ")
+}
+
+// tagNames 整个关键词和其中的每个词都可能是标签名
+func tagNames(keyword string, terms []string) []string {
+	names := []string{strings.ToLower(strings.Join(strings.Fields(keyword), " "))}
+	for _, term := range terms {
+		names = append(names, strings.ToLower(term))
+	}
+	return names
+}
+
+// EscapeLike 以 ! 为转义符转义 LIKE 模式中的通配符
+func EscapeLike(s string) string {
+	return strings.NewReplacer("!", "!!", "%", "!%", "_", "!_").Replace(s)
+}
@@ -0,0 +1,100 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"aifriend/internal/model"
+
+	"gorm.io/gorm"
+)
+
+// touchInterval 最近使用时间的更新间隔, 避免每个请求都写数据库
+const touchInterval = 5 * time.Minute
+
+var ErrRevoked = errors.New("session: revoked, expired or token version changed")
+
+type contextKey struct{}
+
+func NewContext(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 返回 SessionCheck 中间件保存的当前会话ID
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(contextKey{}).(string)
+	return id
+}
+
+// Check 确认会话属于该用户且仍然有效、令牌版本与用户当前版本一致, 并按间隔刷新最近使用时间
+func Check(db *gorm.DB, userId int64, tokenVersion int64, id string) error {
+	if id == "" {
+		return ErrRevoked
+	}
+
+	var s struct {
+		model.Session
+		TokenVersion int64
+	}
+	if err := db.Model(&model.Session{}).
+		Select("user_sessions.*, users.token_version").
+		Joins("JOIN users ON users.id = user_sessions.user_id AND users.deleted_at IS NULL").
+		Where("user_sessions.id = ? AND user_sessions.user_id = ?", id, userId).
+		Take(&s).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return ErrRevoked
+		}
+		return err
+	}
+	now := time.Now()
+	if s.RevokedAt != nil || now.After(s.ExpiresAt) || s.TokenVersion != tokenVersion {
+		return ErrRevoked
+	}
+
+	if now.Sub(s.LastUsedAt) > touchInterval {
+		if err := db.Model(&model.Session{}).Where("id = ?", id).UpdateColumn("last_used_at", now).Error; err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Revoke 吊销满足条件的会话及其全部刷新令牌
+func Revoke(db *gorm.DB, query interface{}, args ...interface{}) error {
+	now := time.Now()
+	return db.Transaction(func(tx *gorm.DB) error {
+		ids := tx.Model(&model.Session{}).Select("id").Where(query, args...)
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("family_id IN (?)", ids).
+			Where("revoked_at IS NULL").
+			UpdateColumn("revoked_at", now).Error; err != nil {
+			return err
+		}
+		return tx.Model(&model.Session{}).
+			Where(query, args...).
+			Where("revoked_at IS NULL").
+			UpdateColumn("revoked_at", now).Error
+	})
+}
+
+// InvalidateUser 递增用户的令牌版本并吊销会话, 修改或重置密码后之前签发的令牌都不再有效.
+// keep 不为空时保留该会话但吊销它的刷新令牌, 调用方需要用 Issue 为它重新签发令牌
+func InvalidateUser(db *gorm.DB, userId int64, keep string) error {
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Model(&model.User{}).
+			Where("id = ?", userId).
+			UpdateColumn("token_version", gorm.Expr("token_version + 1")).Error; err != nil {
+			return err
+		}
+		if keep == "" {
+			return Revoke(tx, "user_id = ?", userId)
+		}
+		if err := tx.Model(&model.RefreshToken{}).
+			Where("family_id = ? AND revoked_at IS NULL", keep).
+			UpdateColumn("revoked_at", time.Now()).Error; err != nil {
+			return err
+		}
+		return Revoke(tx, "user_id = ? AND id <> ?", userId, keep)
+	})
+}
@@ -0,0 +1,116 @@
+package session
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+	"time"
+
+	"aifriend/internal/model"
+	"aifriend/internal/pkg/client"
+	"aifriend/internal/pkg/jwt"
+
+	"gorm.io/gorm"
+)
+
+// 刷新令牌在签名之外还需要在 refresh_tokens 表中有效: 每次刷新都会把旧令牌标记为已轮换并签发新令牌,
+// 已轮换的令牌再次出现说明它可能被盗用, 此时吊销整个会话, 合法用户和攻击者都需要重新登录.
+
+// Config 签发令牌使用的密钥和有效期
+type Config struct {
+	AccessSecret  string
+	AccessExpire  int64 // 访问令牌过期时间(秒)
+	RefreshSecret string
+	RefreshExpire int64 // 刷新令牌过期时间(秒)
+}
+
+// Tokens 签发给客户端的访问令牌和刷新令牌
+type Tokens struct {
+	AccessToken  string
+	RefreshToken string
+	ExpiresIn    int64 // 访问令牌有效期(秒)
+}
+
+// maxDeviceNameLength 客户端自定义设备名称的最大字数
+const maxDeviceNameLength = 50
+
+// HashToken 刷新令牌只保存摘要, 数据库泄露时无法直接使用
+func HashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// Start 为一次新的登录创建会话并签发令牌. deviceName 为空时根据 User-Agent 推断
+func Start(ctx context.Context, tx *gorm.DB, c Config, user *model.User, deviceName string) (*Tokens, error) {
+	info := client.FromContext(ctx)
+	deviceName = strings.TrimSpace(deviceName)
+	if deviceName == "" {
+		deviceName = client.DeviceName(info.UserAgent)
+	}
+	if name := []rune(deviceName); len(name) > maxDeviceNameLength {
+		deviceName = string(name[:maxDeviceNameLength])
+	}
+
+	id, err := jwt.NewTokenId()
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	s := model.Session{
+		Id:         id,
+		UserId:     user.Id,
+		DeviceName: deviceName,
+		LastUsedAt: now,
+		ExpiresAt:  now,
+	}
+	if err := tx.Create(&s).Error; err != nil {
+		return nil, err
+	}
+	return Issue(ctx, tx, c, user, s.Id)
+}
+
+// Issue 为会话签发访问令牌和刷新令牌, 保存刷新令牌并更新会话的使用记录.
+// 访问令牌的 jti 为会话ID, 刷新令牌属于该会话的令牌族
+func Issue(ctx context.Context, tx *gorm.DB, c Config, user *model.User, id string) (*Tokens, error) {
+	accessToken, err := jwt.GenerateToken(user.Id, user.Username, user.TokenVersion, id, c.AccessSecret, c.AccessExpire)
+	if err != nil {
+		return nil, err
+	}
+	tokenId, err := jwt.NewTokenId()
+	if err != nil {
+		return nil, err
+	}
+	refreshToken, err := jwt.GenerateToken(user.Id, user.Username, user.TokenVersion, tokenId, c.RefreshSecret, c.RefreshExpire)
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	expiresAt := now.Add(time.Duration(c.RefreshExpire) * time.Second)
+	info := client.FromContext(ctx)
+	if err := tx.Create(&model.RefreshToken{
+		UserId:     user.Id,
+		FamilyId:   id,
+		TokenHash:  HashToken(refreshToken),
+		DeviceInfo: info.UserAgent,
+		Ip:         info.Ip,
+		ExpiresAt:  expiresAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+	if err := tx.Model(&model.Session{}).Where("id = ?", id).Updates(map[string]interface{}{
+		"user_agent":   info.UserAgent,
+		"ip":           info.Ip,
+		"last_used_at": now,
+		"expires_at":   expiresAt,
+	}).Error; err != nil {
+		return nil, err
+	}
+
+	return &Tokens{
+		AccessToken:  accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    c.AccessExpire,
+	}, nil
+}
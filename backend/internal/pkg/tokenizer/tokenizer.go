@@ -0,0 +1,86 @@
+package tokenizer
+
+import (
+	"strings"
+	"unicode"
+)
+
+// MessageOverhead 每条消息在角色标记等格式上的额外开销
+const MessageOverhead = 4
+
+// Tokenizer 文本分词计数器
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// ApproxTokenizer 基于字符数的近似计数器, 无需加载词表.
+// 中日韩字符按每字 1 个 token 计, 其余字符按每 4 个字符 1 个 token 计.
+type ApproxTokenizer struct{}
+
+func NewApproxTokenizer() ApproxTokenizer {
+	return ApproxTokenizer{}
+}
+
+func (ApproxTokenizer) Count(text string) int {
+	var cjk, other int
+	for _, r := range text {
+		if isCJK(r) {
+			cjk++
+		} else {
+			other++
+		}
+	}
+	return cjk + (other+3)/4
+}
+
+// Terms 切分检索词: 英文和数字按单词切分并转为小写, 中日韩文本按相邻两字切分, 结果去重
+func Terms(text string) []string {
+	seen := make(map[string]bool)
+	var terms []string
+	add := func(term string) {
+		if !seen[term] {
+			seen[term] = true
+			terms = append(terms, term)
+		}
+	}
+
+	for _, field := range strings.FieldsFunc(strings.ToLower(text), isSeparator) {
+		var word, cjk []rune
+		flushWord := func() {
+			if len(word) > 0 {
+				add(string(word))
+				word = word[:0]
+			}
+		}
+		flushCJK := func() {
+			if len(cjk) == 1 {
+				add(string(cjk))
+			}
+			for i := 0; i+1 < len(cjk); i++ {
+				add(string(cjk[i : i+2]))
+			}
+			cjk = cjk[:0]
+		}
+
+		for _, r := range field {
+			if isCJK(r) {
+				flushWord()
+				cjk = append(cjk, r)
+			} else {
+				flushCJK()
+				word = append(word, r)
+			}
+		}
+		flushWord()
+		flushCJK()
+	}
+	return terms
+}
+
+func isSeparator(r rune) bool {
+	return !unicode.IsLetter(r) && !unicode.IsNumber(r)
+}
+
+func isCJK(r rune) bool {
+	return unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul)
+}
@@ -0,0 +1,481 @@
+package vector
+
+import (
+	"container/heap"
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// HNSWIndex 在数据库存储之上维护内存中的 HNSW 近似最近邻图.
+// 每个检索范围 (Kind + UserId + CharacterId) 单独建图, 首次检索时从数据库加载,
+// 超出 MaxGraphs 时淘汰最久未使用的图, 写入和删除会同步到已加载的图.
+type HNSWIndex struct {
+	store *Store
+	c     Config
+
+	mu     sync.Mutex
+	graphs map[Filter]*hnswGraph
+	loads  map[Filter]*hnswLoad
+}
+
+// hnswLoad 正在从数据库加载的图. 读取数据库之后、图安装之前的写入和删除无法直接同步到图,
+// 先按顺序记录下来, 建图后重放. 读取之前的修改也会被记录, 重放结果与数据库一致
+type hnswLoad struct {
+	mu  sync.Mutex // 同一范围的并发加载只执行一次
+	ops []hnswOp
+}
+
+// hnswOp 加载期间的一次修改, vector 为 nil 时表示删除
+type hnswOp struct {
+	refId  int64
+	vector []float32
+}
+
+// 内存中最多保留的图数量
+const maxGraphs = 1024
+
+func NewHNSWIndex(store *Store, c Config) *HNSWIndex {
+	return &HNSWIndex{
+		store:  store,
+		c:      c,
+		graphs: make(map[Filter]*hnswGraph),
+		loads:  make(map[Filter]*hnswLoad),
+	}
+}
+
+func (h *HNSWIndex) Upsert(ctx context.Context, items ...Item) error {
+	if err := h.store.Upsert(ctx, items...); err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		key := Filter{Kind: item.Kind, UserId: item.UserId, CharacterId: item.CharacterId}
+		if g := h.upsert(key, item.RefId, item.Vector); g != nil {
+			g.insert(item.RefId, item.Vector)
+		}
+	}
+	return nil
+}
+
+func (h *HNSWIndex) Delete(ctx context.Context, kind string, refIds ...int64) error {
+	if err := h.store.Delete(ctx, kind, refIds...); err != nil {
+		return err
+	}
+
+	for _, id := range refIds {
+		h.remove(kind, id)
+	}
+	return nil
+}
+
+func (h *HNSWIndex) Search(ctx context.Context, query []float32, filter Filter, k int) ([]Result, error) {
+	if len(query) == 0 {
+		return nil, ErrEmptyVector
+	}
+	if k <= 0 {
+		return nil, nil
+	}
+
+	g, err := h.load(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+	return g.search(query, k, h.c.EfSearch), nil
+}
+
+// load 返回范围对应的图, 未加载时从数据库构建. 同一范围的并发加载只执行一次.
+func (h *HNSWIndex) load(ctx context.Context, key Filter) (*hnswGraph, error) {
+	h.mu.Lock()
+	if g, ok := h.graphs[key]; ok {
+		g.touch()
+		h.mu.Unlock()
+		return g, nil
+	}
+	load, ok := h.loads[key]
+	if !ok {
+		load = &hnswLoad{}
+		h.loads[key] = load
+	}
+	h.mu.Unlock()
+
+	load.mu.Lock()
+	defer load.mu.Unlock()
+
+	if g := h.graph(key); g != nil {
+		return g, nil
+	}
+
+	items, err := h.store.load(ctx, key)
+	if err != nil {
+		// 下一次加载重新读取数据库, 之前记录的修改已经包含在内
+		h.mu.Lock()
+		load.ops = nil
+		h.mu.Unlock()
+		return nil, err
+	}
+	g := newHNSWGraph(h.c.M, h.c.EfConstruction)
+	for _, item := range items {
+		g.insert(item.RefId, item.Vector)
+	}
+	h.install(key, load, g)
+	return g, nil
+}
+
+// install 重放加载期间记录的修改后安装图. 重放和安装在同一次加锁内完成, 之后的修改直接同步到图
+func (h *HNSWIndex) install(key Filter, load *hnswLoad, g *hnswGraph) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, op := range load.ops {
+		if op.vector == nil {
+			g.remove(op.refId)
+		} else {
+			g.insert(op.refId, op.vector)
+		}
+	}
+	h.evict()
+	h.graphs[key] = g
+	delete(h.loads, key)
+}
+
+func (h *HNSWIndex) graph(key Filter) *hnswGraph {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	return h.graphs[key]
+}
+
+// upsert 来源记录可能换了所属范围, 先从所有图中移除, 再记录到正在加载的图.
+// 返回需要写入的已加载的图
+func (h *HNSWIndex) upsert(key Filter, refId int64, vector []float32) *hnswGraph {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(key.Kind, refId)
+	if load, ok := h.loads[key]; ok {
+		load.ops = append(load.ops, hnswOp{refId: refId, vector: vector})
+	}
+	return h.graphs[key]
+}
+
+func (h *HNSWIndex) remove(kind string, refId int64) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.removeLocked(kind, refId)
+}
+
+// removeLocked 从已加载的图中移除并记录到正在加载的图, 调用方需持有 h.mu
+func (h *HNSWIndex) removeLocked(kind string, refId int64) {
+	for key, g := range h.graphs {
+		if key.Kind == kind {
+			g.remove(refId)
+		}
+	}
+	for key, load := range h.loads {
+		if key.Kind == kind {
+			load.ops = append(load.ops, hnswOp{refId: refId})
+		}
+	}
+}
+
+// evict 图数量达到上限时淘汰最久未使用的一个, 调用方需持有 h.mu
+func (h *HNSWIndex) evict() {
+	if len(h.graphs) < maxGraphs {
+		return
+	}
+
+	var oldest Filter
+	var oldestAt int64 = math.MaxInt64
+	for key, g := range h.graphs {
+		if at := g.lastUsed(); at < oldestAt {
+			oldest, oldestAt = key, at
+		}
+	}
+	delete(h.graphs, oldest)
+}
+
+type hnswNode struct {
+	refId     int64
+	vector    []float32 // 单位向量
+	neighbors [][]int   // 每层的邻居节点下标
+	deleted   bool
+}
+
+// hnswGraph 单个检索范围内的 HNSW 图. 删除采用墓碑标记, 节点仍参与遍历但不会被返回.
+type hnswGraph struct {
+	mu        sync.RWMutex
+	m         int
+	ef        int
+	levelMult float64
+	rng       *rand.Rand
+
+	nodes    []*hnswNode
+	ids      map[int64]int
+	entry    int
+	maxLevel int
+	deleted  int
+	used     atomic.Int64 // 最近一次使用的时间戳, 用于淘汰
+}
+
+func newHNSWGraph(m, efConstruction int) *hnswGraph {
+	if m < 2 {
+		m = 16
+	}
+	if efConstruction < m {
+		efConstruction = m
+	}
+	g := &hnswGraph{
+		m:         m,
+		ef:        efConstruction,
+		levelMult: 1 / math.Log(float64(m)),
+		rng:       rand.New(rand.NewSource(time.Now().UnixNano())),
+		ids:       make(map[int64]int),
+		entry:     -1,
+	}
+	g.touch()
+	return g
+}
+
+func (g *hnswGraph) touch() {
+	g.used.Store(time.Now().UnixNano())
+}
+
+func (g *hnswGraph) lastUsed() int64 {
+	return g.used.Load()
+}
+
+func (g *hnswGraph) insert(refId int64, vector []float32) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if idx, ok := g.ids[refId]; ok {
+		g.nodes[idx].deleted = true
+		g.deleted++
+	}
+	// 墓碑过多时重建, 避免图中堆积失效节点
+	if g.deleted > 0 && g.deleted*2 > len(g.nodes) {
+		g.rebuild()
+	}
+	g.add(refId, vector)
+}
+
+// add 插入新节点, 调用方需持有写锁
+func (g *hnswGraph) add(refId int64, vector []float32) {
+	level := int(math.Floor(-math.Log(1-g.rng.Float64()) * g.levelMult))
+	node := &hnswNode{
+		refId:     refId,
+		vector:    normalize(vector),
+		neighbors: make([][]int, level+1),
+	}
+	idx := len(g.nodes)
+	g.nodes = append(g.nodes, node)
+	g.ids[refId] = idx
+
+	if g.entry < 0 {
+		g.entry, g.maxLevel = idx, level
+		return
+	}
+
+	ep := g.entry
+	for l := g.maxLevel; l > level; l-- {
+		ep = g.greedy(node.vector, ep, l)
+	}
+	for l := min(level, g.maxLevel); l >= 0; l-- {
+		candidates := g.searchLayer(node.vector, ep, g.ef, l)
+		node.neighbors[l] = g.closest(candidates, g.maxNeighbors(l))
+		for _, n := range node.neighbors[l] {
+			g.connect(n, idx, l)
+		}
+		ep = candidates[0].idx
+	}
+
+	if level > g.maxLevel {
+		g.entry, g.maxLevel = idx, level
+	}
+}
+
+func (g *hnswGraph) remove(refId int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if idx, ok := g.ids[refId]; ok {
+		g.nodes[idx].deleted = true
+		g.deleted++
+		delete(g.ids, refId)
+	}
+}
+
+func (g *hnswGraph) search(query []float32, k, ef int) []Result {
+	g.touch()
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if g.entry < 0 || len(query) != len(g.nodes[g.entry].vector) {
+		return nil
+	}
+
+	q := normalize(query)
+	ep := g.entry
+	for l := g.maxLevel; l > 0; l-- {
+		ep = g.greedy(q, ep, l)
+	}
+
+	// 墓碑节点会占用候选位置, 按比例放大候选集
+	ef = max(ef, k) + g.deleted
+	candidates := g.searchLayer(q, ep, ef, 0)
+
+	results := make([]Result, 0, k)
+	for _, c := range candidates {
+		node := g.nodes[c.idx]
+		if node.deleted {
+			continue
+		}
+		results = append(results, Result{RefId: node.refId, Score: c.score})
+		if len(results) == k {
+			break
+		}
+	}
+	return results
+}
+
+// rebuild 丢弃墓碑节点后重新建图, 调用方需持有写锁
+func (g *hnswGraph) rebuild() {
+	nodes := g.nodes
+	g.nodes, g.ids, g.entry, g.maxLevel, g.deleted = nil, make(map[int64]int), -1, 0, 0
+
+	for _, node := range nodes {
+		if !node.deleted {
+			g.add(node.refId, node.vector)
+		}
+	}
+}
+
+// greedy 在第 l 层贪心移动到离 q 最近的节点
+func (g *hnswGraph) greedy(q []float32, ep, l int) int {
+	best := dot(q, g.nodes[ep].vector)
+	for changed := true; changed; {
+		changed = false
+		for _, n := range g.nodes[ep].neighbors[l] {
+			if score := dot(q, g.nodes[n].vector); score > best {
+				best, ep, changed = score, n, true
+			}
+		}
+	}
+	return ep
+}
+
+// searchLayer 在第 l 层做束搜索, 返回按相似度从高到低排序的至多 ef 个候选
+func (g *hnswGraph) searchLayer(q []float32, ep, ef, l int) []candidate {
+	visited := map[int]bool{ep: true}
+	first := candidate{idx: ep, score: dot(q, g.nodes[ep].vector)}
+	frontier := &maxHeap{first}
+	found := &minHeap{first}
+
+	for frontier.Len() > 0 {
+		c := heap.Pop(frontier).(candidate)
+		if found.Len() >= ef && c.score < (*found)[0].score {
+			break
+		}
+		for _, n := range g.nodes[c.idx].neighbors[l] {
+			if visited[n] {
+				continue
+			}
+			visited[n] = true
+
+			score := dot(q, g.nodes[n].vector)
+			if found.Len() < ef || score > (*found)[0].score {
+				heap.Push(frontier, candidate{idx: n, score: score})
+				heap.Push(found, candidate{idx: n, score: score})
+				if found.Len() > ef {
+					heap.Pop(found)
+				}
+			}
+		}
+	}
+
+	result := make([]candidate, found.Len())
+	for i := len(result) - 1; i >= 0; i-- {
+		result[i] = heap.Pop(found).(candidate)
+	}
+	return result
+}
+
+// closest 从已排序的候选中取前 n 个节点下标
+func (g *hnswGraph) closest(candidates []candidate, n int) []int {
+	if len(candidates) > n {
+		candidates = candidates[:n]
+	}
+	ids := make([]int, len(candidates))
+	for i, c := range candidates {
+		ids[i] = c.idx
+	}
+	return ids
+}
+
+// connect 为节点 from 在第 l 层添加邻居 to, 超出上限时只保留最相似的邻居
+func (g *hnswGraph) connect(from, to, l int) {
+	node := g.nodes[from]
+	node.neighbors[l] = append(node.neighbors[l], to)
+
+	limit := g.maxNeighbors(l)
+	if len(node.neighbors[l]) <= limit {
+		return
+	}
+
+	candidates := make([]candidate, len(node.neighbors[l]))
+	for i, n := range node.neighbors[l] {
+		candidates[i] = candidate{idx: n, score: dot(node.vector, g.nodes[n].vector)}
+	}
+	h := minHeap(candidates)
+	heap.Init(&h)
+	for h.Len() > limit {
+		heap.Pop(&h)
+	}
+	node.neighbors[l] = node.neighbors[l][:0]
+	for _, c := range h {
+		node.neighbors[l] = append(node.neighbors[l], c.idx)
+	}
+}
+
+// maxNeighbors 第 0 层允许两倍的邻居数
+func (g *hnswGraph) maxNeighbors(l int) int {
+	if l == 0 {
+		return 2 * g.m
+	}
+	return g.m
+}
+
+type candidate struct {
+	idx   int
+	score float64
+}
+
+// minHeap 堆顶为相似度最低的候选
+type minHeap []candidate
+
+func (h minHeap) Len() int           { return len(h) }
+func (h minHeap) Less(i, j int) bool { return h[i].score < h[j].score }
+func (h minHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *minHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *minHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
+
+// maxHeap 堆顶为相似度最高的候选
+type maxHeap []candidate
+
+func (h maxHeap) Len() int           { return len(h) }
+func (h maxHeap) Less(i, j int) bool { return h[i].score > h[j].score }
+func (h maxHeap) Swap(i, j int)      { h[i], h[j] = h[j], h[i] }
+func (h *maxHeap) Push(x any)        { *h = append(*h, x.(candidate)) }
+func (h *maxHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
@@ -0,0 +1,272 @@
+package vector
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+)
+
+func randomVectors(rng *rand.Rand, n, dim int) [][]float32 {
+	vectors := make([][]float32, n)
+	for i := range vectors {
+		v := make([]float32, dim)
+		for j := range v {
+			v[j] = float32(rng.NormFloat64())
+		}
+		vectors[i] = v
+	}
+	return vectors
+}
+
+// bruteForce 逐条计算余弦相似度, 作为 HNSW 检索的基准
+func bruteForce(vectors map[int64][]float32, query []float32, k int) []Result {
+	results := make([]Result, 0, len(vectors))
+	for id, v := range vectors {
+		results = append(results, Result{RefId: id, Score: Cosine(query, v)})
+	}
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results
+}
+
+func recall(got, want []Result) float64 {
+	if len(want) == 0 {
+		return 1
+	}
+	ids := make(map[int64]bool, len(got))
+	for _, r := range got {
+		ids[r.RefId] = true
+	}
+	hit := 0
+	for _, r := range want {
+		if ids[r.RefId] {
+			hit++
+		}
+	}
+	return float64(hit) / float64(len(want))
+}
+
+func TestHNSWRecall(t *testing.T) {
+	tests := []struct {
+		name      string
+		n, dim, k int
+		m, ef     int
+		minRecall float64
+	}{
+		{name: "少量向量", n: 20, dim: 8, k: 5, m: 4, ef: 16, minRecall: 1},
+		{name: "小图", n: 500, dim: 16, k: 10, m: 8, ef: 64, minRecall: 0.9},
+		{name: "默认参数", n: 2000, dim: 32, k: 10, m: 16, ef: 200, minRecall: 0.9},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rng := rand.New(rand.NewSource(1))
+			vectors := make(map[int64][]float32, tt.n)
+			g := newHNSWGraph(tt.m, tt.ef)
+			for i, v := range randomVectors(rng, tt.n, tt.dim) {
+				vectors[int64(i+1)] = v
+				g.insert(int64(i+1), v)
+			}
+
+			var total float64
+			queries := randomVectors(rng, 50, tt.dim)
+			for _, q := range queries {
+				got := g.search(q, tt.k, tt.ef)
+				if len(got) != min(tt.k, tt.n) {
+					t.Fatalf("search() returned %d results, want %d", len(got), min(tt.k, tt.n))
+				}
+				for i := 1; i < len(got); i++ {
+					if got[i].Score > got[i-1].Score {
+						t.Fatalf("results not sorted: %v", got)
+					}
+				}
+				total += recall(got, bruteForce(vectors, q, tt.k))
+			}
+			if r := total / float64(len(queries)); r < tt.minRecall {
+				t.Errorf("recall@%d = %.3f, want >= %.3f", tt.k, r, tt.minRecall)
+			}
+		})
+	}
+}
+
+func TestHNSWGraphUpdate(t *testing.T) {
+	rng := rand.New(rand.NewSource(2))
+	vectors := randomVectors(rng, 200, 16)
+
+	tests := []struct {
+		name string
+		// apply 在包含 1..200 的图上执行修改, 返回修改后的全部向量
+		apply func(t *testing.T, g *hnswGraph) map[int64][]float32
+	}{
+		{
+			name: "删除",
+			apply: func(t *testing.T, g *hnswGraph) map[int64][]float32 {
+				all := make(map[int64][]float32)
+				for i, v := range vectors {
+					id := int64(i + 1)
+					if id%3 == 0 {
+						g.remove(id)
+						continue
+					}
+					all[id] = v
+				}
+				return all
+			},
+		},
+		{
+			name: "覆盖已有向量",
+			apply: func(t *testing.T, g *hnswGraph) map[int64][]float32 {
+				all := make(map[int64][]float32)
+				for i, v := range vectors {
+					all[int64(i+1)] = v
+				}
+				for i, v := range randomVectors(rng, 50, 16) {
+					all[int64(i+1)] = v
+					g.insert(int64(i+1), v)
+				}
+				return all
+			},
+		},
+		{
+			name: "墓碑过半后重建",
+			apply: func(t *testing.T, g *hnswGraph) map[int64][]float32 {
+				all := make(map[int64][]float32)
+				for i, v := range vectors {
+					id := int64(i + 1)
+					if id <= 150 {
+						g.remove(id)
+						continue
+					}
+					all[id] = v
+				}
+				// 插入触发重建
+				extra := randomVectors(rng, 1, 16)[0]
+				all[1000] = extra
+				g.insert(1000, extra)
+				if g.deleted != 0 || len(g.nodes) != len(all) {
+					t.Errorf("rebuild kept %d nodes, %d deleted, want %d nodes", len(g.nodes), g.deleted, len(all))
+				}
+				return all
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := newHNSWGraph(8, 64)
+			for i, v := range vectors {
+				g.insert(int64(i+1), v)
+			}
+			all := tt.apply(t, g)
+
+			var total float64
+			for _, q := range randomVectors(rng, 20, 16) {
+				got := g.search(q, 10, 64)
+				seen := make(map[int64]bool)
+				for _, r := range got {
+					if _, ok := all[r.RefId]; !ok {
+						t.Fatalf("search() returned removed id %d", r.RefId)
+					}
+					if seen[r.RefId] {
+						t.Fatalf("search() returned id %d twice", r.RefId)
+					}
+					seen[r.RefId] = true
+				}
+				total += recall(got, bruteForce(all, q, 10))
+			}
+			if r := total / 20; r < 0.9 {
+				t.Errorf("recall@10 = %.3f, want >= 0.9", r)
+			}
+		})
+	}
+}
+
+// TestHNSWLoadReplay 读取数据库之后、安装图之前的修改在安装时重放
+func TestHNSWLoadReplay(t *testing.T) {
+	key := Filter{Kind: KindMemory, UserId: 1, CharacterId: 1}
+	other := Filter{Kind: KindMemory, UserId: 1, CharacterId: 2}
+	v := func(x, y float32) []float32 { return []float32{x, y} }
+
+	tests := []struct {
+		name   string
+		during func(h *HNSWIndex) // 加载期间的修改
+		query  []float32
+		want   []int64
+	}{
+		{
+			name:   "写入新向量",
+			during: func(h *HNSWIndex) { h.upsert(key, 3, v(0, -1)) },
+			query:  v(0, -1),
+			want:   []int64{3, 1, 2},
+		},
+		{
+			name:   "覆盖已有向量",
+			during: func(h *HNSWIndex) { h.upsert(key, 1, v(-1, 0)) },
+			query:  v(-1, 0),
+			want:   []int64{1, 2},
+		},
+		{
+			name:   "删除",
+			during: func(h *HNSWIndex) { h.remove(KindMemory, 2) },
+			query:  v(0, 1),
+			want:   []int64{1},
+		},
+		{
+			name:   "换到其他范围",
+			during: func(h *HNSWIndex) { h.upsert(other, 2, v(0, 1)) },
+			query:  v(0, 1),
+			want:   []int64{1},
+		},
+		{
+			name:   "其他类型不受影响",
+			during: func(h *HNSWIndex) { h.remove(KindKnowledge, 2) },
+			query:  v(0, 1),
+			want:   []int64{2, 1},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHNSWIndex(nil, Config{M: 4, EfConstruction: 16, EfSearch: 16})
+			load := &hnswLoad{}
+			h.loads[key] = load
+
+			// 数据库中读到的快照
+			g := newHNSWGraph(h.c.M, h.c.EfConstruction)
+			g.insert(1, v(1, 0))
+			g.insert(2, v(0, 1))
+
+			tt.during(h)
+			if h.graph(key) != nil {
+				t.Fatalf("graph installed before load finished")
+			}
+			h.install(key, load, g)
+			if _, ok := h.loads[key]; ok {
+				t.Errorf("load not cleared after install")
+			}
+
+			got := h.graph(key).search(tt.query, 10, 16)
+			ids := make([]int64, len(got))
+			for i, r := range got {
+				ids[i] = r.RefId
+			}
+			if len(ids) != len(tt.want) {
+				t.Fatalf("search() = %v, want %v", ids, tt.want)
+			}
+			for i := range ids {
+				if ids[i] != tt.want[i] {
+					t.Fatalf("search() = %v, want %v", ids, tt.want)
+				}
+			}
+
+			// 安装后的修改直接写入图
+			if g := h.upsert(key, 4, v(1, 1)); g == nil {
+				t.Errorf("upsert() after install returned no graph")
+			}
+		})
+	}
+}
@@ -0,0 +1,116 @@
+package vector
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"aifriend/internal/model"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Store 基于数据库的暴力检索索引: 按过滤条件加载向量后逐条计算余弦相似度.
+// 单个用户-角色范围内的向量通常只有几百条, 无需额外的向量数据库.
+type Store struct {
+	db *gorm.DB
+}
+
+func NewStore(db *gorm.DB) *Store {
+	return &Store{db: db}
+}
+
+func (s *Store) Upsert(ctx context.Context, items ...Item) error {
+	if len(items) == 0 {
+		return nil
+	}
+
+	now := time.Now()
+	rows := make([]model.Embedding, len(items))
+	for i, item := range items {
+		if len(item.Vector) == 0 {
+			return ErrEmptyVector
+		}
+		rows[i] = model.Embedding{
+			Kind:        item.Kind,
+			RefId:       item.RefId,
+			UserId:      item.UserId,
+			CharacterId: item.CharacterId,
+			Dimensions:  len(item.Vector),
+			Vector:      Encode(item.Vector),
+			CreatedAt:   now,
+			UpdatedAt:   now,
+		}
+	}
+
+	return s.db.WithContext(ctx).Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "kind"}, {Name: "ref_id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"user_id", "character_id", "dimensions", "vector", "updated_at"}),
+	}).Create(&rows).Error
+}
+
+func (s *Store) Delete(ctx context.Context, kind string, refIds ...int64) error {
+	if len(refIds) == 0 {
+		return nil
+	}
+	return s.db.WithContext(ctx).Where("kind = ? AND ref_id IN ?", kind, refIds).
+		Delete(&model.Embedding{}).Error
+}
+
+func (s *Store) Search(ctx context.Context, query []float32, filter Filter, k int) ([]Result, error) {
+	if len(query) == 0 {
+		return nil, ErrEmptyVector
+	}
+	if k <= 0 {
+		return nil, nil
+	}
+
+	items, err := s.load(ctx, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]Result, 0, len(items))
+	for _, item := range items {
+		// 更换向量模型后维度不同的旧向量无法比较, 直接跳过
+		if len(item.Vector) != len(query) {
+			continue
+		}
+		results = append(results, Result{RefId: item.RefId, Score: Cosine(query, item.Vector)})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		return results[i].Score > results[j].Score
+	})
+	if len(results) > k {
+		results = results[:k]
+	}
+	return results, nil
+}
+
+// load 加载 filter 范围内的全部向量
+func (s *Store) load(ctx context.Context, filter Filter) ([]Item, error) {
+	var rows []model.Embedding
+	if err := s.db.WithContext(ctx).
+		Where("kind = ? AND user_id = ? AND character_id = ?", filter.Kind, filter.UserId, filter.CharacterId).
+		Find(&rows).Error; err != nil {
+		return nil, err
+	}
+
+	items := make([]Item, 0, len(rows))
+	for _, row := range rows {
+		v, err := Decode(row.Vector)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, Item{
+			Kind:        row.Kind,
+			RefId:       row.RefId,
+			UserId:      row.UserId,
+			CharacterId: row.CharacterId,
+			Vector:      v,
+		})
+	}
+	return items, nil
+}
@@ -0,0 +1,143 @@
+package vector
+
+import (
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math"
+
+	"gorm.io/gorm"
+)
+
+// 支持的索引类型
+const (
+	IndexBruteForce = "bruteforce"
+	IndexHNSW       = "hnsw"
+)
+
+// 向量来源类型
+const (
+	KindMemory    = "memory"
+	KindKnowledge = "knowledge"
+)
+
+var ErrEmptyVector = errors.New("vector: empty vector")
+
+// Config 向量索引配置
+type Config struct {
+	Index          string `json:",default=bruteforce,options=bruteforce|hnsw"` // bruteforce: 从数据库加载后逐条计算; hnsw: 内存中的近似最近邻图
+	M              int    `json:",default=16"`                                 // hnsw 每层的最大邻居数
+	EfConstruction int    `json:",default=200"`                                // hnsw 建图时的候选集大小
+	EfSearch       int    `json:",default=64"`                                 // hnsw 查询时的候选集大小
+}
+
+// Item 待索引的向量
+type Item struct {
+	Kind        string
+	RefId       int64
+	UserId      int64
+	CharacterId int64
+	Vector      []float32
+}
+
+// Filter 检索范围, 所有字段按等值匹配
+type Filter struct {
+	Kind        string
+	UserId      int64
+	CharacterId int64
+}
+
+// Result 检索结果, Score 为余弦相似度
+type Result struct {
+	RefId int64
+	Score float64
+}
+
+// Index 向量索引
+type Index interface {
+	// Upsert 写入或覆盖向量, 以 Kind + RefId 作为唯一键
+	Upsert(ctx context.Context, items ...Item) error
+	// Delete 删除指定来源记录的向量
+	Delete(ctx context.Context, kind string, refIds ...int64) error
+	// Search 返回 filter 范围内与 query 最相似的 k 条结果, 按相似度从高到低排序
+	Search(ctx context.Context, query []float32, filter Filter, k int) ([]Result, error)
+}
+
+// NewIndex 根据配置创建向量索引, 向量始终持久化在数据库中
+func NewIndex(db *gorm.DB, c Config) (Index, error) {
+	store := NewStore(db)
+	switch c.Index {
+	case "", IndexBruteForce:
+		return store, nil
+	case IndexHNSW:
+		return NewHNSWIndex(store, c), nil
+	default:
+		return nil, fmt.Errorf("vector: unknown index %q", c.Index)
+	}
+}
+
+// Encode 把向量编码为小端序字节
+func Encode(v []float32) []byte {
+	buf := make([]byte, 4*len(v))
+	for i, f := range v {
+		binary.LittleEndian.PutUint32(buf[4*i:], math.Float32bits(f))
+	}
+	return buf
+}
+
+// Decode 从小端序字节还原向量
+func Decode(buf []byte) ([]float32, error) {
+	if len(buf)%4 != 0 {
+		return nil, fmt.Errorf("vector: invalid encoded length %d", len(buf))
+	}
+	v := make([]float32, len(buf)/4)
+	for i := range v {
+		v[i] = math.Float32frombits(binary.LittleEndian.Uint32(buf[4*i:]))
+	}
+	return v, nil
+}
+
+// Cosine 计算余弦相似度, 维度不同或含零向量时返回 0
+func Cosine(a, b []float32) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+
+	var dot, na, nb float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		na += float64(a[i]) * float64(a[i])
+		nb += float64(b[i]) * float64(b[i])
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
+
+// normalize 返回单位化后的副本, 单位向量的点积即余弦相似度
+func normalize(v []float32) []float32 {
+	var norm float64
+	for _, f := range v {
+		norm += float64(f) * float64(f)
+	}
+
+	out := make([]float32, len(v))
+	if norm == 0 {
+		return out
+	}
+	scale := 1 / math.Sqrt(norm)
+	for i, f := range v {
+		out[i] = float32(float64(f) * scale)
+	}
+	return out
+}
+
+func dot(a, b []float32) float64 {
+	var sum float64
+	for i := range a {
+		sum += float64(a[i]) * float64(b[i])
+	}
+	return sum
+}
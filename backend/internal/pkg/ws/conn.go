@@ -0,0 +1,135 @@
+package ws
+
+import (
+	"encoding/json"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	writeWait      = 10 * time.Second  // 单次写入超时
+	pongWait       = 60 * time.Second  // 等待 pong 的超时
+	pingPeriod     = pongWait * 9 / 10 // ping 间隔, 必须小于 pongWait
+	maxMessageSize = 64 * 1024         // 客户端单条消息的最大字节数
+	sendBufferSize = 256               // 每个连接的发送缓冲大小
+)
+
+// ClosePolicyViolation 关闭码: 连接违反服务端策略, 如登录已失效
+const ClosePolicyViolation = websocket.ClosePolicyViolation
+
+var ErrSendBufferFull = errors.New("ws: send buffer full")
+
+// Conn 单个 WebSocket 连接. 读循环运行在调用 ReadLoop 的 goroutine,
+// 写循环运行在独立的 goroutine, 两者通过有界缓冲通信.
+// 发送缓冲写满说明客户端读取过慢, 此时直接断开连接.
+type Conn struct {
+	conn      *websocket.Conn
+	send      chan []byte
+	done      chan struct{}
+	closeOnce sync.Once
+	closeCode int
+	closeText string
+}
+
+func NewConn(conn *websocket.Conn) *Conn {
+	c := &Conn{
+		conn: conn,
+		send: make(chan []byte, sendBufferSize),
+		done: make(chan struct{}),
+	}
+	go c.writeLoop()
+	return c
+}
+
+// Done 连接关闭时关闭
+func (c *Conn) Done() <-chan struct{} {
+	return c.done
+}
+
+// SendJSON 将 v 编码为 JSON 放入发送缓冲, 不会阻塞
+func (c *Conn) SendJSON(v any) error {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-c.done:
+		return websocket.ErrCloseSent
+	default:
+	}
+
+	select {
+	case c.send <- data:
+		return nil
+	default:
+		c.Close()
+		return ErrSendBufferFull
+	}
+}
+
+// ReadLoop 循环读取客户端消息直到连接关闭, 返回时连接已关闭
+func (c *Conn) ReadLoop(fn func(data []byte)) {
+	defer c.Close()
+
+	c.conn.SetReadLimit(maxMessageSize)
+	c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	c.conn.SetPongHandler(func(string) error {
+		return c.conn.SetReadDeadline(time.Now().Add(pongWait))
+	})
+
+	for {
+		messageType, data, err := c.conn.ReadMessage()
+		if err != nil {
+			return
+		}
+		if messageType != websocket.TextMessage {
+			continue
+		}
+		fn(data)
+	}
+}
+
+func (c *Conn) Close() {
+	c.CloseWith(websocket.CloseNormalClosure, "")
+}
+
+// CloseWith 使用指定的关闭码和原因关闭连接, 只有第一次关闭生效
+func (c *Conn) CloseWith(code int, text string) {
+	c.closeOnce.Do(func() {
+		c.closeCode, c.closeText = code, text
+		close(c.done)
+	})
+}
+
+func (c *Conn) writeLoop() {
+	ticker := time.NewTicker(pingPeriod)
+	defer func() {
+		ticker.Stop()
+		c.conn.Close()
+	}()
+
+	for {
+		select {
+		case data := <-c.send:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.TextMessage, data); err != nil {
+				c.Close()
+				return
+			}
+		case <-ticker.C:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			if err := c.conn.WriteMessage(websocket.PingMessage, nil); err != nil {
+				c.Close()
+				return
+			}
+		case <-c.done:
+			c.conn.SetWriteDeadline(time.Now().Add(writeWait))
+			c.conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(c.closeCode, c.closeText))
+			return
+		}
+	}
+}
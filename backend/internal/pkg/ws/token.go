@@ -0,0 +1,36 @@
+package ws
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/websocket"
+)
+
+const (
+	// Subprotocol 聊天网关协议名, 客户端通过子协议传递令牌时需同时声明
+	Subprotocol = "aifriend.chat.v1"
+	// tokenProtocolPrefix 携带访问令牌的子协议前缀, 形如 access_token.<jwt>
+	tokenProtocolPrefix = "access_token."
+)
+
+// TokenFromRequest 从握手请求中读取访问令牌.
+// 浏览器无法为 WebSocket 设置请求头, 因此依次尝试 Authorization 头、
+// access_token 查询参数和 access_token.<jwt> 子协议.
+func TokenFromRequest(r *http.Request) string {
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+
+	if token := r.URL.Query().Get("access_token"); token != "" {
+		return token
+	}
+
+	for _, protocol := range websocket.Subprotocols(r) {
+		if strings.HasPrefix(protocol, tokenProtocolPrefix) {
+			return strings.TrimPrefix(protocol, tokenProtocolPrefix)
+		}
+	}
+
+	return ""
+}
@@ -5,17 +5,44 @@ package svc
 
 import (
 	"aifriend/internal/config"
+	"aifriend/internal/middleware"
 	"aifriend/internal/model"
+	"aifriend/internal/pkg/account"
+	"aifriend/internal/pkg/client"
+	"aifriend/internal/pkg/knowledge"
+	"aifriend/internal/pkg/llm"
+	"aifriend/internal/pkg/loginguard"
+	"aifriend/internal/pkg/mailer"
+	"aifriend/internal/pkg/memory"
+	"aifriend/internal/pkg/ranking"
+	"aifriend/internal/pkg/search"
+	"aifriend/internal/pkg/tokenizer"
+	"aifriend/internal/pkg/vector"
+	"context"
 	"log"
 
+	"github.com/zeromicro/go-zero/core/logx"
+	"github.com/zeromicro/go-zero/core/threading"
+	"github.com/zeromicro/go-zero/rest"
 	"gorm.io/driver/mysql"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
 )
 
 type ServiceContext struct {
-	Config config.Config
-	DB     *gorm.DB
+	Config       config.Config
+	ClientInfo   rest.Middleware
+	SessionCheck rest.Middleware
+	DB           *gorm.DB
+	LLM          llm.Provider
+	Tokenizer    tokenizer.Tokenizer
+	Vector       vector.Index
+	Memory       *memory.Store
+	Knowledge    *knowledge.Base
+	Search       *search.Searcher
+	Ranking      *ranking.Ranker
+	Emails       *account.Emails
+	LoginGuard   *loginguard.Guard
 }
 
 func NewServiceContext(c config.Config) *ServiceContext {
@@ -28,12 +55,90 @@ func NewServiceContext(c config.Config) *ServiceContext {
 	}
 
 	// 自动迁移
-	if err := db.AutoMigrate(&model.User{}, &model.Character{}); err != nil {
+	if err := db.AutoMigrate(
+		&model.User{},
+		&model.Character{},
+		&model.Conversation{},
+		&model.Message{},
+		&model.Memory{},
+		&model.Embedding{},
+		&model.KnowledgeDocument{},
+		&model.KnowledgeChunk{},
+		&model.LorebookEntry{},
+		&model.Tag{},
+		&model.CharacterVersion{},
+		&model.CharacterLike{},
+		&model.CharacterFavorite{},
+		&model.CharacterRating{},
+		&model.CharacterRanking{},
+		&model.CharacterRecommendation{},
+		&model.RefreshToken{},
+		&model.Session{},
+		&model.UserToken{},
+		&model.LoginFailure{},
+	); err != nil {
 		log.Fatalf("failed to migrate database: %v", err)
 	}
 
+	// 初始化大模型提供方
+	provider, err := llm.NewProvider(c.LLM)
+	if err != nil {
+		log.Fatalf("failed to init llm provider: %v", err)
+	}
+
+	// 初始化向量索引
+	index, err := vector.NewIndex(db, c.Vector)
+	if err != nil {
+		log.Fatalf("failed to init vector index: %v", err)
+	}
+
+	memories := memory.NewStore(db, provider, index, c.LLM.Model, c.Memory)
+	threading.GoSafe(func() {
+		if err := memories.IndexMissing(context.Background()); err != nil {
+			logx.Errorf("index memories failed: %v", err)
+		}
+	})
+
+	ranker := ranking.NewRanker(db, c.Ranking)
+	if c.Ranking.Enabled {
+		threading.GoSafe(func() {
+			ranker.Run(context.Background())
+		})
+	}
+
+	// 初始化邮件发送
+	m, err := mailer.NewMailer(c.Mail)
+	if err != nil {
+		log.Fatalf("failed to init mailer: %v", err)
+	}
+
+	// 初始化登录失败限制
+	guard, err := loginguard.NewGuard(c.LoginGuard)
+	if err != nil {
+		log.Fatalf("failed to init login guard: %v", err)
+	}
+
+	// 初始化客户端地址解析
+	resolver, err := client.NewResolver(c.Client)
+	if err != nil {
+		log.Fatalf("failed to init client resolver: %v", err)
+	}
+
+	tok := tokenizer.NewApproxTokenizer()
+
 	return &ServiceContext{
-		Config: c,
-		DB:     db,
+		Config:       c,
+		ClientInfo:   middleware.NewClientInfoMiddleware(resolver).Handle,
+		SessionCheck: middleware.NewSessionCheckMiddleware(db, c.Auth.AccessSecret).Handle,
+		DB:           db,
+		LLM:          provider,
+		Tokenizer:    tok,
+		Vector:       index,
+		Memory:       memories,
+		Knowledge:    knowledge.NewBase(db, provider, index, tok, c.Knowledge),
+		Search:       search.NewSearcher(db, c.Search),
+		Ranking:      ranker,
+		Emails:       account.NewEmails(db, m, c.Account),
+		LoginGuard:   guard,
 	}
 }
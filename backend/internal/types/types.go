@@ -13,47 +13,478 @@ type ChangePasswordReq struct {
 	NewPassword string `json:"new_password"`
 }
 
+type CharacterFacets struct {
+	Tags       []FacetCount `json:"tags"`
+	Categories []FacetCount `json:"categories"`
+}
+
+type CharacterForm struct {
+	Name               string   `form:"name,optional"`
+	Profile            string   `form:"profile,optional"`
+	Scenario           string   `form:"scenario,optional"`
+	Greeting           string   `form:"greeting,optional"`
+	AlternateGreetings []string `form:"alternate_greetings,optional"`
+	Personality        string   `form:"personality,optional"`
+	ExampleDialogues   string   `form:"example_dialogues,optional"`
+	SpeakingStyle      string   `form:"speaking_style,optional"`
+	CreatorNotes       string   `form:"creator_notes,optional"`
+	PromptTemplate     string   `form:"prompt_template,optional"`
+	Generation         string   `form:"generation,optional"` // JSON 格式的 GenerationSettings
+	Visibility         string   `form:"visibility,optional"` // private | unlisted | public
+	Category           string   `form:"category,optional"`
+	Tags               []string `form:"tags,optional"` // 可重复, 也可以用逗号分隔
+}
+
 type CharacterIdReq struct {
 	Id int64 `path:"id"`
 }
 
 type CharacterInfo struct {
+	Id                 int64              `json:"id"`
+	CreatorId          int64              `json:"creator_id"`
+	Name               string             `json:"name"`
+	Photo              string             `json:"photo"`
+	Profile            string             `json:"profile"`
+	BackgroundImage    string             `json:"background_image"`
+	Scenario           string             `json:"scenario"`
+	Greeting           string             `json:"greeting"`
+	AlternateGreetings []string           `json:"alternate_greetings"`
+	Personality        string             `json:"personality"`
+	ExampleDialogues   string             `json:"example_dialogues"`
+	SpeakingStyle      string             `json:"speaking_style"`
+	CreatorNotes       string             `json:"creator_notes"`
+	PromptTemplate     string             `json:"prompt_template"`
+	Generation         GenerationSettings `json:"generation"`
+	Visibility         string             `json:"visibility"`
+	Category           string             `json:"category"`
+	Tags               []string           `json:"tags"`
+	ForkedFromId       int64              `json:"forked_from_id"` // 复制来源角色, 0 表示原创
+	ForkCount          int64              `json:"fork_count"`
+	ChatCount          int64              `json:"chat_count"`
+	LikeCount          int64              `json:"like_count"`
+	FavoriteCount      int64              `json:"favorite_count"`
+	RatingCount        int64              `json:"rating_count"`
+	RatingAverage      float64            `json:"rating_average"` // 平均评分, 没有评分时为 0
+	CreatedAt          string             `json:"created_at"`
+	UpdatedAt          string             `json:"updated_at"`
+}
+
+type CharacterInteraction struct {
+	Liked         bool    `json:"liked"`
+	Favorited     bool    `json:"favorited"`
+	Score         int     `json:"score"` // 当前用户的评分, 未评分时为 0
+	Review        string  `json:"review"`
+	LikeCount     int64   `json:"like_count"`
+	FavoriteCount int64   `json:"favorite_count"`
+	RatingCount   int64   `json:"rating_count"`
+	RatingAverage float64 `json:"rating_average"`
+}
+
+type CharacterList struct {
+	List       []CharacterInfo `json:"list"`
+	NextCursor string          `json:"next_cursor"`
+	HasMore    bool            `json:"has_more"`
+}
+
+type CharacterPage struct {
+	List     []CharacterInfo `json:"list"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+}
+
+type CharacterRatingInfo struct {
+	Id        int64  `json:"id"`
+	UserId    int64  `json:"user_id"`
+	Username  string `json:"username"`
+	Score     int    `json:"score"`
+	Review    string `json:"review"`
+	CreatedAt string `json:"created_at"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+type CharacterRatingList struct {
+	List       []CharacterRatingInfo `json:"list"`
+	NextCursor string                `json:"next_cursor"`
+	HasMore    bool                  `json:"has_more"`
+}
+
+type CharacterRatingsReq struct {
+	Id       int64  `path:"id"`
+	Cursor   string `form:"cursor,optional"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+type CharacterRecommendationInfo struct {
+	Character  CharacterInfo `json:"character"`
+	SourceId   int64         `json:"source_id"` // 推荐依据: 用户聊过的角色
+	SourceName string        `json:"source_name"`
+	Reason     string        `json:"reason"`
+	Score      float64       `json:"score"`
+}
+
+type CharacterSearchResult struct {
+	List     []CharacterInfo `json:"list"`
+	Total    int64           `json:"total"`
+	Page     int             `json:"page"`
+	PageSize int             `json:"page_size"`
+	Facets   CharacterFacets `json:"facets"`
+}
+
+type CharacterVersionDiff struct {
+	From    int           `json:"from"`
+	To      int           `json:"to"`
+	Changes []FieldChange `json:"changes"`
+}
+
+type CharacterVersionDiffReq struct {
+	Id   int64 `path:"id"`
+	From int   `form:"from"`
+	To   int   `form:"to,optional"` // 为 0 时与当前状态比较
+}
+
+type CharacterVersionInfo struct {
+	Id        int64  `json:"id"`
+	Version   int    `json:"version"`
+	Name      string `json:"name"`
+	Photo     string `json:"photo"`
+	CreatedAt string `json:"created_at"`
+}
+
+type CharacterVersionReq struct {
+	Id      int64 `path:"id"`
+	Version int   `path:"version"`
+}
+
+type ChatSocketEvent struct {
+	Type           string       `json:"type"`
+	RequestId      string       `json:"request_id,omitempty"`
+	ConversationId int64        `json:"conversation_id,omitempty"`
+	Content        string       `json:"content,omitempty"`
+	Typing         bool         `json:"typing,omitempty"`
+	Cancelled      bool         `json:"cancelled,omitempty"`
+	UserMessage    *MessageInfo `json:"user_message,omitempty"`
+	Message        *MessageInfo `json:"message,omitempty"`
+	Code           int          `json:"code,omitempty"`
+	Error          string       `json:"error,omitempty"`
+}
+
+type ChatSocketReq struct {
+	Type           string              `json:"type"`
+	RequestId      string              `json:"request_id,optional"`
+	ConversationId int64               `json:"conversation_id"`
+	MessageId      int64               `json:"message_id,optional"`
+	Content        string              `json:"content,optional"`
+	Typing         bool                `json:"typing,optional"`
+	Generation     *GenerationSettings `json:"generation,optional"` // 仅对本次请求生效的生成参数
+}
+
+type ChatStreamEvent struct {
+	Event       string       `json:"event"`
+	Content     string       `json:"content,omitempty"`
+	UserMessage *MessageInfo `json:"user_message,omitempty"`
+	Message     *MessageInfo `json:"message,omitempty"`
+	Code        int          `json:"code,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
+type ConversationIdReq struct {
+	Id             int64 `path:"id"`
+	ConversationId int64 `path:"conversationId"`
+}
+
+type ConversationInfo struct {
 	Id              int64  `json:"id"`
-	Name            string `json:"name"`
-	Photo           string `json:"photo"`
-	Profile         string `json:"profile"`
-	BackgroundImage string `json:"background_image"`
+	CharacterId     int64  `json:"character_id"`
+	Title           string `json:"title"`
+	ActiveMessageId int64  `json:"active_message_id"`
 	CreatedAt       string `json:"created_at"`
 	UpdatedAt       string `json:"updated_at"`
 }
 
+type ConversationList struct {
+	List       []ConversationInfo `json:"list"`
+	NextCursor string             `json:"next_cursor"`
+	HasMore    bool               `json:"has_more"`
+}
+
+type ConversationListReq struct {
+	Id       int64  `path:"id"`
+	Cursor   string `form:"cursor,optional"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
+type CreateConversationReq struct {
+	Id    int64  `path:"id"`
+	Title string `json:"title,optional"`
+}
+
+type CursorReq struct {
+	Cursor   string `form:"cursor,optional"`
+	PageSize int    `form:"page_size,default=20"`
+}
+
 type DataResp struct {
 	Code    int         `json:"code"`
 	Message string      `json:"message"`
 	Data    interface{} `json:"data"`
 }
 
+type DiscoverCharactersReq struct {
+	Page      int    `form:"page,default=1"`
+	PageSize  int    `form:"page_size,default=20"`
+	Sort      string `form:"sort,optional"` // newest | popular | liked | rated | relevance, 有关键词时默认 relevance, 否则 newest
+	Keyword   string `form:"keyword,optional"`
+	CreatorId int64  `form:"creator_id,optional"`
+	Category  string `form:"category,optional"`
+	Tags      string `form:"tags,optional"` // 逗号分隔, 需同时带有全部标签
+}
+
+type EditMessageReq struct {
+	Id         int64               `path:"id"`
+	MessageId  int64               `path:"messageId"`
+	Content    string              `json:"content"`
+	Generation *GenerationSettings `json:"generation,optional"` // 仅对本次请求生效的生成参数
+}
+
+type EmailTokenReq struct {
+	Token string `json:"token"`
+}
+
+type ExportCharacterReq struct {
+	Id     int64  `path:"id"`
+	Format string `form:"format,optional"` // json | png, 默认 json
+}
+
+type FacetCount struct {
+	Name  string `json:"name"`
+	Count int64  `json:"count"`
+}
+
+type FieldChange struct {
+	Field string      `json:"field"`
+	From  interface{} `json:"from"`
+	To    interface{} `json:"to"`
+}
+
+type ForgotPasswordReq struct {
+	Email string `json:"email"`
+}
+
+type GenerationSettings struct {
+	Model            string   `json:"model,optional"`
+	Temperature      *float64 `json:"temperature,optional"`
+	TopP             *float64 `json:"top_p,optional"`
+	MaxTokens        int      `json:"max_tokens,optional"`
+	PresencePenalty  *float64 `json:"presence_penalty,optional"`
+	FrequencyPenalty *float64 `json:"frequency_penalty,optional"`
+	Stop             []string `json:"stop,optional"`
+}
+
+type GetMessageListReq struct {
+	Id             int64  `path:"id"`
+	ConversationId int64  `path:"conversationId"`
+	Cursor         string `form:"cursor,optional"`
+	PageSize       int    `form:"page_size,default=20"`
+}
+
+type KnowledgeDocumentInfo struct {
+	Id          int64  `json:"id"`
+	CharacterId int64  `json:"character_id"`
+	Filename    string `json:"filename"`
+	ContentType string `json:"content_type"`
+	Size        int64  `json:"size"`
+	ChunkCount  int    `json:"chunk_count"`
+	CreatedAt   string `json:"created_at"`
+}
+
+type KnowledgeIdReq struct {
+	Id         int64 `path:"id"`
+	DocumentId int64 `path:"documentId"`
+}
+
 type LoginReq struct {
-	Username string `json:"username"`
-	Password string `json:"password"`
+	Username   string `json:"username"`
+	Password   string `json:"password"`
+	DeviceName string `json:"device_name,optional"` // 为空时根据 User-Agent 推断
+}
+
+type LoginResp struct {
+	Code            int    `json:"code"`
+	Message         string `json:"message"`
+	AccessToken     string `json:"access_token,omitempty"`
+	RefreshToken    string `json:"refresh_token,omitempty"`
+	ExpiresIn       int64  `json:"expires_in,omitempty"`
+	CaptchaRequired bool   `json:"captcha_required"`      // 连续失败次数较多, 客户端应要求完成验证码
+	RetryAfter      int64  `json:"retry_after,omitempty"` // 失败次数过多时需要等待的秒数
+}
+
+type LorebookEntryIdReq struct {
+	Id      int64 `path:"id"`
+	EntryId int64 `path:"entryId"`
+}
+
+type LorebookEntryInfo struct {
+	Id          int64    `json:"id"`
+	CharacterId int64    `json:"character_id"`
+	Keywords    []string `json:"keywords"`
+	Content     string   `json:"content"`
+	Constant    bool     `json:"constant"`
+	Enabled     bool     `json:"enabled"`
+	Priority    int      `json:"priority"`
+	CreatedAt   string   `json:"created_at"`
+	UpdatedAt   string   `json:"updated_at"`
+}
+
+type LorebookEntryReq struct {
+	Id       int64  `path:"id"`
+	Keywords string `json:"keywords,optional"`
+	Content  string `json:"content"`
+	Constant bool   `json:"constant,optional"`
+	Enabled  bool   `json:"enabled,default=true"`
+	Priority int    `json:"priority,optional"`
+}
+
+type MemoryIdReq struct {
+	Id       int64 `path:"id"`
+	MemoryId int64 `path:"memoryId"`
+}
+
+type MemoryInfo struct {
+	Id              int64  `json:"id"`
+	CharacterId     int64  `json:"character_id"`
+	Content         string `json:"content"`
+	Pinned          bool   `json:"pinned"`
+	SourceMessageId int64  `json:"source_message_id"`
+	CreatedAt       string `json:"created_at"`
+	UpdatedAt       string `json:"updated_at"`
+}
+
+type MessageAlternatives struct {
+	List        []MessageInfo `json:"list"`
+	ActiveIndex int           `json:"active_index"` // 当前分支所在的备选消息, 不在当前分支时为-1
+}
+
+type MessageIdReq struct {
+	Id             int64 `path:"id"`
+	ConversationId int64 `path:"conversationId"`
+	MessageId      int64 `path:"messageId"`
+}
+
+type MessageInfo struct {
+	Id             int64  `json:"id"`
+	ConversationId int64  `json:"conversation_id"`
+	ParentId       int64  `json:"parent_id"`
+	Role           string `json:"role"`
+	Content        string `json:"content"`
+	CreatedAt      string `json:"created_at"`
+	SiblingCount   int    `json:"sibling_count"` // 同一位置的备选消息数
+	SiblingIndex   int    `json:"sibling_index"` // 在备选消息中的位置, 从0开始
+}
+
+type MessageList struct {
+	List       []MessageInfo `json:"list"`
+	NextCursor string        `json:"next_cursor"`
+	HasMore    bool          `json:"has_more"`
+}
+
+type PageReq struct {
+	Page     int `form:"page,default=1"`
+	PageSize int `form:"page_size,default=20"`
+}
+
+type PinMemoryReq struct {
+	Id       int64 `path:"id"`
+	MemoryId int64 `path:"memoryId"`
+	Pinned   bool  `json:"pinned"`
+}
+
+type PreviewPromptReq struct {
+	Id       int64  `path:"id"`
+	Template string `json:"template,optional"`
+}
+
+type PromptPreview struct {
+	Prompt    string `json:"prompt"`
+	Template  string `json:"template"`
+	IsDefault bool   `json:"is_default"`
+}
+
+type RateCharacterReq struct {
+	Id     int64  `path:"id"`
+	Score  int    `json:"score"` // 1-5
+	Review string `json:"review,optional"`
 }
 
 type RefreshTokenReq struct {
 	RefreshToken string `json:"refresh_token"`
 }
 
+type RegenerateMessageReq struct {
+	Id         int64               `path:"id"`
+	MessageId  int64               `path:"messageId"`
+	Generation *GenerationSettings `json:"generation,optional"` // 仅对本次请求生效的生成参数
+}
+
 type RegisterReq struct {
 	Username string `json:"username"`
 	Password string `json:"password"`
 	Email    string `json:"email,optional"`
 }
 
+type ResetPasswordReq struct {
+	Token       string `json:"token"`
+	NewPassword string `json:"new_password"`
+}
+
+type SelectBranchReq struct {
+	Id             int64 `path:"id"`
+	ConversationId int64 `path:"conversationId"`
+	MessageId      int64 `json:"message_id"`
+}
+
+type SendMessageReq struct {
+	Id         int64               `path:"id"`
+	Content    string              `json:"content"`
+	Generation *GenerationSettings `json:"generation,optional"` // 仅对本次请求生效的生成参数
+}
+
+type SessionIdReq struct {
+	Id string `path:"id"`
+}
+
+type SessionInfo struct {
+	Id         string `json:"id"`
+	DeviceName string `json:"device_name"`
+	UserAgent  string `json:"user_agent"`
+	Ip         string `json:"ip"`
+	Current    bool   `json:"current"` // 是否为发起请求的会话
+	CreatedAt  string `json:"created_at"`
+	LastUsedAt string `json:"last_used_at"`
+}
+
 type TokenResp struct {
 	AccessToken  string `json:"access_token"`
 	RefreshToken string `json:"refresh_token"`
 	ExpiresIn    int64  `json:"expires_in"`
 }
 
+type UpdateLorebookEntryReq struct {
+	Id       int64  `path:"id"`
+	EntryId  int64  `path:"entryId"`
+	Keywords string `json:"keywords,optional"`
+	Content  string `json:"content"`
+	Constant bool   `json:"constant,optional"`
+	Enabled  bool   `json:"enabled,default=true"`
+	Priority int    `json:"priority,optional"`
+}
+
+type UpdateMemoryReq struct {
+	Id       int64  `path:"id"`
+	MemoryId int64  `path:"memoryId"`
+	Content  string `json:"content"`
+}
+
 type UpdateUserReq struct {
 	Username string `json:"username,optional"`
 	Email    string `json:"email,optional"`
@@ -62,10 +493,11 @@ type UpdateUserReq struct {
 }
 
 type UserInfo struct {
-	Id        int64  `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	Avatar    string `json:"avatar"`
-	Profile   string `json:"profile"`
-	CreatedAt string `json:"created_at"`
+	Id            int64  `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	Avatar        string `json:"avatar"`
+	Profile       string `json:"profile"`
+	CreatedAt     string `json:"created_at"`
 }
@@ -0,0 +1,105 @@
+'use client';
+
+import { useState } from 'react';
+import Link from 'next/link';
+import { Button } from '@/components/ui/button';
+import { Input } from '@/components/ui/input';
+import { Label } from '@/components/ui/label';
+import { Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle } from '@/components/ui/card';
+import { api } from '@/lib/api';
+import { Bot } from 'lucide-react';
+
+export default function ForgotPasswordPage() {
+  const [email, setEmail] = useState('');
+  const [error, setError] = useState('');
+  const [message, setMessage] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setError('');
+    setMessage('');
+    setLoading(true);
+
+    try {
+      const response = await api.forgotPassword(email.trim());
+      if (typeof response.code === 'number' && response.code !== 0) {
+        setError(response.message || '发送失败');
+        return;
+      }
+      setMessage(response.message || '重置邮件已发送');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : '发送失败');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  return (
+    <div className="min-h-screen flex flex-col bg-gradient-to-br from-background to-muted">
+      {/* 顶部导航 */}
+      <header className="border-b bg-background/80 backdrop-blur-sm">
+        <div className="max-w-6xl mx-auto px-4 h-16 flex items-center">
+          <Link href="/" className="flex items-center gap-2">
+            <Bot className="w-8 h-8 text-primary" />
+            <span className="text-xl font-bold">AIFriend</span>
+          </Link>
+        </div>
+      </header>
+
+      {/* 忘记密码表单 */}
+      <main className="flex-1 flex items-center justify-center px-4 py-12">
+        <Card className="w-full max-w-md">
+          <CardHeader className="space-y-1 text-center">
+            <CardTitle className="text-2xl font-bold">忘记密码</CardTitle>
+            <CardDescription>
+              输入已验证的邮箱，我们会发送重置密码的链接
+            </CardDescription>
+          </CardHeader>
+          <form onSubmit={handleSubmit}>
+            <CardContent className="space-y-4">
+              {error && (
+                <div className="p-3 text-sm text-red-500 bg-red-50 dark:bg-red-950/50 rounded-lg border border-red-200 dark:border-red-900">
+                  {error}
+                </div>
+              )}
+              {message && (
+                <div className="p-3 text-sm text-green-600 bg-green-50 dark:bg-green-950/50 rounded-lg border border-green-200 dark:border-green-900">
+                  {message}
+                </div>
+              )}
+              <div className="space-y-2">
+                <Label htmlFor="email">邮箱</Label>
+                <Input
+                  id="email"
+                  type="email"
+                  placeholder="请输入邮箱"
+                  value={email}
+                  onChange={(e) => setEmail(e.target.value)}
+                  required
+                  className="h-11"
+                />
+              </div>
+            </CardContent>
+            <CardFooter className="flex flex-col space-y-4 pt-2">
+              <Button type="submit" className="w-full h-11" disabled={loading}>
+                {loading ? (
+                  <div className="flex items-center gap-2">
+                    <div className="w-4 h-4 border-2 border-white border-t-transparent rounded-full animate-spin" />
+                    发送中...
+                  </div>
+                ) : '发送重置邮件'}
+              </Button>
+              <p className="text-sm text-muted-foreground text-center">
+                想起密码了？{' '}
+                <Link href="/login" className="text-primary hover:underline font-medium">
+                  返回登录
+                </Link>
+              </p>
+            </CardFooter>
+          </form>
+        </Card>
+      </main>
+    </div>
+  );
+}
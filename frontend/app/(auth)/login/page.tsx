@@ -74,7 +74,12 @@ export default function LoginPage() {
                 />
               </div>
               <div className="space-y-2">
-                <Label htmlFor="password">密码</Label>
+                <div className="flex items-center justify-between">
+                  <Label htmlFor="password">密码</Label>
+                  <Link href="/forgot-password" className="text-sm text-primary hover:underline">
+                    忘记密码？
+                  </Link>
+                </div>
                 <div className="relative">
                   <Input
                     id="password"
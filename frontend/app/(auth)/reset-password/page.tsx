@@ -0,0 +1,137 @@
+'use client';
+
+import { Suspense, useState } from 'react';
+import { useRouter, useSearchParams } from 'next/navigation';
+import Link from 'next/link';
+import { Button } from '@/components/ui/button';
+import { Input } from '@/components/ui/input';
+import { Label } from '@/components/ui/label';
+import { Card, CardContent, CardDescription, CardFooter, CardHeader, CardTitle } from '@/components/ui/card';
+import { api } from '@/lib/api';
+import { Bot } from 'lucide-react';
+
+function ResetPasswordForm() {
+  const router = useRouter();
+  const token = useSearchParams().get('token') || '';
+  const [password, setPassword] = useState('');
+  const [confirmPassword, setConfirmPassword] = useState('');
+  const [error, setError] = useState('');
+  const [loading, setLoading] = useState(false);
+
+  const handleSubmit = async (e: React.FormEvent) => {
+    e.preventDefault();
+    setError('');
+
+    if (password.length < 6) {
+      setError('新密码长度不能少于 6 位');
+      return;
+    }
+    if (password !== confirmPassword) {
+      setError('两次输入的新密码不一致');
+      return;
+    }
+
+    setLoading(true);
+    try {
+      const response = await api.resetPassword(token, password);
+      if (typeof response.code === 'number' && response.code !== 0) {
+        setError(response.message || '重置失败');
+        return;
+      }
+      // 重置后所有设备上的令牌都已失效
+      api.clearTokens();
+      router.replace('/login');
+    } catch (err) {
+      setError(err instanceof Error ? err.message : '重置失败');
+    } finally {
+      setLoading(false);
+    }
+  };
+
+  if (!token) {
+    return (
+      <CardContent>
+        <div className="p-3 text-sm text-red-500 bg-red-50 dark:bg-red-950/50 rounded-lg border border-red-200 dark:border-red-900">
+          重置链接无效，请重新申请
+        </div>
+      </CardContent>
+    );
+  }
+
+  return (
+    <form onSubmit={handleSubmit}>
+      <CardContent className="space-y-4">
+        {error && (
+          <div className="p-3 text-sm text-red-500 bg-red-50 dark:bg-red-950/50 rounded-lg border border-red-200 dark:border-red-900">
+            {error}
+          </div>
+        )}
+        <div className="space-y-2">
+          <Label htmlFor="password">新密码</Label>
+          <Input
+            id="password"
+            type="password"
+            placeholder="请输入新密码"
+            value={password}
+            onChange={(e) => setPassword(e.target.value)}
+            required
+            className="h-11"
+          />
+        </div>
+        <div className="space-y-2">
+          <Label htmlFor="confirm-password">确认新密码</Label>
+          <Input
+            id="confirm-password"
+            type="password"
+            placeholder="请再次输入新密码"
+            value={confirmPassword}
+            onChange={(e) => setConfirmPassword(e.target.value)}
+            required
+            className="h-11"
+          />
+        </div>
+      </CardContent>
+      <CardFooter className="pt-2">
+        <Button type="submit" className="w-full h-11" disabled={loading}>
+          {loading ? (
+            <div className="flex items-center gap-2">
+              <div className="w-4 h-4 border-2 border-white border-t-transparent rounded-full animate-spin" />
+              提交中...
+            </div>
+          ) : '重置密码'}
+        </Button>
+      </CardFooter>
+    </form>
+  );
+}
+
+export default function ResetPasswordPage() {
+  return (
+    <div className="min-h-screen flex flex-col bg-gradient-to-br from-background to-muted">
+      {/* 顶部导航 */}
+      <header className="border-b bg-background/80 backdrop-blur-sm">
+        <div className="max-w-6xl mx-auto px-4 h-16 flex items-center">
+          <Link href="/" className="flex items-center gap-2">
+            <Bot className="w-8 h-8 text-primary" />
+            <span className="text-xl font-bold">AIFriend</span>
+          </Link>
+        </div>
+      </header>
+
+      {/* 重置密码表单 */}
+      <main className="flex-1 flex items-center justify-center px-4 py-12">
+        <Card className="w-full max-w-md">
+          <CardHeader className="space-y-1 text-center">
+            <CardTitle className="text-2xl font-bold">重置密码</CardTitle>
+            <CardDescription>
+              设置新密码后，所有设备都需要重新登录
+            </CardDescription>
+          </CardHeader>
+          <Suspense>
+            <ResetPasswordForm />
+          </Suspense>
+        </Card>
+      </main>
+    </div>
+  );
+}
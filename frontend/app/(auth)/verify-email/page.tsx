@@ -0,0 +1,81 @@
+'use client';
+
+import { Suspense, useEffect, useRef, useState } from 'react';
+import { useSearchParams } from 'next/navigation';
+import Link from 'next/link';
+import { Card, CardContent, CardHeader, CardTitle } from '@/components/ui/card';
+import { api } from '@/lib/api';
+import { Bot } from 'lucide-react';
+
+function VerifyEmailResult() {
+  const token = useSearchParams().get('token') || '';
+  const [status, setStatus] = useState<'loading' | 'success' | 'error'>(token ? 'loading' : 'error');
+  const [message, setMessage] = useState(token ? '' : '验证链接无效');
+  // 令牌只能使用一次, 避免开发模式下重复执行副作用
+  const submitted = useRef(false);
+
+  useEffect(() => {
+    if (!token || submitted.current) return;
+    submitted.current = true;
+
+    api.verifyEmail(token)
+      .then((response) => {
+        const ok = typeof response.code !== 'number' || response.code === 0;
+        setStatus(ok ? 'success' : 'error');
+        setMessage(response.message || (ok ? '邮箱验证成功' : '验证失败'));
+      })
+      .catch((err) => {
+        setStatus('error');
+        setMessage(err instanceof Error ? err.message : '验证失败');
+      });
+  }, [token]);
+
+  if (status === 'loading') {
+    return (
+      <div className="flex items-center justify-center gap-2 text-muted-foreground">
+        <div className="w-5 h-5 border-2 border-primary border-t-transparent rounded-full animate-spin" />
+        验证中...
+      </div>
+    );
+  }
+
+  return (
+    <div className="space-y-4 text-center">
+      <p className={status === 'success' ? 'text-green-600 dark:text-green-400' : 'text-red-500'}>
+        {message}
+      </p>
+      <Link href="/" className="text-sm text-primary hover:underline font-medium">
+        返回首页
+      </Link>
+    </div>
+  );
+}
+
+export default function VerifyEmailPage() {
+  return (
+    <div className="min-h-screen flex flex-col bg-gradient-to-br from-background to-muted">
+      {/* 顶部导航 */}
+      <header className="border-b bg-background/80 backdrop-blur-sm">
+        <div className="max-w-6xl mx-auto px-4 h-16 flex items-center">
+          <Link href="/" className="flex items-center gap-2">
+            <Bot className="w-8 h-8 text-primary" />
+            <span className="text-xl font-bold">AIFriend</span>
+          </Link>
+        </div>
+      </header>
+
+      <main className="flex-1 flex items-center justify-center px-4 py-12">
+        <Card className="w-full max-w-md">
+          <CardHeader className="text-center">
+            <CardTitle className="text-2xl font-bold">验证邮箱</CardTitle>
+          </CardHeader>
+          <CardContent>
+            <Suspense>
+              <VerifyEmailResult />
+            </Suspense>
+          </CardContent>
+        </Card>
+      </main>
+    </div>
+  );
+}
@@ -39,7 +39,9 @@ export default function CharactersPage() {
   const router = useRouter();
   const [user, setUser] = useState<UserInfo | null>(null);
   const [characters, setCharacters] = useState<CharacterInfo[]>([]);
+  const [nextCursor, setNextCursor] = useState("");
   const [loading, setLoading] = useState(true);
+  const [loadingMore, setLoadingMore] = useState(false);
   const [formLoading, setFormLoading] = useState(false);
 
   // 对话框状态
@@ -54,8 +56,8 @@ export default function CharactersPage() {
         api.getCharacterList(),
       ]);
       setUser(userRes);
-      // eslint-disable-next-line @typescript-eslint/no-explicit-any
-      setCharacters((charRes as any).data || []);
+      setCharacters(charRes.data?.list || []);
+      setNextCursor(charRes.data?.has_more ? charRes.data.next_cursor : "");
     } catch (error) {
       console.error("获取数据失败:", error);
       router.push("/login");
@@ -72,6 +74,20 @@ export default function CharactersPage() {
     fetchData();
   }, [router, fetchData]);
 
+  const handleLoadMore = async () => {
+    if (!nextCursor) return;
+    setLoadingMore(true);
+    try {
+      const res = await api.getCharacterList(nextCursor);
+      setCharacters((prev) => [...prev, ...(res.data?.list || [])]);
+      setNextCursor(res.data?.has_more ? res.data.next_cursor : "");
+    } catch (error) {
+      console.error("加载更多失败:", error);
+    } finally {
+      setLoadingMore(false);
+    }
+  };
+
   const handleLogout = () => {
     api.logout();
     router.push("/login");
@@ -181,6 +197,14 @@ export default function CharactersPage() {
             ))}
           </div>
         )}
+        {nextCursor && (
+          <div className="mt-8 flex justify-center">
+            <Button variant="outline" onClick={handleLoadMore} disabled={loadingMore}>
+              {loadingMore && <Loader2 className="mr-2 h-4 w-4 animate-spin" />}
+              加载更多
+            </Button>
+          </div>
+        )}
       </div>
 
       {/* 创建角色对话框 */}
@@ -32,6 +32,10 @@ export default function ProfilePage() {
   const [avatarSuccess, setAvatarSuccess] = useState('');
   const [avatarUploading, setAvatarUploading] = useState(false);
 
+  // Email verification state
+  const [verifySending, setVerifySending] = useState(false);
+  const [verifyMessage, setVerifyMessage] = useState('');
+
   // Password state
   const [passwordForm, setPasswordForm] = useState({ oldPassword: '', newPassword: '', confirmPassword: '' });
   const [passwordError, setPasswordError] = useState('');
@@ -131,7 +135,13 @@ export default function ProfilePage() {
         const userInfo = await api.getUserInfo();
         setUser(userInfo);
       } catch {
-        setUser((prev) => prev ? { ...prev, ...updates, email: updates.email ?? prev.email, profile: updates.profile ?? prev.profile } : prev);
+        setUser((prev) => prev ? {
+          ...prev,
+          ...updates,
+          email: updates.email ?? prev.email,
+          email_verified: updates.email === undefined && prev.email_verified,
+          profile: updates.profile ?? prev.profile,
+        } : prev);
       }
       setBasicSuccess('保存成功');
     } catch (err) {
@@ -185,6 +195,20 @@ export default function ProfilePage() {
     }
   };
 
+  // --- Email verification ---
+  const handleSendVerification = async () => {
+    setVerifyMessage('');
+    setVerifySending(true);
+    try {
+      const response = await api.sendVerificationEmail();
+      setVerifyMessage(response.message || '验证邮件已发送');
+    } catch (err) {
+      setVerifyMessage(err instanceof Error ? err.message : '发送失败');
+    } finally {
+      setVerifySending(false);
+    }
+  };
+
   // --- Password ---
   const handlePasswordChange = async () => {
     setPasswordError('');
@@ -214,8 +238,9 @@ export default function ProfilePage() {
         setPasswordError(response.message || '修改失败');
         return;
       }
-      setPasswordSuccess('密码修改成功');
+      // 其他设备需要重新登录, 当前设备已换用新的令牌
       setPasswordForm({ oldPassword: '', newPassword: '', confirmPassword: '' });
+      setPasswordSuccess(response.message || '密码修改成功');
     } catch (err) {
       setPasswordError(err instanceof Error ? err.message : '修改失败');
     } finally {
@@ -299,6 +324,26 @@ export default function ProfilePage() {
                     }}
                     className="h-11"
                   />
+                  {user?.email && (
+                    <div className="flex items-center gap-2 text-xs text-muted-foreground">
+                      {user.email_verified ? (
+                        <span className="text-green-600 dark:text-green-400">邮箱已验证</span>
+                      ) : (
+                        <>
+                          <span>邮箱未验证</span>
+                          <button
+                            type="button"
+                            onClick={handleSendVerification}
+                            disabled={verifySending}
+                            className="text-primary hover:underline disabled:opacity-50"
+                          >
+                            {verifySending ? '发送中...' : '发送验证邮件'}
+                          </button>
+                          {verifyMessage && <span>{verifyMessage}</span>}
+                        </>
+                      )}
+                    </div>
+                  )}
                 </div>
                 <div className="space-y-2">
                   <div className="flex items-center justify-between gap-2">
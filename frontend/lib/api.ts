@@ -1,3 +1,5 @@
+import type { CharacterInfo, CursorPage } from '@/lib/types';
+
 export const API_BASE_URL = process.env.NEXT_PUBLIC_API_URL || 'http://localhost:8888';
 
 interface ApiResponse<T = unknown> {
@@ -7,6 +9,8 @@ interface ApiResponse<T = unknown> {
   access_token?: string;
   refresh_token?: string;
   expires_in?: number;
+  captcha_required?: boolean;
+  retry_after?: number;
 }
 
 class ApiClient {
@@ -83,6 +87,11 @@ class ApiClient {
       body: JSON.stringify({ username, password }),
     });
 
+    // 密码错误或失败次数过多时 code 不为 0
+    if (typeof response.code === 'number' && response.code !== 0) {
+      throw new Error(response.message || '登录失败');
+    }
+
     if (response.access_token && response.refresh_token) {
       this.setTokens(response.access_token, response.refresh_token);
     }
@@ -109,6 +118,52 @@ class ApiClient {
   }
 
   logout() {
+    // 通知服务端吊销刷新令牌, 请求失败时本地同样视为已退出
+    const refreshToken = this.getRefreshToken();
+    if (refreshToken) {
+      this.request<ApiResponse>('/api/v1/auth/logout', {
+        method: 'POST',
+        body: JSON.stringify({ refresh_token: refreshToken }),
+      }).catch(() => {});
+    }
+    this.clearTokens();
+  }
+
+  async verifyEmail(token: string) {
+    return this.request<ApiResponse>('/api/v1/auth/email/verify', {
+      method: 'POST',
+      body: JSON.stringify({ token }),
+    });
+  }
+
+  async forgotPassword(email: string) {
+    return this.request<ApiResponse>('/api/v1/auth/password/forgot', {
+      method: 'POST',
+      body: JSON.stringify({ email }),
+    });
+  }
+
+  async resetPassword(token: string, newPassword: string) {
+    return this.request<ApiResponse>('/api/v1/auth/password/reset', {
+      method: 'POST',
+      body: JSON.stringify({ token, new_password: newPassword }),
+    });
+  }
+
+  async sendVerificationEmail() {
+    return this.request<ApiResponse>('/api/v1/user/email/verification', { method: 'POST' });
+  }
+
+  async getSessions() {
+    return this.request<ApiResponse>('/api/v1/user/sessions');
+  }
+
+  async removeSession(id: string) {
+    return this.request<ApiResponse>(`/api/v1/user/sessions/${id}`, { method: 'DELETE' });
+  }
+
+  async logoutAll() {
+    await this.request<ApiResponse>('/api/v1/auth/logout/all', { method: 'POST' });
     this.clearTokens();
   }
 
@@ -118,6 +173,7 @@ class ApiClient {
       id: number;
       username: string;
       email: string;
+      email_verified: boolean;
       avatar: string;
       profile: string;
       created_at: string;
@@ -132,10 +188,17 @@ class ApiClient {
   }
 
   async changePassword(oldPassword: string, newPassword: string) {
-    return this.request<ApiResponse>('/api/v1/user/password', {
+    const response = await this.request<ApiResponse<{ access_token: string; refresh_token: string }>>('/api/v1/user/password', {
       method: 'POST',
       body: JSON.stringify({ old_password: oldPassword, new_password: newPassword }),
     });
+
+    // 其他设备的令牌已失效, 当前设备换用新签发的令牌
+    if (response.data?.access_token && response.data.refresh_token) {
+      this.setTokens(response.data.access_token, response.data.refresh_token);
+    }
+
+    return response;
   }
 
   async uploadAvatar(file: File) {
@@ -200,8 +263,9 @@ class ApiClient {
     return responseData as ApiResponse;
   }
 
-  async getCharacterList() {
-    return this.request<ApiResponse>('/api/v1/character/list');
+  async getCharacterList(cursor?: string) {
+    const query = cursor ? `?cursor=${encodeURIComponent(cursor)}` : '';
+    return this.request<ApiResponse<CursorPage<CharacterInfo>>>(`/api/v1/character/list${query}`);
   }
 
   async getCharacter(id: number) {
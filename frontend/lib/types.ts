@@ -2,6 +2,7 @@ export interface UserInfo {
   id: number;
   username: string;
   email: string;
+  email_verified: boolean;
   avatar: string;
   profile: string;
   created_at: string;
@@ -16,3 +17,10 @@ export interface CharacterInfo {
   created_at: string;
   updated_at: string;
 }
+
+// 游标分页数据, 把 next_cursor 作为下一次请求的 cursor 参数
+export interface CursorPage<T> {
+  list: T[];
+  next_cursor: string;
+  has_more: boolean;
+}